@@ -0,0 +1,152 @@
+package gocli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Aliases maps flag names (e.g., "log-level") to their short alias, a single
+// character (e.g., "l"). With WithPrefix, names may be given with or without
+// the prefix.
+type Aliases map[string]string
+
+var (
+	aliasesMu sync.RWMutex
+	aliases   = make(map[*flag.FlagSet]map[string]string) // long flag name of each short alias, by flag set
+)
+
+// WithAliases defines short aliases of the flags of a registrar (see Alias),
+// e.g., -l for --log-level in a tool run by hand.
+//
+// The registrar panics if a name is not one of its flags, or if an alias is
+// invalid or already defined in the flag set, e.g., by another registrar, as
+// these are programming errors.
+//
+// Parameters:
+//   - aliases  The short aliases by flag name.
+//
+// Example:
+//
+//	logConfig := gocli.RegisterLogStdFlags(fs, gocli.WithAliases(gocli.Aliases{
+//		"log-level": "l",
+//	}))
+func WithAliases(
+	aliases Aliases,
+) RegisterOption {
+	return func(o *registerOptions) {
+		if o.aliases == nil {
+			o.aliases = make(Aliases, len(aliases))
+		}
+		for name, short := range aliases {
+			o.aliases[name] = short
+		}
+	}
+}
+
+// Alias defines a short alias of a flag, e.g., -l for --log-level: a value
+// given to the alias sets the flag, which then counts as set on the command
+// line (e.g., for Required and RegisterConfigFileFlag). The alias is listed
+// with the flag in the usage output (see PrintDefaults), e.g.,
+// "-l, --log-level level", and has no environment variable of its own.
+//
+// It panics if the flag is not defined or already has an alias, or if the
+// alias is not a single character or is already defined in fs, e.g., as the
+// alias of another flag, as these are programming errors.
+//
+// Parameters:
+//   - fs     The flag set holding the flag.
+//   - short  The alias, a single character other than a dash (e.g., "l").
+//   - name   The flag name (e.g., "log-level").
+//
+// Example:
+//
+//	verbose := fs.Bool("verbose", false, "Print every request")
+//	gocli.Alias(fs, "v", "verbose")
+func Alias(
+	fs *flag.FlagSet,
+	short string,
+	name string,
+) {
+	target := fs.Lookup(name)
+	if target == nil {
+		panic(fmt.Sprintf("gocli: cannot alias undefined flag -%s", name))
+	}
+	if utf8.RuneCountInString(short) != 1 || short == "-" || short == "=" {
+		panic(fmt.Sprintf("gocli: invalid alias %q of -%s: expected a single character", short, name))
+	}
+
+	aliasesMu.Lock()
+	defer aliasesMu.Unlock()
+	if existing, ok := aliases[fs][short]; ok {
+		panic(fmt.Sprintf("gocli: alias -%s of -%s conflicts with the alias of -%s", short, name, existing))
+	}
+	if fs.Lookup(short) != nil {
+		panic(fmt.Sprintf("gocli: alias -%s of -%s conflicts with flag -%s", short, name, short))
+	}
+	for existing, long := range aliases[fs] {
+		if long == name {
+			panic(fmt.Sprintf("gocli: flag -%s already has alias -%s", name, existing))
+		}
+	}
+
+	fs.Var(&forwardFlag{fs: fs, target: target}, short, target.Usage)
+	fs.Lookup(short).DefValue = target.DefValue
+	if aliases[fs] == nil {
+		aliases[fs] = make(map[string]string)
+	}
+	aliases[fs][short] = name
+	Hide(fs, short)
+}
+
+// applyAliases defines the aliases of the options of a registrar for the flags
+// of fs not in known (see applyOptions).
+func applyAliases(
+	fs *flag.FlagSet,
+	known map[string]struct{},
+	o registerOptions,
+) {
+	for name, short := range o.aliases {
+		if o.prefix != "" && !strings.HasPrefix(name, o.prefix) {
+			name = o.prefix + name
+		}
+		if _, ok := known[name]; ok || fs.Lookup(name) == nil {
+			panic(fmt.Sprintf("gocli: cannot alias -%s: not a flag of the registrar", name))
+		}
+		Alias(fs, short, name)
+	}
+}
+
+// isAlias reports whether a flag of fs is the short alias of another flag.
+func isAlias(
+	fs *flag.FlagSet,
+	name string,
+) bool {
+	aliasesMu.RLock()
+	defer aliasesMu.RUnlock()
+	_, ok := aliases[fs][name]
+	return ok
+}
+
+// aliasOf returns the short alias of a flag of fs, or of the global flag it
+// forwards to for the global flags of a CommandSet, or the empty string.
+func aliasOf(
+	fs *flag.FlagSet,
+	f *flag.Flag,
+) string {
+	name := f.Name
+	if forward, ok := f.Value.(*forwardFlag); ok && !isAlias(fs, name) {
+		fs, name = forward.fs, forward.target.Name
+	}
+
+	aliasesMu.RLock()
+	defer aliasesMu.RUnlock()
+	for short, long := range aliases[fs] {
+		if long == name {
+			return short
+		}
+	}
+	return ""
+}
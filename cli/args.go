@@ -0,0 +1,391 @@
+package gocli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// ArgType is the type of the values of a positional argument.
+type ArgType int
+
+// Types of positional arguments, read with the accessor of the same name of
+// Args (e.g., Args.Int for ArgInt).
+const (
+	ArgString   ArgType = iota // Any string
+	ArgInt                     // A decimal integer (e.g., "3")
+	ArgFloat64                 // A floating-point number (e.g., "0.5")
+	ArgDuration                // A time.Duration (e.g., "30s")
+	ArgByteSize                // A ByteSize (e.g., "64MiB")
+)
+
+// argTypeNames are the names of the argument types shown in the usage.
+var argTypeNames = map[ArgType]string{
+	ArgString:   "string",
+	ArgInt:      "int",
+	ArgFloat64:  "float",
+	ArgDuration: "duration",
+	ArgByteSize: "size",
+}
+
+// Arity is the number of values a positional argument takes.
+type Arity int
+
+// Arities of positional arguments. Arguments are declared in order: those
+// taking exactly one value first, then the optional ones, then at most one
+// taking several values.
+const (
+	ArityOne       Arity = iota // Exactly one value
+	ArityOptional               // Zero or one value
+	ArityOneOrMore              // One or more values, the rest of the command line
+	ArityAny                    // Zero or more values, the rest of the command line
+)
+
+// positional is a declared positional argument.
+type positional struct {
+	name  string
+	typ   ArgType
+	arity Arity
+	usage string
+}
+
+var (
+	positionalsMu sync.RWMutex
+	positionals   = make(map[*flag.FlagSet][]*positional) // declared positional arguments, by flag set
+)
+
+// PositionalArg declares a positional argument of fs, expected among the
+// arguments left after its flags (fs.Args()) and checked by ParseArgs, e.g.,
+// the target node of a command-line tool.
+//
+// The usage of fs shows the arguments before the flags (see PrintDefaults),
+// as does the usage of a CommandSet subcommand, whose declared arguments are
+// checked before it runs.
+//
+// It panics if name is already declared, or if the argument breaks the order
+// of the arities (see Arity), as these are programming errors.
+//
+// Parameters:
+//   - fs     The flag set whose arguments are declared.
+//   - name   The argument name, shown in the usage (e.g., "node").
+//   - typ    The type of the values.
+//   - arity  The number of values.
+//   - usage  The argument help.
+//
+// Example:
+//
+//	gocli.PositionalArg(fs, "node", gocli.ArgString, gocli.ArityOne, "Name of the target node")
+//	gocli.PositionalArg(fs, "samples", gocli.ArgInt, gocli.ArityOptional, "Number of samples to collect")
+//	fs.Parse(os.Args[1:])
+//	args, err := gocli.ParseArgs(fs)
+//	if err != nil { ... }
+//	node := args.String("node")
+//	samples := 10
+//	if args.Has("samples") {
+//		samples = args.Int("samples")
+//	}
+func PositionalArg(
+	fs *flag.FlagSet,
+	name string,
+	typ ArgType,
+	arity Arity,
+	usage string,
+) {
+	positionalsMu.Lock()
+	for _, p := range positionals[fs] {
+		switch {
+		case p.name == name:
+			positionalsMu.Unlock()
+			panic(fmt.Sprintf("gocli: argument %s declared twice", name))
+		case p.arity == ArityOneOrMore || p.arity == ArityAny:
+			positionalsMu.Unlock()
+			panic(fmt.Sprintf("gocli: cannot declare argument %s after %s, which takes the rest of the command line", name, p.name))
+		case p.arity == ArityOptional && (arity == ArityOne || arity == ArityOneOrMore):
+			positionalsMu.Unlock()
+			panic(fmt.Sprintf("gocli: cannot declare required argument %s after optional argument %s", name, p.name))
+		}
+	}
+	positionals[fs] = append(positionals[fs], &positional{name: name, typ: typ, arity: arity, usage: usage})
+	positionalsMu.Unlock()
+
+	installUsage(fs)
+}
+
+// Args holds the positional arguments of a flag set, parsed by ParseArgs.
+type Args struct {
+	values map[string][]any // parsed values of each declared argument
+}
+
+// ParseArgs checks the arguments left after the flags of fs (fs.Args())
+// against the arguments declared with PositionalArg, and parses their values.
+// Call it after fs.Parse.
+//
+// Parameters:
+//   - fs  The parsed flag set.
+//
+// Returns:
+//
+//	The parsed arguments, or an error joining every problem: missing
+//	arguments, values not matching their type, and unexpected arguments
+//	(e.g., "missing argument <node>").
+func ParseArgs(
+	fs *flag.FlagSet,
+) (*Args, error) {
+	positionalsMu.RLock()
+	declared := positionals[fs]
+	positionalsMu.RUnlock()
+
+	args := &Args{values: make(map[string][]any, len(declared))}
+	rest := fs.Args()
+	var errs []error
+	for _, p := range declared {
+		var given []string
+		switch p.arity {
+		case ArityOne:
+			if len(rest) == 0 {
+				errs = append(errs, fmt.Errorf("missing argument %s", p.placeholder()))
+				args.values[p.name] = nil
+				continue
+			}
+			given, rest = rest[:1], rest[1:]
+		case ArityOptional:
+			if len(rest) > 0 {
+				given, rest = rest[:1], rest[1:]
+			}
+		case ArityOneOrMore:
+			if len(rest) == 0 {
+				errs = append(errs, fmt.Errorf("missing argument %s", p.placeholder()))
+			}
+			given, rest = rest, nil
+		case ArityAny:
+			given, rest = rest, nil
+		}
+
+		values := make([]any, 0, len(given))
+		for _, s := range given {
+			value, err := parseArg(p.typ, s)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invalid value %q for argument %s: %w", s, p.placeholder(), err))
+				continue
+			}
+			values = append(values, value)
+		}
+		args.values[p.name] = values
+	}
+	if len(rest) > 0 {
+		errs = append(errs, fmt.Errorf("unexpected arguments: %s", strings.Join(rest, " ")))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// parseArg parses a value of a positional argument.
+func parseArg(
+	typ ArgType,
+	s string,
+) (any, error) {
+	switch typ {
+	case ArgString:
+		return s, nil
+	case ArgInt:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, errors.New("not an integer")
+		}
+		return n, nil
+	case ArgFloat64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, errors.New("not a number")
+		}
+		return f, nil
+	case ArgDuration:
+		return time.ParseDuration(s)
+	case ArgByteSize:
+		return ParseByteSize(s)
+	default:
+		return nil, fmt.Errorf("unknown argument type %d", typ)
+	}
+}
+
+// Has reports whether an argument was given at least once.
+func (a *Args) Has(
+	name string,
+) bool {
+	return len(a.lookup(name)) > 0
+}
+
+// Len returns the number of values given to an argument.
+func (a *Args) Len(
+	name string,
+) int {
+	return len(a.lookup(name))
+}
+
+// String returns the value of an ArgString argument, or its first value for
+// arguments taking several values; the empty string if it was not given.
+func (a *Args) String(
+	name string,
+) string {
+	return argValue[string](a, name)
+}
+
+// Int returns the value of an ArgInt argument, or its first value; 0 if it
+// was not given.
+func (a *Args) Int(
+	name string,
+) int {
+	return argValue[int](a, name)
+}
+
+// Float64 returns the value of an ArgFloat64 argument, or its first value; 0
+// if it was not given.
+func (a *Args) Float64(
+	name string,
+) float64 {
+	return argValue[float64](a, name)
+}
+
+// Duration returns the value of an ArgDuration argument, or its first value;
+// 0 if it was not given.
+func (a *Args) Duration(
+	name string,
+) time.Duration {
+	return argValue[time.Duration](a, name)
+}
+
+// ByteSize returns the value of an ArgByteSize argument, or its first value;
+// 0 if it was not given.
+func (a *Args) ByteSize(
+	name string,
+) ByteSize {
+	return argValue[ByteSize](a, name)
+}
+
+// Strings returns the values of an ArgString argument taking several values.
+func (a *Args) Strings(
+	name string,
+) []string {
+	return argValues[string](a, name)
+}
+
+// Ints returns the values of an ArgInt argument taking several values.
+func (a *Args) Ints(
+	name string,
+) []int {
+	return argValues[int](a, name)
+}
+
+// lookup returns the values of an argument; it panics if the argument is not
+// declared.
+func (a *Args) lookup(
+	name string,
+) []any {
+	values, ok := a.values[name]
+	if !ok {
+		panic(fmt.Sprintf("gocli: undeclared argument %s", name))
+	}
+	return values
+}
+
+// argValue returns the first value of an argument, or the zero value of T; it
+// panics if the argument is not of type T.
+func argValue[T any](
+	a *Args,
+	name string,
+) T {
+	var zero T
+	values := a.lookup(name)
+	if len(values) == 0 {
+		return zero
+	}
+	value, ok := values[0].(T)
+	if !ok {
+		panic(fmt.Sprintf("gocli: argument %s is not of type %T", name, zero))
+	}
+	return value
+}
+
+// argValues returns the values of an argument; it panics if the argument is
+// not of type T.
+func argValues[T any](
+	a *Args,
+	name string,
+) []T {
+	values := a.lookup(name)
+	out := make([]T, 0, len(values))
+	for _, v := range values {
+		value, ok := v.(T)
+		if !ok {
+			var zero T
+			panic(fmt.Sprintf("gocli: argument %s is not of type %T", name, zero))
+		}
+		out = append(out, value)
+	}
+	return out
+}
+
+// placeholder returns the usage form of an argument (e.g., "<node>",
+// "[samples]", "<file>...").
+func (p *positional) placeholder() string {
+	switch p.arity {
+	case ArityOptional:
+		return "[" + p.name + "]"
+	case ArityOneOrMore:
+		return "<" + p.name + ">..."
+	case ArityAny:
+		return "[" + p.name + "...]"
+	default:
+		return "<" + p.name + ">"
+	}
+}
+
+// hasArgs reports whether fs declares any positional argument.
+func hasArgs(
+	fs *flag.FlagSet,
+) bool {
+	positionalsMu.RLock()
+	defer positionalsMu.RUnlock()
+	return len(positionals[fs]) > 0
+}
+
+// argsSynopsis returns the usage forms of the arguments of fs, separated by
+// spaces (e.g., "<node> [samples]").
+func argsSynopsis(
+	fs *flag.FlagSet,
+) string {
+	positionalsMu.RLock()
+	defer positionalsMu.RUnlock()
+
+	forms := make([]string, 0, len(positionals[fs]))
+	for _, p := range positionals[fs] {
+		forms = append(forms, p.placeholder())
+	}
+	return strings.Join(forms, " ")
+}
+
+// printArgs prints the arguments of fs, with their type and usage, under an
+// "Arguments:" title.
+func printArgs(
+	w io.Writer,
+	fs *flag.FlagSet,
+) {
+	positionalsMu.RLock()
+	defer positionalsMu.RUnlock()
+
+	_, _ = fmt.Fprintln(w, "Arguments:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, p := range positionals[fs] {
+		_, _ = fmt.Fprintf(tw, "  %s\t%s\t%s\n", p.placeholder(), argTypeNames[p.typ], p.usage)
+	}
+	_ = tw.Flush()
+}
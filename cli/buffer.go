@@ -0,0 +1,68 @@
+package gocli
+
+import "flag"
+
+// BufferConfig holds configuration options for an in-memory buffer of pending
+// items (e.g., the metrics an agent queues for the relay), consumed by
+// datastructure.NewRingBufferFromConfig.
+type BufferConfig struct {
+	BufferCapacity       int      `log:"buffer-capacity" validate:"min=1"`                              // Maximum number of items held by the buffer
+	BufferMaxBytes       ByteSize `log:"buffer-max-bytes" validate:"min=0"`                             // Maximum total size of the items held by the buffer; 0 leaves only the capacity limit
+	BufferOverflowPolicy string   `log:"buffer-overflow-policy" validate:"oneof=overwrite drop-newest"` // What happens to an item added to a full buffer: "overwrite" the oldest or "drop-newest"
+	BufferFlushBatchSize int      `log:"buffer-flush-batch-size" validate:"min=1"`                      // Maximum number of items taken from the buffer per flush
+}
+
+// RegisterBufferFlags registers command-line flags for sizing a buffer of
+// pending items and choosing its overflow policy.
+//
+// Registered flags:
+//
+//	--buffer-capacity          int      Maximum number of items held by the buffer (default <capacity>)
+//	--buffer-max-bytes         size     Maximum total size of the items held by the buffer, 0 leaves only the capacity limit (default 0B)
+//	--buffer-overflow-policy   string   When full, "overwrite" the oldest item or "drop-newest" (default "overwrite")
+//	--buffer-flush-batch-size  int      Maximum number of items taken from the buffer per flush (default 100)
+//
+// A flag not given on the command line takes the value of its environment
+// variable if set (see EnvVarName and SetEnvPrefix), e.g. KUBENSAGE_BUFFER_CAPACITY;
+// the precedence is flag > environment > default.
+//
+// Parameters:
+//   - fs        The flag set into which the flags will be registered.
+//   - capacity  The default capacity of the buffer.
+//   - opts      Options of the registrar (see WithDefaults and WithPrefix).
+//
+// Returns:
+//
+//	A closure that, when invoked, returns a populated *BufferConfig
+//	containing the values from the parsed flags, checked by Validate, or
+//	an error joining every violation, also reported by ParseOrExit.
+func RegisterBufferFlags(
+	fs *flag.FlagSet,
+	capacity int,
+	opts ...RegisterOption,
+) func() (*BufferConfig, error) {
+	defer bindEnv(fs, flagNames(fs))
+	defer groupFlags(fs, GroupBuffer, flagNames(fs))
+	defer applyOptions(fs, flagNames(fs), opts)
+
+	prefix := optionsPrefix(opts)
+
+	bufferCapacity := fs.Int(prefix+"buffer-capacity", capacity, "Maximum number of items held by the buffer")
+	bufferMaxBytes := Bytes(fs, prefix+"buffer-max-bytes", 0, "Maximum total `size` of the items held by the buffer (e.g., 64MiB; 0 leaves only the capacity limit)")
+	bufferOverflowPolicy := Enum(fs, prefix+"buffer-overflow-policy", []string{"overwrite", "drop-newest"}, "overwrite", "When the buffer is full, overwrite the oldest item or drop the newest")
+	bufferFlushBatchSize := fs.Int(prefix+"buffer-flush-batch-size", 100, "Maximum number of items taken from the buffer per flush")
+
+	return checked(fs, func() (*BufferConfig, error) {
+		cfg := &BufferConfig{
+			BufferCapacity:       *bufferCapacity,
+			BufferMaxBytes:       *bufferMaxBytes,
+			BufferOverflowPolicy: *bufferOverflowPolicy,
+			BufferFlushBatchSize: *bufferFlushBatchSize,
+		}
+		if err := validateConfig(cfg, prefix); err != nil {
+			return nil, err
+		}
+		recordSources(fs, cfg, prefix)
+		return cfg, nil
+	})
+}
@@ -0,0 +1,232 @@
+package gocli
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a size in bytes, written in a human-friendly form on the command
+// line and in configuration files (e.g., "512KiB", "10MB", "1.5GiB").
+type ByteSize int64
+
+// Decimal (SI) and binary (IEC) size units.
+const (
+	Byte ByteSize = 1
+
+	KB ByteSize = 1000
+	MB          = 1000 * KB
+	GB          = 1000 * MB
+	TB          = 1000 * GB
+
+	KiB ByteSize = 1024
+	MiB          = 1024 * KiB
+	GiB          = 1024 * MiB
+	TiB          = 1024 * GiB
+)
+
+// byteSizeUnits maps the lower-cased unit suffixes to their size.
+var byteSizeUnits = map[string]ByteSize{
+	"":  Byte,
+	"b": Byte,
+	"k": KB, "kb": KB, "ki": KiB, "kib": KiB,
+	"m": MB, "mb": MB, "mi": MiB, "mib": MiB,
+	"g": GB, "gb": GB, "gi": GiB, "gib": GiB,
+	"t": TB, "tb": TB, "ti": TiB, "tib": TiB,
+}
+
+// ParseByteSize parses a size: a non-negative number, possibly fractional,
+// followed by an optional unit. Units are case-insensitive: B, decimal K/KB,
+// M/MB, G/GB, T/TB (powers of 1000), and binary Ki/KiB, Mi/MiB, Gi/GiB, Ti/TiB
+// (powers of 1024). A bare number counts bytes.
+//
+// Parameters:
+//   - s  The size (e.g., "64MiB", "1.5 GB", "4096").
+//
+// Returns:
+//
+//	The size, rounded to the nearest byte, or an error if s is malformed,
+//	negative or overflows.
+func ParseByteSize(
+	s string,
+) (ByteSize, error) {
+	return parseByteSize(s, Byte)
+}
+
+// parseByteSize parses a size whose bare numbers count multiples of unit.
+func parseByteSize(
+	s string,
+	unit ByteSize,
+) (ByteSize, error) {
+	trimmed := strings.TrimSpace(s)
+	i := strings.IndexFunc(trimmed, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(trimmed)
+	}
+	number, suffix := trimmed[:i], strings.ToLower(strings.TrimSpace(trimmed[i:]))
+
+	if number == "" {
+		return 0, fmt.Errorf("invalid size %q (e.g., 512KiB, 10MB, 1.5GiB)", s)
+	}
+	if suffix != "" {
+		var ok bool
+		if unit, ok = byteSizeUnits[suffix]; !ok {
+			return 0, fmt.Errorf("invalid size %q: unknown unit %q (e.g., 512KiB, 10MB, 1.5GiB)", s, trimmed[i:])
+		}
+	}
+
+	if !strings.Contains(number, ".") {
+		n, err := strconv.ParseInt(number, 10, 64)
+		if err != nil || n > math.MaxInt64/int64(unit) {
+			return 0, fmt.Errorf("invalid size %q: out of range", s)
+		}
+		return ByteSize(n) * unit, nil
+	}
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q (e.g., 512KiB, 10MB, 1.5GiB)", s)
+	}
+	bytes := math.Round(f * float64(unit))
+	if bytes >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid size %q: out of range", s)
+	}
+	return ByteSize(bytes), nil
+}
+
+// Bytes defines a ByteSize flag with the behavior of the gocli registrars: it
+// falls back to its environment variable (see EnvVarName) when not given on
+// the command line, and its default is shown in the help in its
+// human-friendly form (e.g., "(default 64MiB)").
+//
+// Parameters:
+//   - fs     The flag set into which the flag is registered.
+//   - name   The flag name.
+//   - value  The default value.
+//   - usage  The flag help.
+//
+// Returns:
+//
+//	A pointer to the flag value.
+//
+// Example:
+//
+//	maxMemory := gocli.Bytes(fs, "buffer-max-memory", 64*gocli.MiB, "Memory held by the buffer at most")
+func Bytes(
+	fs *flag.FlagSet,
+	name string,
+	value ByteSize,
+	usage string,
+) *ByteSize {
+	return bytesVar(fs, name, value, Byte, usage)
+}
+
+// byteSizeFlag is a flag.Value holding a ByteSize, whose bare numbers count
+// multiples of unit (e.g., MiB for flags that used to take a number of MiB).
+type byteSizeFlag struct {
+	value *ByteSize
+	unit  ByteSize
+}
+
+// bytesVar defines a ByteSize flag whose bare numbers count multiples of unit.
+func bytesVar(
+	fs *flag.FlagSet,
+	name string,
+	value ByteSize,
+	unit ByteSize,
+	usage string,
+) *ByteSize {
+	defer bindEnv(fs, flagNames(fs))
+
+	p := new(ByteSize)
+	*p = value
+	fs.Var(&byteSizeFlag{value: p, unit: unit}, name, usage)
+	return p
+}
+
+// String returns the current size in its human-friendly form.
+func (f *byteSizeFlag) String() string {
+	if f.value == nil {
+		return ByteSize(0).String()
+	}
+	return f.value.String()
+}
+
+// Set parses a size.
+func (f *byteSizeFlag) Set(
+	s string,
+) error {
+	size, err := parseByteSize(s, f.unit)
+	if err != nil {
+		return err
+	}
+	*f.value = size
+	return nil
+}
+
+// String returns the size in its shortest form using the largest unit
+// dividing it exactly, binary or decimal (e.g., "64MiB", "10MB", "1500B").
+func (b ByteSize) String() string {
+	n, sign := b, ""
+	if n < 0 {
+		n, sign = -n, "-"
+	}
+	binary := formatByteSize(n, []ByteSize{TiB, GiB, MiB, KiB}, []string{"TiB", "GiB", "MiB", "KiB"})
+	decimal := formatByteSize(n, []ByteSize{TB, GB, MB, KB}, []string{"TB", "GB", "MB", "KB"})
+	if len(decimal) < len(binary) {
+		return sign + decimal
+	}
+	return sign + binary
+}
+
+// formatByteSize formats a non-negative size with the first of units dividing
+// it exactly, or in bytes.
+func formatByteSize(
+	n ByteSize,
+	units []ByteSize,
+	names []string,
+) string {
+	if n == 0 {
+		return "0B"
+	}
+	for i, unit := range units {
+		if n%unit == 0 {
+			return strconv.FormatInt(int64(n/unit), 10) + names[i]
+		}
+	}
+	return strconv.FormatInt(int64(n), 10) + "B"
+}
+
+// Set parses a size, so that *ByteSize can be used with fs.Var.
+func (b *ByteSize) Set(
+	s string,
+) error {
+	size, err := ParseByteSize(s)
+	if err != nil {
+		return err
+	}
+	*b = size
+	return nil
+}
+
+// MarshalText returns the size in its human-friendly form.
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText parses a size, e.g., for LoadConfigFile.
+func (b *ByteSize) UnmarshalText(
+	text []byte,
+) error {
+	return b.Set(string(text))
+}
+
+// ceilMiB returns the number of MiB holding a size, rounded up.
+func ceilMiB(
+	b ByteSize,
+) int {
+	return int((b + MiB - 1) / MiB)
+}
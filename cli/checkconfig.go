@@ -0,0 +1,301 @@
+package gocli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// preflightTimeout bounds the resolution of the addresses checked by the
+// preflight checks of the registrars.
+const preflightTimeout = 5 * time.Second
+
+// preflight is a check run by ParseOrExit in --check-config mode only.
+type preflight struct {
+	name  string
+	check func() error
+}
+
+var (
+	checkConfigMu sync.RWMutex
+	checkConfig   = make(map[*flag.FlagSet]*bool)       // values of the --check-config flags, by flag set
+	preflights    = make(map[*flag.FlagSet][]preflight) // preflight checks, by flag set
+)
+
+// RegisterCheckConfigFlag registers the --check-config flag, with which
+// ParseOrExit validates the configuration and exits instead of returning, so
+// that a CI pipeline or an init container can check a configuration before
+// rolling it out.
+//
+// Registered flags:
+//
+//	--check-config  bool  Validate the configuration, run the preflight checks and exit (default false)
+//
+// In this mode, ParseOrExit parses the command line, loads the configuration
+// file and runs the checks (see AddCheck), then the preflight checks (see
+// AddPreflight) if the configuration is valid. The registrars add preflight
+// checks probing the log directories for writability and opening the log files
+// without writing to them, and resolving the gRPC target and loading its TLS
+// files without connecting. Every finding is
+// reported, and the process exits with 0 if all checks passed, else with
+// ExitCheckFailed (1); an invalid command line still exits with ExitUsage.
+//
+// Parameters:
+//   - fs  The flag set into which the flag will be registered.
+//
+// Example:
+//
+//	gocli.RegisterCheckConfigFlag(flag.CommandLine)
+//	loadConfig := gocli.RegisterConfigFileFlag(flag.CommandLine)
+//	logConfig := gocli.RegisterLogStdAndFileFlags(flag.CommandLine, "agent")
+//	gocli.ParseOrExit(flag.CommandLine, os.Args[1:], nil) // exits with --check-config
+func RegisterCheckConfigFlag(
+	fs *flag.FlagSet,
+) {
+	defer bindEnv(fs, flagNames(fs))
+	defer groupFlags(fs, GroupGeneral, flagNames(fs))
+
+	enabled := fs.Bool("check-config", false, "Validate the configuration, run the preflight checks and exit (0 if valid, 1 otherwise)")
+
+	checkConfigMu.Lock()
+	defer checkConfigMu.Unlock()
+	checkConfig[fs] = enabled
+}
+
+// AddPreflight adds a check run by ParseOrExit in --check-config mode only
+// (see RegisterCheckConfigFlag), after the checks added with AddCheck passed,
+// e.g., probing a dependency that the binary would use at startup. Preflight
+// checks run in the order they were added, and must not have lasting side
+// effects.
+//
+// Parameters:
+//   - fs     The flag set whose configuration is checked.
+//   - name   The name of the check in the report (e.g., "database").
+//   - check  The check, returning an error, possibly joining several, if it
+//     fails.
+//
+// Example:
+//
+//	gocli.AddPreflight(fs, "state directory", func() error {
+//		return unix.Access(*stateDir, unix.W_OK)
+//	})
+func AddPreflight(
+	fs *flag.FlagSet,
+	name string,
+	check func() error,
+) {
+	checkConfigMu.Lock()
+	defer checkConfigMu.Unlock()
+	preflights[fs] = append(preflights[fs], preflight{name: name, check: check})
+}
+
+// checkConfigEnabled reports whether --check-config is set on fs.
+func checkConfigEnabled(
+	fs *flag.FlagSet,
+) bool {
+	checkConfigMu.RLock()
+	defer checkConfigMu.RUnlock()
+	enabled := checkConfig[fs]
+	return enabled != nil && *enabled
+}
+
+// runCheckConfig reports the result of the checks of fs, then runs and
+// reports its preflight checks if the checks passed, returning the exit
+// status of --check-config.
+func runCheckConfig(
+	fs *flag.FlagSet,
+	logger *zap.Logger,
+	errs []error,
+) int {
+	checkConfigMu.RLock()
+	pending := append([]preflight(nil), preflights[fs]...)
+	checkConfigMu.RUnlock()
+
+	status := 0
+	report := func(name string, err error) {
+		switch {
+		case err != nil:
+			status = ExitCheckFailed
+			reportErrors(fs, logger, "check failed: "+name, err)
+		case logger != nil:
+			logger.Info("check passed: " + name)
+		default:
+			_, _ = fmt.Fprintf(fs.Output(), "check passed: %s\n", name)
+		}
+	}
+
+	report("configuration", errors.Join(errs...))
+	if status != 0 {
+		return status
+	}
+	for _, p := range pending {
+		report(p.name, p.check())
+	}
+	return status
+}
+
+// preflightLogFile checks that a log file can be written: its directory is
+// writable or can be created, and the file can be opened for appending.
+func preflightLogFile(
+	path string,
+) error {
+	if path == "" {
+		return nil
+	}
+	if err := preflightDirWritable(path); err != nil {
+		return err
+	}
+	return preflightOpenFile(path)
+}
+
+// preflightDirWritable checks that files can be created in the directory of
+// path, or in path itself if it is a directory, or in its nearest existing
+// ancestor if it is missing, since log directories are created at startup.
+// Unlike the dir-writable rule, it creates a file to find out: permission bits
+// do not account for ACLs, read-only mounts or Windows attributes.
+func preflightDirWritable(
+	path string,
+) error {
+	dir, err := existingDir(path)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("directory %s is not writable: %w", dir, err)
+	}
+	name := f.Name()
+	_ = f.Close()
+	_ = os.Remove(name)
+	return nil
+}
+
+// preflightOpenFile checks that a log file can be opened for appending,
+// without writing to it. A file created by the check is removed, and a missing
+// directory, which the logger creates, is left to preflightDirWritable.
+func preflightOpenFile(
+	path string,
+) error {
+	if path == "" {
+		return nil
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		if _, err := os.Stat(filepath.Dir(path)); err != nil {
+			return nil
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err != nil {
+			return fmt.Errorf("cannot create %s: %w", path, err)
+		}
+		_ = f.Close()
+		return os.Remove(path)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return fmt.Errorf("cannot open %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// grpcDefaultPort is the port of a dns target without one, as defaulted by the
+// dns resolver of gRPC.
+const grpcDefaultPort = "443"
+
+// preflightGrpcTarget checks that the address of a gRPC target resolves,
+// without connecting to it. The target is parsed the way gRPC does: an address
+// without a known scheme is a dns target, whose port defaults to 443, and the
+// dns scheme accepts the dns:host:port, dns:///host:port and
+// dns://server/host:port forms, the latter resolving with the given DNS server.
+// Targets of schemes other than dns and passthrough (e.g., unix) are not
+// resolved.
+func preflightGrpcTarget(
+	target string,
+) error {
+	scheme, authority, endpoint := "dns", "", target
+	if u, err := url.Parse(target); err == nil && u.Scheme != "" {
+		switch {
+		case u.Scheme == "dns" || u.Scheme == "passthrough":
+			scheme, authority, endpoint = u.Scheme, u.Host, u.Path
+			if endpoint == "" {
+				endpoint = u.Opaque
+			}
+			endpoint = strings.TrimPrefix(endpoint, "/")
+		case u.Scheme == "unix" || u.Scheme == "unix-abstract" || strings.Contains(target, "://"):
+			return nil // not a network address, or a custom resolver
+		}
+		// e.g., "localhost:50051", parsed with the scheme "localhost"
+	}
+
+	defaultPort := ""
+	if scheme == "dns" {
+		defaultPort = grpcDefaultPort
+	}
+	host, err := grpcTargetHost(endpoint, defaultPort)
+	if err != nil {
+		return fmt.Errorf("invalid gRPC target %q: %w", target, err)
+	}
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+
+	resolver := net.DefaultResolver
+	if authority != "" {
+		server := authority
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			server = net.JoinHostPort(strings.Trim(server, "[]"), "53")
+		}
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, server)
+			},
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), preflightTimeout)
+	defer cancel()
+	if _, err := resolver.LookupHost(ctx, host); err != nil {
+		return fmt.Errorf("cannot resolve gRPC target %q: %w", target, err)
+	}
+	return nil
+}
+
+// grpcTargetHost returns the host of the endpoint of a gRPC target, as
+// host:port, [host]:port, or, if defaultPort is not empty, a bare host or IP
+// address. An empty host is localhost, as for gRPC.
+func grpcTargetHost(
+	endpoint string,
+	defaultPort string,
+) (string, error) {
+	if endpoint == "" {
+		return "", errors.New("missing address")
+	}
+	if defaultPort != "" && net.ParseIP(endpoint) != nil {
+		return endpoint, nil
+	}
+	host, port, err := net.SplitHostPort(endpoint)
+	var addrErr *net.AddrError
+	if defaultPort != "" && errors.As(err, &addrErr) && addrErr.Err == "missing port in address" {
+		host, port, err = net.SplitHostPort(endpoint + ":" + defaultPort)
+	}
+	if err != nil {
+		return "", err
+	}
+	if port == "" {
+		return "", errors.New("missing port after port-separator colon")
+	}
+	if host == "" {
+		host = "localhost"
+	}
+	return host, nil
+}
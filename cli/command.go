@@ -0,0 +1,272 @@
+package gocli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// CommandSet dispatches a command line to subcommands (e.g., "relay run",
+// "relay migrate"), each with its own flags and run function, sharing the
+// global flags of the set.
+//
+// Global flags are accepted both before and after the subcommand name
+// ("relay --log-level debug run" and "relay run --log-level debug"), so that
+// registrars common to every subcommand (e.g., RegisterLogStdFlags) are called
+// once on Global.
+//
+// Example:
+//
+//	commands := gocli.NewCommandSet("relay")
+//	logConfig := gocli.RegisterLogStdFlags(commands.Global)
+//	runFlags := commands.Add("run", "Run the relay", func(args []string) error { ... })
+//	grpcConfig := gocli.RegisterGrpcServerFlags(runFlags, ":50051")
+//	commands.Add("migrate", "Migrate the storage schema", migrate)
+//	commands.AddVersionCommand()
+//	if err := commands.Run(os.Args[1:]); err != nil {
+//		if errors.Is(err, flag.ErrHelp) {
+//			os.Exit(0)
+//		}
+//		fmt.Fprintln(os.Stderr, err)
+//		os.Exit(2)
+//	}
+type CommandSet struct {
+	Global *flag.FlagSet // flags shared by every subcommand
+
+	name     string
+	output   io.Writer
+	commands []*command
+}
+
+// command is a subcommand of a CommandSet.
+type command struct {
+	name  string
+	usage string
+	flags *flag.FlagSet
+	run   func(args []string) error
+}
+
+// NewCommandSet creates an empty set of subcommands for an application.
+//
+// Parameters:
+//   - name  The name of the application, used in the usage output.
+//
+// Returns:
+//
+//	A pointer to a new CommandSet whose usage is written to stderr.
+func NewCommandSet(
+	name string,
+) *CommandSet {
+	s := &CommandSet{
+		Global: flag.NewFlagSet(name, flag.ContinueOnError),
+		name:   name,
+		output: os.Stderr,
+	}
+	s.Global.SetOutput(s.output)
+	s.Global.Usage = s.usage
+	ownUsage(s.Global)
+	return s
+}
+
+// Add registers a subcommand.
+//
+// Parameters:
+//   - name   The subcommand name (e.g., "run").
+//   - usage  A one-line description shown in the usage output.
+//   - run    The function run with the positional arguments left after the
+//     flags of the subcommand, once they match those declared with
+//     PositionalArg on the returned flag set, if any.
+//
+// Returns:
+//
+//	The flag set of the subcommand, into which its own flags are registered.
+func (s *CommandSet) Add(
+	name string,
+	usage string,
+	run func(args []string) error,
+) *flag.FlagSet {
+	if s.lookup(name) != nil {
+		panic(fmt.Sprintf("gocli: subcommand %q registered twice", name))
+	}
+
+	fs := flag.NewFlagSet(s.name+" "+name, flag.ContinueOnError)
+	fs.SetOutput(s.output)
+	cmd := &command{name: name, usage: usage, flags: fs, run: run}
+	fs.Usage = func() { s.commandUsage(cmd) }
+	ownUsage(fs)
+	s.commands = append(s.commands, cmd)
+	return fs
+}
+
+// AddVersionCommand registers a "version" subcommand printing the build
+// metadata of the binary on standard output, like RegisterVersionFlag.
+func (s *CommandSet) AddVersionCommand() {
+	s.Add("version", "Print version information", func([]string) error {
+		printVersion(os.Stdout, s.name)
+		return nil
+	})
+}
+
+// Run parses the global flags, selects the subcommand named by the first
+// remaining argument, parses its flags and runs it. "help" and "help <command>"
+// print the usage of the set or of a subcommand.
+//
+// Parameters:
+//   - args  The command-line arguments, without the program name (e.g., os.Args[1:]).
+//
+// Returns:
+//
+//	The error of the subcommand; flag.ErrHelp if help was requested; or an
+//	error for invalid flags or arguments or a missing or unknown subcommand,
+//	after printing the relevant usage.
+func (s *CommandSet) Run(
+	args []string,
+) error {
+	if err := s.Global.Parse(args); err != nil {
+		return err
+	}
+	args = s.Global.Args()
+
+	if len(args) == 0 {
+		s.usage()
+		return errors.New("missing command")
+	}
+	name, args := args[0], args[1:]
+
+	if name == "help" {
+		if len(args) > 0 {
+			if cmd := s.lookup(args[0]); cmd != nil {
+				s.shareGlobalFlags(cmd)
+				cmd.flags.Usage()
+				return flag.ErrHelp
+			}
+		}
+		s.usage()
+		return flag.ErrHelp
+	}
+
+	cmd := s.lookup(name)
+	if cmd == nil {
+		s.usage()
+		return fmt.Errorf("unknown command %q", name)
+	}
+
+	s.shareGlobalFlags(cmd)
+	if err := cmd.flags.Parse(args); err != nil {
+		return err
+	}
+	if hasArgs(cmd.flags) {
+		if _, err := ParseArgs(cmd.flags); err != nil {
+			cmd.flags.Usage()
+			return err
+		}
+	}
+	return cmd.run(cmd.flags.Args())
+}
+
+// lookup returns the subcommand with the given name, or nil.
+func (s *CommandSet) lookup(
+	name string,
+) *command {
+	for _, cmd := range s.commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// shareGlobalFlags defines the global flags in the flag set of cmd, unless cmd
+// defines a flag with the same name. Values given after the subcommand name
+// are set through Global, so that they count as set for Global.Visit (e.g.,
+// for Required and RegisterConfigFileFlag).
+func (s *CommandSet) shareGlobalFlags(
+	cmd *command,
+) {
+	s.Global.VisitAll(func(f *flag.Flag) {
+		if cmd.flags.Lookup(f.Name) == nil {
+			cmd.flags.Var(&forwardFlag{fs: s.Global, target: f}, f.Name, f.Usage)
+			cmd.flags.Lookup(f.Name).DefValue = f.DefValue
+			if isHidden(s.Global, f.Name) {
+				Hide(cmd.flags, f.Name)
+			}
+		}
+	})
+}
+
+// forwardFlag is a flag.Value setting another flag of a flag set, which then
+// counts as set for fs.Visit.
+type forwardFlag struct {
+	fs     *flag.FlagSet
+	target *flag.Flag
+}
+
+// String returns the value of the target flag.
+func (f *forwardFlag) String() string {
+	if f.target == nil {
+		return ""
+	}
+	return f.target.Value.String()
+}
+
+// Set sets the target flag.
+func (f *forwardFlag) Set(
+	s string,
+) error {
+	return f.fs.Set(f.target.Name, s)
+}
+
+// IsBoolFlag reports whether the target flag may be given without a value.
+func (f *forwardFlag) IsBoolFlag() bool {
+	b, ok := f.target.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// usage prints the subcommands and the global flags.
+func (s *CommandSet) usage() {
+	_, _ = fmt.Fprintf(s.output, "Usage: %s [global flags] <command> [flags] [args]\n\nCommands:\n", s.name)
+	w := tabwriter.NewWriter(s.output, 0, 0, 2, ' ', 0)
+	for _, cmd := range s.commands {
+		_, _ = fmt.Fprintf(w, "  %s\t%s\n", cmd.name, cmd.usage)
+	}
+	_ = w.Flush()
+
+	if hasFlags(s.Global) {
+		_, _ = fmt.Fprintln(s.output)
+		printDefaults(s.Global, true)
+	}
+	_, _ = fmt.Fprintf(s.output, "\nRun \"%s help <command>\" for the flags of a command.\n", s.name)
+}
+
+// commandUsage prints the description, arguments and flags of a subcommand.
+func (s *CommandSet) commandUsage(
+	cmd *command,
+) {
+	synopsis := "[args]"
+	if hasArgs(cmd.flags) {
+		synopsis = argsSynopsis(cmd.flags)
+	}
+	_, _ = fmt.Fprintf(s.output, "Usage: %s %s [flags] %s\n\n%s\n",
+		s.name, cmd.name, synopsis, strings.TrimSpace(cmd.usage))
+	if hasArgs(cmd.flags) {
+		_, _ = fmt.Fprintln(s.output)
+		printArgs(s.output, cmd.flags)
+	}
+	if hasFlags(cmd.flags) {
+		_, _ = fmt.Fprintln(s.output)
+		printDefaults(cmd.flags, true)
+	}
+}
+
+// hasFlags reports whether fs defines any flag.
+func hasFlags(
+	fs *flag.FlagSet,
+) bool {
+	found := false
+	fs.VisitAll(func(*flag.Flag) { found = true })
+	return found
+}
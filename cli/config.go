@@ -1,70 +1,320 @@
 package gocli
 
-import "flag"
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+)
 
 // LogStdAndFileConfig holds configuration options for logging to both
 // standard output and a rotating log file.
 type LogStdAndFileConfig struct {
-	LogLevel      string // Log verbosity level (e.g., "info", "debug", "error")
-	LogFile       string // Path to the log file
-	LogMaxSize    int    // Maximum size (in MB) before log file is rotated
-	LogMaxBackups int    // Maximum number of old log files to retain
-	LogMaxAge     int    // Maximum age (in days) to retain old log files
-	LogCompress   bool   // Whether to compress old log files
+	LogLevel              string        `log:"log-level" validate:"required,oneof=debug info warn error dpanic panic fatal"`   // Log verbosity level (e.g., "info", "debug", "error")
+	LogLevelOverrides     string        `log:"log-level-overrides"`                                                            // Per-subsystem levels as name=level pairs (e.g., "grpc=debug,buffer=warn")
+	LogFile               string        `log:"log-file" validate:"required-unless=log-output journald"`                        // Path to the log file
+	LogErrorFile          string        `log:"log-error-file" validate:"dir-writable"`                                         // Path to an additional file receiving only warn and above; empty disables it
+	LogDirMode            string        `log:"log-dir-mode"`                                                                   // Octal permissions of log directories created at startup (e.g., "0750")
+	LogFileMode           string        `log:"log-file-mode"`                                                                  // Octal permissions of the log files, kept across rotations (e.g., "0640")
+	LogFileOwner          string        `log:"log-file-owner"`                                                                 // Owner of the log files as user[:group] names or IDs; empty leaves the process owner
+	LogFileErrorPolicy    string        `log:"log-file-error-policy" validate:"oneof=drop block stdout"`                       // What to do with entries the file cannot take (e.g., disk full): "drop", "block" or "stdout"
+	LogMaxSize            int           `log:"log-max-size" validate:"min=0"`                                                  // Maximum size (in MiB) before log file is rotated; the flag also takes sizes such as 100MiB
+	LogMaxBackups         int           `log:"log-max-backups" validate:"min=0"`                                               // Maximum number of old log files to retain
+	LogMaxAge             int           `log:"log-max-age" validate:"min=0"`                                                   // Maximum age (in days) to retain old log files
+	LogCompress           bool          `log:"log-compress"`                                                                   // Whether to compress old log files
+	LogEncoder            string        `log:"log-encoder" validate:"oneof=json console ecs"`                                  // Output encoding: "json", "console" or "ecs" (Elastic Common Schema)
+	LogRotateOnSIGHUP     bool          `log:"log-rotate-on-sighup"`                                                           // Whether to rotate the log file when SIGHUP is received
+	LogRotateInterval     time.Duration `log:"log-rotate-interval" validate:"min=0s"`                                          // Time-based rotation aligned on UTC (e.g., 24h for midnight UTC); 0 rotates by size only
+	LogEncryptKeyFile     string        `log:"log-encrypt-key-file" validate:"file-exists"`                                    // File holding the AES-256 key encrypting rotated log files at rest; empty disables encryption
+	LogEncryptKeyEnv      string        `log:"log-encrypt-key-env" validate:"excluded-with=log-encrypt-key-file"`              // Environment variable holding the key, used when LogEncryptKeyFile is empty
+	LogOutput             string        `log:"log-output" validate:"oneof=file journald"`                                      // Output mode: "file" (stdout + file) or "journald"
+	LogStdoutLevel        string        `log:"log-stdout-level" validate:"oneof=debug info warn error dpanic panic fatal"`     // Stdout level override; empty uses LogLevel
+	LogFileLevel          string        `log:"log-file-level" validate:"oneof=debug info warn error dpanic panic fatal"`       // File level override; empty uses LogLevel
+	LogTimeFormat         string        `log:"log-time-format" validate:"timeformat"`                                          // Timestamp format: "iso8601", "rfc3339", "rfc3339nano", "epochmillis" or a Go layout
+	LogTimeUTC            bool          `log:"log-time-utc"`                                                                   // Whether timestamps are written in UTC instead of local time
+	LogTimeZone           string        `log:"log-time-zone" validate:"timezone,excluded-with=log-time-utc"`                   // Time zone of the timestamps, a tz database name (e.g., "Europe/Rome"); empty uses the local zone
+	LogSplitStderr        bool          `log:"log-split-stderr"`                                                               // Whether warn and above go to stderr instead of stdout
+	LogSamplingInitial    int           `log:"log-sampling-initial" validate:"min=0"`                                          // Identical entries logged per second before sampling; 0 disables sampling
+	LogSamplingThereafter int           `log:"log-sampling-thereafter" validate:"min=0"`                                       // After LogSamplingInitial, log one entry out of every N
+	LogThrottleRate       int           `log:"log-throttle-rate" validate:"min=0"`                                             // Debug or info entries per second allowed per level before the excess is dropped; 0 disables throttling
+	LogThrottleBurst      int           `log:"log-throttle-burst" validate:"min=0"`                                            // Burst size of the throttle; 0 uses LogThrottleRate
+	LogOTLPEndpoint       string        `log:"log-otlp-endpoint"`                                                              // OTLP/HTTP logs endpoint (e.g., "http://collector:4318/v1/logs"); empty disables export
+	LogOTLPBatchSize      int           `log:"log-otlp-batch-size" validate:"min=0"`                                           // Maximum number of records per OTLP export request
+	LogOTLPFlushInterval  time.Duration `log:"log-otlp-flush-interval" validate:"min=0s"`                                      // Maximum time a record waits before being exported
+	LogLokiURL            string        `log:"log-loki-url"`                                                                   // Loki base URL (e.g., "http://loki:3100"); empty disables the Loki sink
+	LogLokiLabels         string        `log:"log-loki-labels"`                                                                // Static stream labels as key=value pairs (e.g., "app=agent,node=worker-1")
+	LogLokiBatchSize      int           `log:"log-loki-batch-size" validate:"min=0"`                                           // Maximum number of lines per Loki push
+	LogLokiFlushInterval  time.Duration `log:"log-loki-flush-interval" validate:"min=0s"`                                      // Maximum time a line waits before being pushed
+	LogDedupInterval      time.Duration `log:"log-dedup-interval" validate:"min=0s"`                                           // Suppress identical consecutive entries, summarizing them at this interval; 0 disables
+	LogCaller             bool          `log:"log-caller"`                                                                     // Whether entries include the calling file and line
+	LogCallerSkip         int           `log:"log-caller-skip" validate:"min=0"`                                               // Extra stack frames to skip when reporting the caller, for logging wrappers
+	LogStacktraceLevel    string        `log:"log-stacktrace-level" validate:"oneof=debug info warn error dpanic panic fatal"` // Minimum level at which a stack trace is attached; empty disables stack traces
+	LogMetrics            bool          `log:"log-metrics"`                                                                    // Whether entries are counted by level and logger in Prometheus
+	LogMetadata           bool          `log:"log-metadata"`                                                                   // Whether hostname, PID, app name and version are attached to every entry
+	LogK8sMetadata        bool          `log:"log-k8s-metadata"`                                                               // Whether node, pod and namespace from the Kubernetes downward API (NODE_NAME, POD_NAME, POD_NAMESPACE) are attached to every entry
+	AppName               string        `log:"app-name"`                                                                       // Application name reported with the metadata, set by the registrar
+	AppVersion            string        `log:"app-version"`                                                                    // Application version reported with the metadata, set by the application
 }
 
 // LogStdConfig holds configuration options for logging to standard output only.
 type LogStdConfig struct {
-	LogLevel string // Log verbosity level (e.g., "info", "debug", "error")
+	LogLevel              string        `log:"log-level" validate:"required,oneof=debug info warn error dpanic panic fatal"`   // Log verbosity level (e.g., "info", "debug", "error")
+	LogLevelOverrides     string        `log:"log-level-overrides"`                                                            // Per-subsystem levels as name=level pairs (e.g., "grpc=debug,buffer=warn")
+	LogEncoder            string        `log:"log-encoder" validate:"oneof=json console ecs"`                                  // Output encoding: "json", "console" or "ecs" (Elastic Common Schema)
+	LogDev                bool          `log:"log-dev"`                                                                        // Whether zap's development format (colors, short timestamps, caller) is used instead of LogEncoder
+	LogTimeFormat         string        `log:"log-time-format" validate:"timeformat"`                                          // Timestamp format: "iso8601", "rfc3339", "rfc3339nano", "epochmillis" or a Go layout
+	LogTimeUTC            bool          `log:"log-time-utc"`                                                                   // Whether timestamps are written in UTC instead of local time
+	LogTimeZone           string        `log:"log-time-zone" validate:"timezone,excluded-with=log-time-utc"`                   // Time zone of the timestamps, a tz database name (e.g., "Europe/Rome"); empty uses the local zone
+	LogSplitStderr        bool          `log:"log-split-stderr"`                                                               // Whether warn and above go to stderr instead of stdout
+	LogSamplingInitial    int           `log:"log-sampling-initial" validate:"min=0"`                                          // Identical entries logged per second before sampling; 0 disables sampling
+	LogSamplingThereafter int           `log:"log-sampling-thereafter" validate:"min=0"`                                       // After LogSamplingInitial, log one entry out of every N
+	LogThrottleRate       int           `log:"log-throttle-rate" validate:"min=0"`                                             // Debug or info entries per second allowed per level before the excess is dropped; 0 disables throttling
+	LogThrottleBurst      int           `log:"log-throttle-burst" validate:"min=0"`                                            // Burst size of the throttle; 0 uses LogThrottleRate
+	LogDedupInterval      time.Duration `log:"log-dedup-interval" validate:"min=0s"`                                           // Suppress identical consecutive entries, summarizing them at this interval; 0 disables
+	LogCaller             bool          `log:"log-caller"`                                                                     // Whether entries include the calling file and line
+	LogCallerSkip         int           `log:"log-caller-skip" validate:"min=0"`                                               // Extra stack frames to skip when reporting the caller, for logging wrappers
+	LogStacktraceLevel    string        `log:"log-stacktrace-level" validate:"oneof=debug info warn error dpanic panic fatal"` // Minimum level at which a stack trace is attached; empty disables stack traces
+	LogMetrics            bool          `log:"log-metrics"`                                                                    // Whether entries are counted by level and logger in Prometheus
+	LogMetadata           bool          `log:"log-metadata"`                                                                   // Whether hostname, PID, app name and version are attached to every entry
+	LogK8sMetadata        bool          `log:"log-k8s-metadata"`                                                               // Whether node, pod and namespace from the Kubernetes downward API (NODE_NAME, POD_NAME, POD_NAMESPACE) are attached to every entry
+	AppName               string        `log:"app-name"`                                                                       // Application name reported with the metadata; empty uses the executable name
+	AppVersion            string        `log:"app-version"`                                                                    // Application version reported with the metadata, set by the application
+}
+
+// LogSyslogConfig holds configuration options for logging to a local or remote
+// syslog daemon, optionally teed with standard output.
+type LogSyslogConfig struct {
+	LogLevel            string        `log:"log-level" validate:"required,oneof=debug info warn error dpanic panic fatal"`   // Log verbosity level (e.g., "info", "debug", "error")
+	LogLevelOverrides   string        `log:"log-level-overrides"`                                                            // Per-subsystem levels as name=level pairs (e.g., "grpc=debug,buffer=warn")
+	LogEncoder          string        `log:"log-encoder" validate:"oneof=json console ecs"`                                  // Standard output encoding: "json", "console" or "ecs" (Elastic Common Schema)
+	SyslogNetwork       string        `log:"log-syslog-network" validate:"oneof=udp tcp tls"`                                // Transport: "" for the local daemon, "udp" or "tcp" for a remote one, "tls" for a remote one over TLS (RFC 5425)
+	SyslogAddress       string        `log:"log-syslog-address"`                                                             // Remote daemon address (host:port), ignored for the local daemon
+	SyslogTLSCAFile     string        `log:"log-syslog-tls-ca" validate:"file-exists"`                                       // PEM bundle of the CAs trusted to sign the server certificate; empty uses the system pool
+	SyslogTLSCertFile   string        `log:"log-syslog-tls-cert" validate:"file-exists,required-with=log-syslog-tls-key"`    // PEM client certificate for mutual TLS; empty disables client authentication
+	SyslogTLSKeyFile    string        `log:"log-syslog-tls-key" validate:"file-exists,required-with=log-syslog-tls-cert"`    // PEM private key of the client certificate
+	SyslogTLSServerName string        `log:"log-syslog-tls-server-name"`                                                     // Name expected in the server certificate; empty uses the host of the address
+	SyslogTLSInsecure   bool          `log:"log-syslog-tls-insecure"`                                                        // Whether the server certificate is accepted without verification (testing only)
+	SyslogFacility      string        `log:"log-syslog-facility"`                                                            // Syslog facility name (e.g., "daemon", "local0")
+	SyslogTag           string        `log:"log-syslog-tag"`                                                                 // Tag prepended to every message, usually the application name
+	LogDedupInterval    time.Duration `log:"log-dedup-interval" validate:"min=0s"`                                           // Suppress identical consecutive entries, summarizing them at this interval; 0 disables
+	LogCaller           bool          `log:"log-caller"`                                                                     // Whether entries include the calling file and line
+	LogCallerSkip       int           `log:"log-caller-skip" validate:"min=0"`                                               // Extra stack frames to skip when reporting the caller, for logging wrappers
+	LogStacktraceLevel  string        `log:"log-stacktrace-level" validate:"oneof=debug info warn error dpanic panic fatal"` // Minimum level at which a stack trace is attached; empty disables stack traces
+	LogMetrics          bool          `log:"log-metrics"`                                                                    // Whether entries are counted by level and logger in Prometheus
+	LogMetadata         bool          `log:"log-metadata"`                                                                   // Whether hostname, PID, app name and version are attached to every entry
+	LogK8sMetadata      bool          `log:"log-k8s-metadata"`                                                               // Whether node, pod and namespace from the Kubernetes downward API (NODE_NAME, POD_NAME, POD_NAMESPACE) are attached to every entry
+	AppName             string        `log:"app-name"`                                                                       // Application name reported with the metadata, set by the registrar
+	AppVersion          string        `log:"app-version"`                                                                    // Application version reported with the metadata, set by the application
+}
+
+// LogElasticConfig holds configuration options for shipping logs to
+// Elasticsearch through the bulk API.
+type LogElasticConfig struct {
+	ElasticURL           string        `log:"log-elastic-url"`                              // Elasticsearch base URL (e.g., "http://elasticsearch:9200")
+	ElasticIndexPrefix   string        `log:"log-elastic-index-prefix"`                     // Daily indices are named <prefix>-YYYY.MM.DD
+	ElasticUsername      string        `log:"log-elastic-username"`                         // Basic auth user; empty disables authentication
+	ElasticPassword      SecretString  `log:"log-elastic-password"`                         // Basic auth password (literal, @file or env:VAR), logged masked
+	ElasticBatchSize     int           `log:"log-elastic-batch-size" validate:"min=0"`      // Maximum number of documents per bulk request
+	ElasticFlushInterval time.Duration `log:"log-elastic-flush-interval" validate:"min=0s"` // Maximum time a document waits before being sent
+}
+
+// LogAuditConfig holds configuration options for the audit log, a separate
+// append-only file of security-relevant events.
+type LogAuditConfig struct {
+	AuditFile    string `log:"log-audit-file" validate:"required,dir-writable"` // Path to the audit log file
+	AuditDirMode string `log:"log-audit-dir-mode"`                              // Octal permissions of the audit log directory if created at startup (e.g., "0700")
 }
 
 // RegisterLogStdAndFileFlags registers command-line flags for configuring
 // both standard output and file-based logging with rotation settings.
 //
 // The provided FlagSet `fs` is used to define flags such as log level, log file path,
-// max size, backup count, age, and compression behavior. The `appName` is used
-// to generate a default log file path (e.g., /var/log/kubensage/myapp.log).
+// max size, backup count, age, compression behavior, and output encoding. The `appName` is used
+// to generate a platform-aware default log file path (see DefaultLogFile).
 //
 // Registered flags:
 //
-//	--log-level        string   Log verbosity level (default "info")
-//	--log-file         string   Path to log file (default "/var/log/kubensage/<appName>.log")
-//	--log-max-size     int      Max log file size in MB before rotation (default 10)
-//	--log-max-backups  int      Max number of old log files to retain (default 5)
-//	--log-max-age      int      Max age in days to retain old log files (default 30)
-//	--log-compress     bool     Whether to compress old log files (default true)
+//	--log-level                string     Log verbosity level, one of ValidLogLevels, case-insensitive (default "info")
+//	--log-level-overrides      string     Per-subsystem levels as name=level pairs (default "")
+//	--log-file                 string     Path to log file (default DefaultLogFile(appName))
+//	--log-error-file           string     Additional file receiving only warn and above, empty disables (default "")
+//	--log-dir-mode             string     Octal permissions of log directories created at startup (default "0755")
+//	--log-file-mode            string     Octal permissions of the log files (default "0600")
+//	--log-file-owner           string     Owner of the log files as user[:group], empty leaves it unchanged (default "")
+//	--log-file-error-policy    string     On write errors (e.g., disk full), "drop", "block" or "stdout" (default "drop")
+//	--log-max-size             size       Max log file size before rotation, e.g. 100MiB, a bare number counts MiB (default 10MiB)
+//	--log-max-backups          int        Max number of old log files to retain (default 5)
+//	--log-max-age              int        Max age in days to retain old log files (default 30)
+//	--log-compress             bool       Whether to compress old log files (default true)
+//	--log-encoder              string     Output encoding, "json", "console" or "ecs" (default "json")
+//	--log-rotate-on-sighup     bool       Rotate the log file when SIGHUP is received (default false)
+//	--log-rotate-interval      duration   Also rotate at every multiple of this interval aligned on UTC, e.g. 24h, 0 disables (default 0s)
+//	--log-encrypt-key-file     string     Encrypt rotated log files with the AES-256 key in this file, empty disables (default "")
+//	--log-encrypt-key-env      string     Encrypt rotated log files with the AES-256 key in this variable, empty disables (default "")
+//	--log-output               string     Output mode, "file" (stdout + file) or "journald" (default "file")
+//	--log-stdout-level         string     Stdout level, overrides --log-level when set (default "")
+//	--log-file-level           string     File level, overrides --log-level when set (default "")
+//	--log-time-format          format     Timestamp format, one of ValidTimeFormats or a Go layout (default "iso8601")
+//	--log-time-utc             bool       Write timestamps in UTC instead of local time (default false)
+//	--log-time-zone            zone       Time zone of the timestamps, e.g. Europe/Rome, empty uses the local zone (default "")
+//	--log-split-stderr         bool       Send warn and above to stderr instead of stdout (default false)
+//	--log-sampling-initial     int        Identical entries per second before sampling, 0 disables (default 0)
+//	--log-sampling-thereafter  int        Log one entry out of every N once sampling kicks in (default 100)
+//	--log-throttle-rate        int        Debug or info entries per second per level before dropping the excess, 0 disables (default 0)
+//	--log-throttle-burst       int        Burst size of the throttle, 0 uses the rate (default 0)
+//	--log-otlp-endpoint        string     OTLP/HTTP logs endpoint, empty disables export (default "")
+//	--log-otlp-batch-size      int        Max records per OTLP export request (default 512)
+//	--log-otlp-flush-interval  duration   Max time a record waits before export (default 5s)
+//	--log-loki-url             string     Loki base URL, empty disables the Loki sink (default "")
+//	--log-loki-labels          string     Loki stream labels as key=value pairs (default "app=<appName>")
+//	--log-loki-batch-size      int        Max lines per Loki push (default 512)
+//	--log-loki-flush-interval  duration   Max time a line waits before push (default 5s)
+//	--log-dedup-interval       duration   Suppress identical consecutive entries, summarizing them at this interval, 0 disables (default 0s)
+//	--log-caller               bool       Include the calling file and line in every entry (default false)
+//	--log-caller-skip          int        Extra stack frames to skip when reporting the caller (default 0)
+//	--log-stacktrace-level     string     Minimum level at which a stack trace is attached, empty disables (default "")
+//	--log-metrics              bool       Count log entries by level and logger in Prometheus (default false)
+//	--log-metadata             bool       Attach hostname, PID, app name and version to every entry (default false)
+//	--log-k8s-metadata         bool       Attach node, pod and namespace from the downward API env vars to every entry (default false)
+//
+// A flag not given on the command line takes the value of its environment
+// variable if set (see EnvVarName and SetEnvPrefix), e.g. KUBENSAGE_LOG_LEVEL;
+// the precedence is flag > environment > default.
 //
 // Parameters:
 //   - fs       The flag set into which the flags will be registered.
 //   - appName  The name of the application (used in the default log file path).
+//   - opts     Options of the registrar (see WithDefaults and WithPrefix).
 //
 // Returns:
 //
 //	A closure that, when invoked, returns a populated *LogStdAndFileConfig
-//	containing the values from the parsed flags.
+//	containing the values from the parsed flags, checked by Validate, or
+//	an error joining every violation, also reported by ParseOrExit.
 func RegisterLogStdAndFileFlags(
 	fs *flag.FlagSet,
 	appName string,
-) func() *LogStdAndFileConfig {
-	logPath := "/var/log/kubensage/" + appName + ".log"
-
-	logLevel := fs.String("log-level", "info", "Set log level")
-	logFile := fs.String("log-file", logPath, "Path to log file")
-	logMaxSize := fs.Int("log-max-size", 10, "Maximum log size (MB)")
-	logMaxBackups := fs.Int("log-max-backups", 5, "Max backup files")
-	logMaxAge := fs.Int("log-max-age", 30, "Max age in days")
-	logCompress := fs.Bool("log-compress", true, "Compress logs")
-
-	return func() *LogStdAndFileConfig {
-		return &LogStdAndFileConfig{
-			LogLevel:      *logLevel,
-			LogFile:       *logFile,
-			LogMaxSize:    *logMaxSize,
-			LogMaxBackups: *logMaxBackups,
-			LogMaxAge:     *logMaxAge,
-			LogCompress:   *logCompress,
+	opts ...RegisterOption,
+) func() (*LogStdAndFileConfig, error) {
+	defer bindEnv(fs, flagNames(fs))
+	defer groupFlags(fs, GroupLogging, flagNames(fs))
+	defer applyOptions(fs, flagNames(fs), opts)
+
+	prefix := optionsPrefix(opts)
+
+	logLevel := logLevelVar(fs, prefix+"log-level", "info", "Set log `level`", false)
+	logLevelOverrides := fs.String(prefix+"log-level-overrides", "", "Per-subsystem log levels (name=level,...)")
+	logFile := fs.String(prefix+"log-file", DefaultLogFile(appName), "Path to log file")
+	logErrorFile := fs.String(prefix+"log-error-file", "", "Path to an additional warn+ log file (empty disables)")
+	logDirMode := fs.String(prefix+"log-dir-mode", "0755", "Octal permissions of created log directories")
+	logFileMode := fs.String(prefix+"log-file-mode", "0600", "Octal permissions of the log files")
+	logFileOwner := fs.String(prefix+"log-file-owner", "", "Owner of the log files as user[:group] (empty leaves it unchanged)")
+	logFileErrorPolicy := Enum(fs, prefix+"log-file-error-policy", []string{"drop", "block", "stdout"}, "drop", "On log file write errors")
+	logMaxSize := bytesVar(fs, prefix+"log-max-size", 10*MiB, MiB, "Maximum log file `size` before rotation (e.g., 100MiB; a bare number counts MiB)")
+	logMaxBackups := fs.Int(prefix+"log-max-backups", 5, "Max backup files")
+	logMaxAge := fs.Int(prefix+"log-max-age", 30, "Max age in days")
+	logCompress := fs.Bool(prefix+"log-compress", true, "Compress logs")
+	logEncoder := Enum(fs, prefix+"log-encoder", []string{"json", "console", "ecs"}, "json", "Log encoding")
+	logRotateOnSIGHUP := fs.Bool(prefix+"log-rotate-on-sighup", false, "Rotate log file on SIGHUP")
+	logRotateInterval := Duration(fs, prefix+"log-rotate-interval", 0, "Rotate the log file at every multiple of this `interval`, aligned on UTC (0 disables)", MinDuration(0))
+	logEncryptKeyFile := fs.String(prefix+"log-encrypt-key-file", "", "File holding the AES-256 key (hex or base64) encrypting rotated logs (empty disables)")
+	logEncryptKeyEnv := fs.String(prefix+"log-encrypt-key-env", "", "Environment variable holding the AES-256 key encrypting rotated logs (empty disables)")
+	logOutput := Enum(fs, prefix+"log-output", []string{"file", "journald"}, "file", "Log output")
+	logStdoutLevel := logLevelVar(fs, prefix+"log-stdout-level", "", "Set stdout log `level` (defaults to --log-level)", true)
+	logFileLevel := logLevelVar(fs, prefix+"log-file-level", "", "Set file log `level` (defaults to --log-level)", true)
+	logTimeFormat := TimeFormat(fs, prefix+"log-time-format", "iso8601", "Timestamp `format`")
+	logTimeUTC := fs.Bool(prefix+"log-time-utc", false, "Write timestamps in UTC instead of local time")
+	logTimeZone := TimeZone(fs, prefix+"log-time-zone", "", "Time `zone` of the timestamps, e.g. Europe/Rome (empty uses the local zone)")
+	logSplitStderr := fs.Bool(prefix+"log-split-stderr", false, "Send warn and above to stderr")
+	logSamplingInitial := fs.Int(prefix+"log-sampling-initial", 0, "Identical entries per second before sampling (0 disables)")
+	logSamplingThereafter := fs.Int(prefix+"log-sampling-thereafter", 100, "Log one entry out of every N when sampling")
+	logThrottleRate := fs.Int(prefix+"log-throttle-rate", 0, "Debug or info entries per second per level before dropping the excess (0 disables)")
+	logThrottleBurst := fs.Int(prefix+"log-throttle-burst", 0, "Burst size of the log throttle (0 uses the rate)")
+	logOTLPEndpoint := fs.String(prefix+"log-otlp-endpoint", "", "OTLP/HTTP logs endpoint (empty disables export)")
+	logOTLPBatchSize := fs.Int(prefix+"log-otlp-batch-size", 512, "Max records per OTLP export request")
+	logOTLPFlushInterval := Duration(fs, prefix+"log-otlp-flush-interval", 5*time.Second, "Max `time` a record waits before OTLP export", MinDuration(0))
+	logLokiURL := fs.String(prefix+"log-loki-url", "", "Loki base URL (empty disables the Loki sink)")
+	logLokiLabels := fs.String(prefix+"log-loki-labels", "app="+appName, "Loki stream labels (key=value,...)")
+	logLokiBatchSize := fs.Int(prefix+"log-loki-batch-size", 512, "Max lines per Loki push")
+	logLokiFlushInterval := Duration(fs, prefix+"log-loki-flush-interval", 5*time.Second, "Max `time` a line waits before Loki push", MinDuration(0))
+	logDedupInterval := Duration(fs, prefix+"log-dedup-interval", 0, "Suppress identical consecutive entries, summarizing them at this `interval` (0 disables)", MinDuration(0))
+	logCaller := fs.Bool(prefix+"log-caller", false, "Include the calling file and line in every entry")
+	logCallerSkip := fs.Int(prefix+"log-caller-skip", 0, "Extra stack frames to skip when reporting the caller")
+	logStacktraceLevel := logLevelVar(fs, prefix+"log-stacktrace-level", "", "Minimum `level` at which a stack trace is attached (empty disables)", true)
+	logMetrics := fs.Bool(prefix+"log-metrics", false, "Count log entries by level and logger in Prometheus")
+	logMetadata := fs.Bool(prefix+"log-metadata", false, "Attach hostname, PID, app name and version to every entry")
+	logK8sMetadata := fs.Bool(prefix+"log-k8s-metadata", false, "Attach node, pod and namespace from NODE_NAME, POD_NAME and POD_NAMESPACE to every entry")
+
+	load := checked(fs, func() (*LogStdAndFileConfig, error) {
+		cfg := &LogStdAndFileConfig{
+			LogLevel:              *logLevel,
+			LogLevelOverrides:     *logLevelOverrides,
+			LogFile:               *logFile,
+			LogErrorFile:          *logErrorFile,
+			LogDirMode:            *logDirMode,
+			LogFileMode:           *logFileMode,
+			LogFileOwner:          *logFileOwner,
+			LogFileErrorPolicy:    *logFileErrorPolicy,
+			LogMaxSize:            ceilMiB(*logMaxSize),
+			LogMaxBackups:         *logMaxBackups,
+			LogMaxAge:             *logMaxAge,
+			LogCompress:           *logCompress,
+			LogEncoder:            *logEncoder,
+			LogRotateOnSIGHUP:     *logRotateOnSIGHUP,
+			LogRotateInterval:     *logRotateInterval,
+			LogEncryptKeyFile:     *logEncryptKeyFile,
+			LogEncryptKeyEnv:      *logEncryptKeyEnv,
+			LogOutput:             *logOutput,
+			LogStdoutLevel:        *logStdoutLevel,
+			LogFileLevel:          *logFileLevel,
+			LogTimeFormat:         *logTimeFormat,
+			LogTimeUTC:            *logTimeUTC,
+			LogTimeZone:           *logTimeZone,
+			LogSplitStderr:        *logSplitStderr,
+			LogSamplingInitial:    *logSamplingInitial,
+			LogSamplingThereafter: *logSamplingThereafter,
+			LogThrottleRate:       *logThrottleRate,
+			LogThrottleBurst:      *logThrottleBurst,
+			LogOTLPEndpoint:       *logOTLPEndpoint,
+			LogOTLPBatchSize:      *logOTLPBatchSize,
+			LogOTLPFlushInterval:  *logOTLPFlushInterval,
+			LogLokiURL:            *logLokiURL,
+			LogLokiLabels:         *logLokiLabels,
+			LogLokiBatchSize:      *logLokiBatchSize,
+			LogLokiFlushInterval:  *logLokiFlushInterval,
+			LogDedupInterval:      *logDedupInterval,
+			LogCaller:             *logCaller,
+			LogCallerSkip:         *logCallerSkip,
+			LogStacktraceLevel:    *logStacktraceLevel,
+			LogMetrics:            *logMetrics,
+			LogMetadata:           *logMetadata,
+			LogK8sMetadata:        *logK8sMetadata,
+			AppName:               appName,
 		}
-	}
+		if err := validateConfig(cfg, prefix); err != nil {
+			return nil, err
+		}
+		// The dir-writable rule of log-file applies only when the file is
+		// written, so it is checked here rather than in the tag.
+		if cfg.LogOutput != "journald" {
+			if err := checkDirWritable(cfg.LogFile); err != nil {
+				return nil, fmt.Errorf("%slog-file: %w", prefix, err)
+			}
+		}
+		recordSources(fs, cfg, prefix)
+		return cfg, nil
+	})
+	AddPreflight(fs, prefix+"log files", func() error {
+		cfg, err := load()
+		if err != nil {
+			return err
+		}
+		if cfg.LogOutput == "journald" {
+			return preflightLogFile(cfg.LogErrorFile)
+		}
+		return errors.Join(preflightLogFile(cfg.LogFile), preflightLogFile(cfg.LogErrorFile))
+	})
+	return load
 }
 
 // RegisterLogStdFlags registers command-line flags for configuring logging
@@ -72,23 +322,313 @@ func RegisterLogStdAndFileFlags(
 //
 // Registered flags:
 //
-//	--log-level string   Log verbosity level (default "info")
+//	--log-level                string     Log verbosity level, one of ValidLogLevels, case-insensitive (default "info")
+//	--log-level-overrides      string     Per-subsystem levels as name=level pairs (default "")
+//	--log-encoder              string     Output encoding, "json", "console" or "ecs" (default "json")
+//	--log-dev                  bool       Use the development format: colored levels, short timestamps, caller (default false)
+//	--log-time-format          format     Timestamp format, one of ValidTimeFormats or a Go layout (default "iso8601")
+//	--log-time-utc             bool       Write timestamps in UTC instead of local time (default false)
+//	--log-time-zone            zone       Time zone of the timestamps, e.g. Europe/Rome, empty uses the local zone (default "")
+//	--log-split-stderr         bool       Send warn and above to stderr instead of stdout (default false)
+//	--log-sampling-initial     int        Identical entries per second before sampling, 0 disables (default 0)
+//	--log-sampling-thereafter  int        Log one entry out of every N once sampling kicks in (default 100)
+//	--log-throttle-rate        int        Debug or info entries per second per level before dropping the excess, 0 disables (default 0)
+//	--log-throttle-burst       int        Burst size of the throttle, 0 uses the rate (default 0)
+//	--log-dedup-interval       duration   Suppress identical consecutive entries, summarizing them at this interval, 0 disables (default 0s)
+//	--log-caller               bool       Include the calling file and line in every entry (default false)
+//	--log-caller-skip          int        Extra stack frames to skip when reporting the caller (default 0)
+//	--log-stacktrace-level     string     Minimum level at which a stack trace is attached, empty disables (default "")
+//	--log-metrics              bool       Count log entries by level and logger in Prometheus (default false)
+//	--log-metadata             bool       Attach hostname, PID, app name and version to every entry (default false)
+//	--log-k8s-metadata         bool       Attach node, pod and namespace from the downward API env vars to every entry (default false)
+//
+// A flag not given on the command line takes the value of its environment
+// variable if set (see EnvVarName and SetEnvPrefix), e.g. KUBENSAGE_LOG_LEVEL;
+// the precedence is flag > environment > default.
 //
 // Parameters:
-//   - fs  The flag set into which the flags will be registered.
+//   - fs    The flag set into which the flags will be registered.
+//   - opts  Options of the registrar (see WithDefaults and WithPrefix).
 //
 // Returns:
 //
 //	A closure that, when invoked, returns a populated *LogStdConfig
-//	containing the value from the parsed flags.
+//	containing the values from the parsed flags, checked by Validate, or
+//	an error joining every violation, also reported by ParseOrExit.
 func RegisterLogStdFlags(
 	fs *flag.FlagSet,
-) func() *LogStdConfig {
-	logLevel := fs.String("log-level", "info", "Set log level")
+	opts ...RegisterOption,
+) func() (*LogStdConfig, error) {
+	defer bindEnv(fs, flagNames(fs))
+	defer groupFlags(fs, GroupLogging, flagNames(fs))
+	defer applyOptions(fs, flagNames(fs), opts)
+
+	prefix := optionsPrefix(opts)
+
+	logLevel := logLevelVar(fs, prefix+"log-level", "info", "Set log `level`", false)
+	logLevelOverrides := fs.String(prefix+"log-level-overrides", "", "Per-subsystem log levels (name=level,...)")
+	logEncoder := Enum(fs, prefix+"log-encoder", []string{"json", "console", "ecs"}, "json", "Log encoding")
+	logDev := fs.Bool(prefix+"log-dev", false, "Use the development format (colored levels, short timestamps, caller)")
+	logTimeFormat := TimeFormat(fs, prefix+"log-time-format", "iso8601", "Timestamp `format`")
+	logTimeUTC := fs.Bool(prefix+"log-time-utc", false, "Write timestamps in UTC instead of local time")
+	logTimeZone := TimeZone(fs, prefix+"log-time-zone", "", "Time `zone` of the timestamps, e.g. Europe/Rome (empty uses the local zone)")
+	logSplitStderr := fs.Bool(prefix+"log-split-stderr", false, "Send warn and above to stderr")
+	logSamplingInitial := fs.Int(prefix+"log-sampling-initial", 0, "Identical entries per second before sampling (0 disables)")
+	logSamplingThereafter := fs.Int(prefix+"log-sampling-thereafter", 100, "Log one entry out of every N when sampling")
+	logThrottleRate := fs.Int(prefix+"log-throttle-rate", 0, "Debug or info entries per second per level before dropping the excess (0 disables)")
+	logThrottleBurst := fs.Int(prefix+"log-throttle-burst", 0, "Burst size of the log throttle (0 uses the rate)")
+	logDedupInterval := Duration(fs, prefix+"log-dedup-interval", 0, "Suppress identical consecutive entries, summarizing them at this `interval` (0 disables)", MinDuration(0))
+	logCaller := fs.Bool(prefix+"log-caller", false, "Include the calling file and line in every entry")
+	logCallerSkip := fs.Int(prefix+"log-caller-skip", 0, "Extra stack frames to skip when reporting the caller")
+	logStacktraceLevel := logLevelVar(fs, prefix+"log-stacktrace-level", "", "Minimum `level` at which a stack trace is attached (empty disables)", true)
+	logMetrics := fs.Bool(prefix+"log-metrics", false, "Count log entries by level and logger in Prometheus")
+	logMetadata := fs.Bool(prefix+"log-metadata", false, "Attach hostname, PID, app name and version to every entry")
+	logK8sMetadata := fs.Bool(prefix+"log-k8s-metadata", false, "Attach node, pod and namespace from NODE_NAME, POD_NAME and POD_NAMESPACE to every entry")
+
+	return checked(fs, func() (*LogStdConfig, error) {
+		cfg := &LogStdConfig{
+			LogLevel:              *logLevel,
+			LogLevelOverrides:     *logLevelOverrides,
+			LogEncoder:            *logEncoder,
+			LogDev:                *logDev,
+			LogTimeFormat:         *logTimeFormat,
+			LogTimeUTC:            *logTimeUTC,
+			LogTimeZone:           *logTimeZone,
+			LogSplitStderr:        *logSplitStderr,
+			LogSamplingInitial:    *logSamplingInitial,
+			LogSamplingThereafter: *logSamplingThereafter,
+			LogThrottleRate:       *logThrottleRate,
+			LogThrottleBurst:      *logThrottleBurst,
+			LogDedupInterval:      *logDedupInterval,
+			LogCaller:             *logCaller,
+			LogCallerSkip:         *logCallerSkip,
+			LogStacktraceLevel:    *logStacktraceLevel,
+			LogMetrics:            *logMetrics,
+			LogMetadata:           *logMetadata,
+			LogK8sMetadata:        *logK8sMetadata,
+		}
+		if err := validateConfig(cfg, prefix); err != nil {
+			return nil, err
+		}
+		recordSources(fs, cfg, prefix)
+		return cfg, nil
+	})
+}
+
+// RegisterLogSyslogFlags registers command-line flags for configuring logging
+// to syslog.
+//
+// Registered flags:
+//
+//	--log-level                   string     Log verbosity level, one of ValidLogLevels, case-insensitive (default "info")
+//	--log-level-overrides         string     Per-subsystem levels as name=level pairs (default "")
+//	--log-encoder                 string     Standard output encoding, "json", "console" or "ecs" (default "json")
+//	--log-syslog-network          string     "" for the local daemon, "udp" or "tcp" for a remote one, "tls" for TLS (RFC 5425) (default "")
+//	--log-syslog-address          string     Remote syslog address as host:port (default "")
+//	--log-syslog-tls-ca           string     PEM bundle of the CAs trusted for the server certificate, empty uses the system pool (default "")
+//	--log-syslog-tls-cert         string     PEM client certificate for mutual TLS (default "")
+//	--log-syslog-tls-key          string     PEM private key of the client certificate (default "")
+//	--log-syslog-tls-server-name  string     Name expected in the server certificate, empty uses the address host (default "")
+//	--log-syslog-tls-insecure     bool       Skip verification of the server certificate, for testing only (default false)
+//	--log-syslog-facility         string     Syslog facility (default "daemon")
+//	--log-syslog-tag              string     Syslog tag (default "<appName>")
+//	--log-dedup-interval          duration   Suppress identical consecutive entries, summarizing them at this interval, 0 disables (default 0s)
+//	--log-caller                  bool       Include the calling file and line in every entry (default false)
+//	--log-caller-skip             int        Extra stack frames to skip when reporting the caller (default 0)
+//	--log-stacktrace-level        string     Minimum level at which a stack trace is attached, empty disables (default "")
+//	--log-metrics                 bool       Count log entries by level and logger in Prometheus (default false)
+//	--log-metadata                bool       Attach hostname, PID, app name and version to every entry (default false)
+//	--log-k8s-metadata            bool       Attach node, pod and namespace from the downward API env vars to every entry (default false)
+//
+// A flag not given on the command line takes the value of its environment
+// variable if set (see EnvVarName and SetEnvPrefix), e.g. KUBENSAGE_LOG_SYSLOG_ADDRESS;
+// the precedence is flag > environment > default.
+//
+// Parameters:
+//   - fs       The flag set into which the flags will be registered.
+//   - appName  The name of the application (used as the default syslog tag).
+//   - opts     Options of the registrar (see WithDefaults and WithPrefix).
+//
+// Returns:
+//
+//	A closure that, when invoked, returns a populated *LogSyslogConfig
+//	containing the values from the parsed flags, checked by Validate, or
+//	an error joining every violation, also reported by ParseOrExit.
+func RegisterLogSyslogFlags(
+	fs *flag.FlagSet,
+	appName string,
+	opts ...RegisterOption,
+) func() (*LogSyslogConfig, error) {
+	defer bindEnv(fs, flagNames(fs))
+	defer groupFlags(fs, GroupLogging, flagNames(fs))
+	defer applyOptions(fs, flagNames(fs), opts)
+
+	prefix := optionsPrefix(opts)
+
+	logLevel := logLevelVar(fs, prefix+"log-level", "info", "Set log `level`", false)
+	logLevelOverrides := fs.String(prefix+"log-level-overrides", "", "Per-subsystem log levels (name=level,...)")
+	logEncoder := Enum(fs, prefix+"log-encoder", []string{"json", "console", "ecs"}, "json", "Log encoding")
+	syslogNetwork := Enum(fs, prefix+"log-syslog-network", []string{"", "udp", "tcp", "tls"}, "", "Syslog network of a remote daemon, empty for the local one")
+	syslogAddress := fs.String(prefix+"log-syslog-address", "", "Remote syslog address (host:port)")
+	syslogTLSCAFile := fs.String(prefix+"log-syslog-tls-ca", "", "PEM bundle of the CAs trusted for the syslog server certificate (empty uses the system pool)")
+	syslogTLSCertFile := fs.String(prefix+"log-syslog-tls-cert", "", "PEM client certificate for mutual TLS with the syslog server")
+	syslogTLSKeyFile := fs.String(prefix+"log-syslog-tls-key", "", "PEM private key of the syslog client certificate")
+	syslogTLSServerName := fs.String(prefix+"log-syslog-tls-server-name", "", "Name expected in the syslog server certificate (empty uses the address host)")
+	syslogTLSInsecure := fs.Bool(prefix+"log-syslog-tls-insecure", false, "Skip verification of the syslog server certificate (testing only)")
+	syslogFacility := fs.String(prefix+"log-syslog-facility", "daemon", "Syslog facility")
+	syslogTag := fs.String(prefix+"log-syslog-tag", appName, "Syslog tag")
+	logDedupInterval := Duration(fs, prefix+"log-dedup-interval", 0, "Suppress identical consecutive entries, summarizing them at this `interval` (0 disables)", MinDuration(0))
+	logCaller := fs.Bool(prefix+"log-caller", false, "Include the calling file and line in every entry")
+	logCallerSkip := fs.Int(prefix+"log-caller-skip", 0, "Extra stack frames to skip when reporting the caller")
+	logStacktraceLevel := logLevelVar(fs, prefix+"log-stacktrace-level", "", "Minimum `level` at which a stack trace is attached (empty disables)", true)
+	logMetrics := fs.Bool(prefix+"log-metrics", false, "Count log entries by level and logger in Prometheus")
+	logMetadata := fs.Bool(prefix+"log-metadata", false, "Attach hostname, PID, app name and version to every entry")
+	logK8sMetadata := fs.Bool(prefix+"log-k8s-metadata", false, "Attach node, pod and namespace from NODE_NAME, POD_NAME and POD_NAMESPACE to every entry")
+
+	return checked(fs, func() (*LogSyslogConfig, error) {
+		cfg := &LogSyslogConfig{
+			LogLevel:            *logLevel,
+			LogLevelOverrides:   *logLevelOverrides,
+			LogEncoder:          *logEncoder,
+			SyslogNetwork:       *syslogNetwork,
+			SyslogAddress:       *syslogAddress,
+			SyslogTLSCAFile:     *syslogTLSCAFile,
+			SyslogTLSCertFile:   *syslogTLSCertFile,
+			SyslogTLSKeyFile:    *syslogTLSKeyFile,
+			SyslogTLSServerName: *syslogTLSServerName,
+			SyslogTLSInsecure:   *syslogTLSInsecure,
+			SyslogFacility:      *syslogFacility,
+			SyslogTag:           *syslogTag,
+			LogDedupInterval:    *logDedupInterval,
+			LogCaller:           *logCaller,
+			LogCallerSkip:       *logCallerSkip,
+			LogStacktraceLevel:  *logStacktraceLevel,
+			LogMetrics:          *logMetrics,
+			LogMetadata:         *logMetadata,
+			LogK8sMetadata:      *logK8sMetadata,
+			AppName:             appName,
+		}
+		if err := validateConfig(cfg, prefix); err != nil {
+			return nil, err
+		}
+		recordSources(fs, cfg, prefix)
+		return cfg, nil
+	})
+}
+
+// RegisterLogElasticFlags registers command-line flags for shipping logs to
+// Elasticsearch.
+//
+// Registered flags:
+//
+//	--log-elastic-url             string     Elasticsearch base URL (default "")
+//	--log-elastic-index-prefix    string     Daily index prefix (default "kubensage-<appName>")
+//	--log-elastic-username        string     Basic auth user (default "")
+//	--log-elastic-password        string     Basic auth password: literal, @file or env:VAR (default "")
+//	--log-elastic-batch-size      int        Max documents per bulk request (default 512)
+//	--log-elastic-flush-interval  duration   Max time a document waits before being sent (default 5s)
+//
+// A flag not given on the command line takes the value of its environment
+// variable if set (see EnvVarName and SetEnvPrefix), e.g. KUBENSAGE_LOG_ELASTIC_URL;
+// the precedence is flag > environment > default.
+//
+// Parameters:
+//   - fs       The flag set into which the flags will be registered.
+//   - appName  The name of the application (used in the default index prefix).
+//   - opts     Options of the registrar (see WithDefaults and WithPrefix).
+//
+// Returns:
+//
+//	A closure that, when invoked, returns a populated *LogElasticConfig
+//	containing the values from the parsed flags, checked by Validate, or
+//	an error joining every violation, also reported by ParseOrExit.
+func RegisterLogElasticFlags(
+	fs *flag.FlagSet,
+	appName string,
+	opts ...RegisterOption,
+) func() (*LogElasticConfig, error) {
+	defer bindEnv(fs, flagNames(fs))
+	defer groupFlags(fs, GroupLogging, flagNames(fs))
+	defer applyOptions(fs, flagNames(fs), opts)
+
+	prefix := optionsPrefix(opts)
+
+	elasticURL := fs.String(prefix+"log-elastic-url", "", "Elasticsearch base URL")
+	elasticIndexPrefix := fs.String(prefix+"log-elastic-index-prefix", "kubensage-"+appName, "Elasticsearch daily index prefix")
+	elasticUsername := fs.String(prefix+"log-elastic-username", "", "Elasticsearch basic auth user")
+	elasticPassword := Secret(fs, prefix+"log-elastic-password", "Elasticsearch basic auth `password` (literal, @file or env:VAR)")
+	elasticBatchSize := fs.Int(prefix+"log-elastic-batch-size", 512, "Max documents per Elasticsearch bulk request")
+	elasticFlushInterval := Duration(fs, prefix+"log-elastic-flush-interval", 5*time.Second, "Max `time` a document waits before being sent", MinDuration(0))
+
+	return checked(fs, func() (*LogElasticConfig, error) {
+		cfg := &LogElasticConfig{
+			ElasticURL:           *elasticURL,
+			ElasticIndexPrefix:   *elasticIndexPrefix,
+			ElasticUsername:      *elasticUsername,
+			ElasticPassword:      *elasticPassword,
+			ElasticBatchSize:     *elasticBatchSize,
+			ElasticFlushInterval: *elasticFlushInterval,
+		}
+		if err := validateConfig(cfg, prefix); err != nil {
+			return nil, err
+		}
+		recordSources(fs, cfg, prefix)
+		return cfg, nil
+	})
+}
+
+// RegisterLogAuditFlags registers command-line flags for configuring the audit log.
+//
+// Registered flags:
+//
+//	--log-audit-file      string   Path to the audit log file (default DefaultAuditFile(appName))
+//	--log-audit-dir-mode  string   Octal permissions of the audit log directory if created (default "0700")
+//
+// A flag not given on the command line takes the value of its environment
+// variable if set (see EnvVarName and SetEnvPrefix), e.g. KUBENSAGE_LOG_AUDIT_FILE;
+// the precedence is flag > environment > default.
+//
+// Parameters:
+//   - fs       The flag set into which the flags will be registered.
+//   - appName  The name of the application (used in the default audit file path).
+//   - opts     Options of the registrar (see WithDefaults and WithPrefix).
+//
+// Returns:
+//
+//	A closure that, when invoked, returns a populated *LogAuditConfig
+//	containing the values from the parsed flags, checked by Validate, or
+//	an error joining every violation, also reported by ParseOrExit.
+func RegisterLogAuditFlags(
+	fs *flag.FlagSet,
+	appName string,
+	opts ...RegisterOption,
+) func() (*LogAuditConfig, error) {
+	defer bindEnv(fs, flagNames(fs))
+	defer groupFlags(fs, GroupLogging, flagNames(fs))
+	defer applyOptions(fs, flagNames(fs), opts)
 
-	return func() *LogStdConfig {
-		return &LogStdConfig{
-			LogLevel: *logLevel,
+	prefix := optionsPrefix(opts)
+
+	auditFile := fs.String(prefix+"log-audit-file", DefaultAuditFile(appName), "Path to the audit log file")
+	auditDirMode := fs.String(prefix+"log-audit-dir-mode", "0700", "Octal permissions of the created audit log directory")
+
+	load := checked(fs, func() (*LogAuditConfig, error) {
+		cfg := &LogAuditConfig{
+			AuditFile:    *auditFile,
+			AuditDirMode: *auditDirMode,
+		}
+		if err := validateConfig(cfg, prefix); err != nil {
+			return nil, err
+		}
+		recordSources(fs, cfg, prefix)
+		return cfg, nil
+	})
+	AddPreflight(fs, prefix+"audit log file", func() error {
+		cfg, err := load()
+		if err != nil {
+			return err
 		}
-	}
+		return preflightLogFile(cfg.AuditFile)
+	})
+	return load
 }
@@ -0,0 +1,178 @@
+package gocli
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Markers of the encrypted values of configuration files.
+const (
+	encryptedValuePrefix = "ENC[AES256_GCM,"
+	encryptedValueSuffix = "]"
+)
+
+// ParseConfigKey decodes the AES-256 key decrypting the encrypted values of
+// configuration files (see EncryptConfigValue): 32 bytes, hex-encoded (64
+// characters), base64-encoded, or raw. Surrounding whitespace is ignored.
+//
+// Parameters:
+//   - s  The encoded key (e.g., the output of "openssl rand -hex 32").
+//
+// Returns:
+//
+//	The key, or an error if it is not 32 bytes long once decoded.
+func ParseConfigKey(
+	s string,
+) ([]byte, error) {
+	if len(s) == 32 {
+		return []byte(s), nil
+	}
+	trimmed := strings.TrimSpace(s)
+	if key, err := hex.DecodeString(trimmed); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if key, err := enc.DecodeString(trimmed); err == nil && len(key) == 32 {
+			return key, nil
+		}
+	}
+	return nil, errors.New("invalid config key: expected 32 bytes, hex or base64 encoded")
+}
+
+// EncryptConfigValue encrypts a value of a configuration file, so that the file
+// can be committed to Git with its secrets (e.g., a Loki password) encrypted.
+// The result, in the style of sops, is written in place of the value:
+//
+//	log-loki-password: ENC[AES256_GCM,data:...,iv:...,tag:...,type:str]
+//
+// The value is encrypted with AES-256-GCM and bound to its key in the file, so
+// it cannot be moved to another key. RegisterConfigFileFlag and
+// LoadEncryptedConfigFile decrypt it when the file is loaded.
+//
+// Parameters:
+//   - key    The AES-256 key (see ParseConfigKey).
+//   - name   The key of the value in the file (e.g., "log-loki-password").
+//   - value  The value to encrypt.
+//
+// Returns:
+//
+//	The encrypted value, or an error if key is not 32 bytes long.
+func EncryptConfigValue(
+	key []byte,
+	name string,
+	value string,
+) (string, error) {
+	gcm, err := newConfigCipher(key)
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", fmt.Errorf("failed to generate IV: %w", err)
+	}
+
+	sealed := gcm.Seal(nil, iv, []byte(value), []byte(name))
+	data, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+	return fmt.Sprintf("%sdata:%s,iv:%s,tag:%s,type:str%s", encryptedValuePrefix,
+		base64.StdEncoding.EncodeToString(data),
+		base64.StdEncoding.EncodeToString(iv),
+		base64.StdEncoding.EncodeToString(tag),
+		encryptedValueSuffix,
+	), nil
+}
+
+// isEncryptedValue reports whether a value of a configuration file is
+// encrypted (see EncryptConfigValue).
+func isEncryptedValue(
+	value string,
+) bool {
+	value = strings.TrimSpace(value)
+	return strings.HasPrefix(value, encryptedValuePrefix) && strings.HasSuffix(value, encryptedValueSuffix)
+}
+
+// decryptConfigValue decrypts a value encrypted by EncryptConfigValue for the
+// key name.
+func decryptConfigValue(
+	key []byte,
+	name string,
+	value string,
+) (string, error) {
+	body := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(value), encryptedValuePrefix), encryptedValueSuffix)
+	parts := make(map[string][]byte)
+	for _, part := range strings.Split(body, ",") {
+		field, encoded, _ := strings.Cut(part, ":")
+		if field == "type" {
+			if encoded != "str" {
+				return "", fmt.Errorf("unsupported encrypted value type %q", encoded)
+			}
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return "", fmt.Errorf("malformed encrypted value: invalid %s", field)
+		}
+		parts[field] = decoded
+	}
+
+	gcm, err := newConfigCipher(key)
+	if err != nil {
+		return "", err
+	}
+	if len(parts["iv"]) != gcm.NonceSize() || len(parts["tag"]) != gcm.Overhead() {
+		return "", errors.New("malformed encrypted value: invalid iv or tag")
+	}
+	plain, err := gcm.Open(nil, parts["iv"], append(parts["data"], parts["tag"]...), []byte(name))
+	if err != nil {
+		return "", errors.New("wrong key, or value encrypted for another key")
+	}
+	return string(plain), nil
+}
+
+// newConfigCipher returns the AES-256-GCM cipher of a key.
+func newConfigCipher(
+	key []byte,
+) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("invalid config key: expected 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// decryptConfigEntries replaces the encrypted values of the entries of a
+// configuration file with their plaintext, and returns the entries that could
+// be decrypted with the errors of the others. A nil key decrypts nothing, and
+// reports every encrypted value.
+func decryptConfigEntries(
+	path string,
+	entries []configEntry,
+	key []byte,
+) ([]configEntry, []error) {
+	var errs []error
+	kept := entries[:0:0]
+	for _, entry := range entries {
+		if !entry.nested && isEncryptedValue(entry.value) {
+			if key == nil {
+				errs = append(errs, entry.errorf(path, "value of %q is encrypted but no decryption key is configured", entry.key))
+				continue
+			}
+			plain, err := decryptConfigValue(key, entry.key, entry.value)
+			if err != nil {
+				errs = append(errs, entry.errorf(path, "cannot decrypt %s: %v", entry.key, err))
+				continue
+			}
+			entry.value = plain
+		}
+		kept = append(kept, entry)
+	}
+	return kept, errs
+}
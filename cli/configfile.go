@@ -0,0 +1,580 @@
+package gocli
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"go.yaml.in/yaml/v3"
+)
+
+// configEntry is a top-level key of a configuration file with its value in
+// string form and the line it is defined on (0 if unknown).
+type configEntry struct {
+	key    string
+	value  string
+	line   int
+	nested bool // the value is a mapping or a list, which no key accepts
+}
+
+// LoadConfigFile reads a configuration file into a struct. The format is chosen
+// by the file extension: .yaml or .yml, .toml, or .json. The path may also be
+// an http:// or https:// URL (see RegisterConfigFileFlag).
+//
+// The file holds a flat mapping of keys to scalar values. Each key names a
+// field by its `log` tag, else its `flag` tag, else its `json` tag, else its Go
+// name, so the keys of the gocli configs are the flag names (e.g.,
+// "log-level: debug"). Durations are written as strings (e.g., "5s").
+//
+// A file written for an older release is migrated to the current format (see
+// RegisterConfigMigration and ConfigVersionKey).
+//
+// Unknown keys are errors. All the problems of the file are reported at once,
+// each prefixed with the file path and line (e.g., "agent.yaml:4: unknown key
+// \"log-levle\""). Encrypted values (see EncryptConfigValue) are errors too:
+// LoadEncryptedConfigFile decrypts them.
+//
+// Parameters:
+//   - path  The path of the configuration file.
+//   - into  A pointer to the struct receiving the values; keys absent from the
+//     file leave their field unchanged.
+//
+// Returns:
+//
+//	An error if the file cannot be read or parsed, or holds unknown keys or
+//	invalid values.
+func LoadConfigFile(
+	path string,
+	into any,
+) error {
+	return loadConfigFile(path, into, nil)
+}
+
+// LoadEncryptedConfigFile reads a configuration file into a struct like
+// LoadConfigFile, decrypting its encrypted values (see EncryptConfigValue).
+//
+// Parameters:
+//   - path  The path of the configuration file.
+//   - into  A pointer to the struct receiving the values.
+//   - key   The AES-256 key of the encrypted values (see ParseConfigKey).
+//
+// Returns:
+//
+//	An error if the file cannot be read or parsed, or holds unknown keys,
+//	invalid values, or values that cannot be decrypted with key.
+func LoadEncryptedConfigFile(
+	path string,
+	into any,
+	key []byte,
+) error {
+	if key == nil {
+		key = []byte{}
+	}
+	return loadConfigFile(path, into, key)
+}
+
+// loadConfigFile reads a configuration file into a struct, decrypting its
+// encrypted values with key unless it is nil.
+func loadConfigFile(
+	path string,
+	into any,
+	key []byte,
+) error {
+	target := reflect.ValueOf(into)
+	if target.Kind() != reflect.Pointer || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config file target must be a non-nil pointer to a struct, got %T", into)
+	}
+	target = target.Elem()
+
+	entries, err := readConfigFile(path)
+	if err != nil {
+		return err
+	}
+	name := configSourceName(path)
+	entries, errs := decryptConfigEntries(name, entries, key)
+	if entries, err = migrateConfigEntries(name, entries); err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+
+	fields := make(map[string]int)
+	for i := 0; i < target.NumField(); i++ {
+		field := target.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if name, ok := configKey(field); ok {
+			fields[name] = i
+		}
+	}
+
+	for _, entry := range entries {
+		i, ok := fields[entry.key]
+		if !ok {
+			errs = append(errs, entry.errorf(name, "unknown key %q", entry.key))
+			continue
+		}
+		if entry.nested {
+			errs = append(errs, entry.nestedError(name))
+			continue
+		}
+		if err := setConfigField(target.Field(i), entry.value); err != nil {
+			errs = append(errs, entry.errorf(name, "invalid value %q for %s: %v", entry.value, entry.key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RegisterConfigFileFlag registers the --config flag, naming a configuration
+// file whose values sit between the defaults and the command line: the
+// precedence is flag > environment > file > default.
+//
+// Registered flags:
+//
+//	--config                string    Path or http(s) URL of a YAML, TOML or JSON configuration file, empty disables it (default "")
+//	--config-key            string    AES-256 key (hex or base64) of the encrypted values of the file: literal, @file or env:VAR (default "")
+//	--config-poll-interval  duration  Interval at which Watcher.Poll reloads the file, 0 disables polling (default 0s)
+//
+// The file follows the format of LoadConfigFile, with the flag names of fs as
+// keys. It may be a file mounted from a ConfigMap, or served by an HTTP
+// endpoint: an http:// or https:// URL is fetched, its format being given by
+// the extension of its path or else by the Content-Type of the response, and
+// user information in the URL is sent as basic authentication. Polling the
+// source (see Watcher.Poll and ConfigPollInterval) applies fleet-wide changes
+// without redeploying. A file written for an older release is migrated to the
+// current format (see RegisterConfigMigration).
+//
+// Encrypted values of the file (see EncryptConfigValue) are decrypted with
+// the key of --config-key, so that the file can be committed to Git with its
+// secrets. A flag not given on the command line takes the value of its
+// environment variable if set (see EnvVarName), e.g. KUBENSAGE_CONFIG and
+// KUBENSAGE_CONFIG_KEY.
+//
+// Parameters:
+//   - fs  The flag set into which the flag will be registered.
+//
+// Returns:
+//
+//	A closure that, when invoked after fs.Parse, applies the file to the
+//	flags of fs neither set on the command line nor by their environment
+//	variable. It must run before the closures of the other registrars, and
+//	returns an error if the file is invalid, holds keys that are not flags
+//	of fs, or values that cannot be decrypted. It may be invoked again to
+//	re-read the file (see Watch): flags whose key was removed from the file
+//	get their previous value back. The flags are then set under a lock also
+//	taken by the closures of the registrars, so a reload from another
+//	goroutine does not race with them.
+//	ParseOrExit invokes it before its other checks (see AddCheck).
+//
+// Example:
+//
+//	loadConfig := gocli.RegisterConfigFileFlag(flag.CommandLine)
+//	logConfig := gocli.RegisterLogStdAndFileFlags(flag.CommandLine, "agent")
+//	flag.Parse()
+//	if err := loadConfig(); err != nil {
+//		log.Fatal(err)
+//	}
+//	cfg, err := logConfig()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func RegisterConfigFileFlag(
+	fs *flag.FlagSet,
+) func() error {
+	defer bindEnv(fs, flagNames(fs))
+	defer groupFlags(fs, GroupGeneral, flagNames(fs))
+
+	configFile := fs.String("config", "", "Path or http(s) URL of a YAML, TOML or JSON configuration file (empty disables)")
+	Duration(fs, "config-poll-interval", 0, "Reload the configuration file at this `interval` when watched (0 disables)", MinDuration(0))
+	configKey := Secret(fs, "config-key", "AES-256 `key` (hex or base64) decrypting the ENC[...] values of the configuration file (literal, @file or env:VAR)")
+
+	// Values of the flags set by a previous call, before the file applied to
+	// them: the flags remain open to the file, and are reset when it changes.
+	fromFile := make(map[string]string)
+
+	load := func() error {
+		mu := valuesLock(fs)
+		mu.RLock()
+		source, keyValue := *configFile, configKey.Value()
+		mu.RUnlock()
+
+		if source == "" {
+			return nil
+		}
+		entries, err := readConfigFile(source)
+		if err != nil {
+			return err
+		}
+		var key []byte
+		if keyValue != "" {
+			if key, err = ParseConfigKey(keyValue); err != nil {
+				return err
+			}
+		}
+		name := configSourceName(source)
+		entries, errs := decryptConfigEntries(name, entries, key)
+		if entries, err = migrateConfigEntries(name, entries); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+
+		// The flags are set under the write lock, while a Watcher may reload
+		// them concurrently with the closures of the registrars.
+		mu.Lock()
+		defer mu.Unlock()
+		set := make(map[string]struct{})
+		fs.Visit(func(f *flag.Flag) {
+			if _, ok := fromFile[f.Name]; !ok {
+				set[f.Name] = struct{}{}
+			}
+		})
+		for name, value := range fromFile {
+			resetFlag(fs.Lookup(name), value)
+			forgetOrigin(fs, name)
+		}
+
+		for _, entry := range entries {
+			f := fs.Lookup(entry.key)
+			if f == nil {
+				errs = append(errs, entry.errorf(name, "unknown key %q", entry.key))
+				continue
+			}
+			if entry.nested {
+				errs = append(errs, entry.nestedError(name))
+				continue
+			}
+			if _, ok := set[f.Name]; ok {
+				continue // set on the command line or by its environment variable
+			}
+			previous := f.Value.String()
+			if err := fs.Set(f.Name, entry.value); err != nil {
+				resetFlag(f, previous)
+				errs = append(errs, entry.errorf(name, "invalid value %q for %s: %v", entry.value, entry.key, err))
+				continue
+			}
+			if _, ok := fromFile[f.Name]; !ok {
+				fromFile[f.Name] = previous
+			}
+			recordOrigin(fs, f, SourceFile)
+		}
+		return errors.Join(errs...)
+	}
+	prependCheck(fs, load)
+	return load
+}
+
+// resetFlag sets a flag back to a value it held, which the next value given
+// replaces (see defaultBinder).
+func resetFlag(
+	f *flag.Flag,
+	value string,
+) {
+	binder, ok := f.Value.(defaultBinder)
+	if ok {
+		binder.bindDefault()
+	}
+	_ = f.Value.Set(value)
+	if ok {
+		binder.bindDefault()
+	}
+}
+
+// errorf returns an error located at the entry line of the file.
+func (e configEntry) errorf(
+	path string,
+	format string,
+	args ...any,
+) error {
+	if e.line > 0 {
+		return fmt.Errorf("%s:%d: %s", path, e.line, fmt.Sprintf(format, args...))
+	}
+	return fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...))
+}
+
+// nestedError returns the error reported for a nested value.
+func (e configEntry) nestedError(
+	path string,
+) error {
+	return e.errorf(path, "value of %q must be a scalar", e.key)
+}
+
+// readConfigFile reads and parses a configuration file, or the configuration
+// served at an http:// or https:// URL, according to its extension.
+func readConfigFile(
+	path string,
+) ([]configEntry, error) {
+	var data []byte
+	var err error
+	ext := strings.ToLower(filepath.Ext(path))
+	if isConfigURL(path) {
+		data, ext, err = fetchConfig(path)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	name := configSourceName(path)
+	switch ext {
+	case ".yaml", ".yml":
+		return parseYAMLConfig(name, data)
+	case ".toml":
+		return parseTOMLConfig(name, data)
+	case ".json":
+		return parseJSONConfig(name, data)
+	default:
+		return nil, fmt.Errorf("unsupported config file extension %q (supported: .yaml, .yml, .toml, .json)", ext)
+	}
+}
+
+// parseYAMLConfig returns the top-level keys of a YAML document.
+func parseYAMLConfig(
+	path string,
+	data []byte,
+) ([]configEntry, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s:%d: expected a mapping of keys to values", path, root.Line)
+	}
+
+	entries := make([]configEntry, 0, len(root.Content)/2)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		entry := configEntry{key: key.Value, line: key.Line}
+		switch {
+		case value.Kind != yaml.ScalarNode:
+			entry.nested = true
+		case value.Tag != "!!null":
+			entry.value = value.Value
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// parseTOMLConfig returns the top-level keys of a TOML document. Since the
+// decoder does not expose key positions, lines are found by scanning the
+// document.
+func parseTOMLConfig(
+	path string,
+	data []byte,
+) ([]configEntry, error) {
+	var values map[string]any
+	md, err := toml.Decode(string(data), &values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	entries := make([]configEntry, 0, len(values))
+	for _, key := range md.Keys() {
+		if len(key) != 1 {
+			continue
+		}
+		entry := configEntry{key: key[0], line: tomlKeyLine(data, key[0])}
+		switch v := values[key[0]].(type) {
+		case map[string]any, []any, []map[string]any:
+			entry.nested = true
+		case string:
+			entry.value = v
+		case time.Time:
+			entry.value = v.Format(time.RFC3339Nano)
+		default:
+			entry.value = fmt.Sprint(v)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// tomlKeyLine returns the line of the first top-level assignment of key in a
+// TOML document, or 0 if it cannot be found.
+func tomlKeyLine(
+	data []byte,
+	key string,
+) int {
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			return 0 // tables start after the top-level keys
+		}
+		for _, quoted := range []string{key, `"` + key + `"`, "'" + key + "'"} {
+			if rest, ok := strings.CutPrefix(line, quoted); ok && strings.HasPrefix(strings.TrimSpace(rest), "=") {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
+// parseJSONConfig returns the keys of a JSON object.
+func parseJSONConfig(
+	path string,
+	data []byte,
+) ([]configEntry, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	syntaxError := func(err error) error {
+		offset := dec.InputOffset()
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			offset = syntaxErr.Offset
+		}
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return fmt.Errorf("%s:%d: failed to parse config file: %w", path, lineAt(data, offset), err)
+	}
+
+	if tok, err := dec.Token(); err != nil {
+		return nil, syntaxError(err)
+	} else if tok != json.Delim('{') {
+		return nil, fmt.Errorf("%s:%d: expected an object of keys to values", path, lineAt(data, dec.InputOffset()))
+	}
+
+	var entries []configEntry
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, syntaxError(err)
+		}
+		entry := configEntry{key: tok.(string), line: lineAt(data, dec.InputOffset())}
+
+		tok, err = dec.Token()
+		if err != nil {
+			return nil, syntaxError(err)
+		}
+		switch v := tok.(type) {
+		case json.Delim:
+			// Skip the nested object or array to report every problem of the file.
+			for depth := 1; depth > 0; {
+				tok, err := dec.Token()
+				if err != nil {
+					return nil, syntaxError(err)
+				}
+				switch tok {
+				case json.Delim('{'), json.Delim('['):
+					depth++
+				case json.Delim('}'), json.Delim(']'):
+					depth--
+				}
+			}
+			entry.nested = true
+		case string:
+			entry.value = v
+		case nil:
+		default:
+			entry.value = fmt.Sprint(v)
+		}
+		entries = append(entries, entry)
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, syntaxError(err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("%s:%d: unexpected data after the configuration object", path, lineAt(data, dec.InputOffset()))
+	}
+	return entries, nil
+}
+
+// lineAt returns the 1-based line of a byte offset of data.
+func lineAt(
+	data []byte,
+	offset int64,
+) int {
+	offset = min(max(offset, 0), int64(len(data)))
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+// configKey returns the configuration file key of a struct field, following the
+// naming of the startup configuration logs: the `log` tag, else the `flag` tag
+// (see RegisterFlagsFromStruct), else the `json` tag, else the Go field name.
+// It returns false for fields excluded with a "-" tag.
+func configKey(
+	field reflect.StructField,
+) (string, bool) {
+	for _, key := range []string{"log", "flag", "json"} {
+		tag, ok := field.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			return "", false
+		}
+		if name != "" {
+			return name, true
+		}
+	}
+	return field.Name, true
+}
+
+// setConfigField parses a value into a field of a basic kind, or of a type
+// implementing encoding.TextUnmarshaler (e.g., SecretString).
+func setConfigField(
+	field reflect.Value,
+	value string,
+) error {
+	if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(value))
+	}
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 0, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 0, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
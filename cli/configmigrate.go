@@ -0,0 +1,272 @@
+package gocli
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// ConfigVersionKey is the key of a configuration file holding the version of
+// its format (e.g., "config-version: 2"), from which the migrations registered
+// with RegisterConfigMigration are applied. A file without it is at version 1.
+const ConfigVersionKey = "config-version"
+
+// ConfigMigration is a migration applied to a configuration file when it was
+// last loaded.
+type ConfigMigration struct {
+	Source      string // Path or URL of the file, without the user information of the URL
+	From        int    // Version of the file before the migration
+	To          int    // Version of the file after the migration
+	Description string // What the migration changes (e.g., "rename log-format to log-encoder")
+}
+
+// configMigration is a migration registered with RegisterConfigMigration.
+type configMigration struct {
+	description string
+	migrate     func(m *ConfigMigrator) error
+}
+
+var (
+	migrationsMu      sync.RWMutex
+	migrations        = make(map[int]configMigration)      // migrations by version migrated from
+	appliedMigrations = make(map[string][]ConfigMigration) // migrations applied by the last load, by source
+)
+
+// ConfigMigrator edits the values of a configuration file being migrated from
+// one version to the next (see RegisterConfigMigration). Keys are those of the
+// file, i.e., flag names (e.g., "log-level").
+type ConfigMigrator struct {
+	entries []configEntry
+}
+
+// RegisterConfigMigration registers the migration of the configuration files
+// from a version to the next, so that files written for an older release keep
+// working as flags get renamed or change format. It is typically called from
+// an init function, once per version bump.
+//
+// A file is at the version given by its ConfigVersionKey key, or 1 without
+// it, and the current version is the one following the last migration. When
+// a file is loaded (see RegisterConfigFileFlag and LoadConfigFile), the
+// migrations from its version onwards are applied in order, and recorded for
+// AppliedConfigMigrations; golog.LogStartupInfo logs them, so that operators
+// update their files. A file of a newer version than the current one is
+// rejected.
+//
+// It panics if from is lower than 1 or a migration from that version is
+// already registered, as these are programming errors.
+//
+// Parameters:
+//   - from         The version migrated from; the file is at from+1 afterwards.
+//   - description  What the migration changes, for the logs.
+//   - migrate      Edits the values of the file; an error fails the loading.
+//
+// Example:
+//
+//	func init() {
+//		gocli.RegisterConfigMigration(1, "rename relay-addr to relay-address", func(m *gocli.ConfigMigrator) error {
+//			m.Rename("relay-addr", "relay-address")
+//			return nil
+//		})
+//	}
+func RegisterConfigMigration(
+	from int,
+	description string,
+	migrate func(m *ConfigMigrator) error,
+) {
+	if from < 1 {
+		panic(fmt.Sprintf("gocli: invalid configuration migration from version %d", from))
+	}
+
+	migrationsMu.Lock()
+	defer migrationsMu.Unlock()
+	if _, ok := migrations[from]; ok {
+		panic(fmt.Sprintf("gocli: configuration migration from version %d registered twice", from))
+	}
+	migrations[from] = configMigration{description: description, migrate: migrate}
+}
+
+// ConfigVersion returns the current version of the configuration files: the
+// one following the last migration registered with RegisterConfigMigration,
+// or 1 without migrations.
+//
+// Returns:
+//
+//	The current version.
+func ConfigVersion() int {
+	migrationsMu.RLock()
+	defer migrationsMu.RUnlock()
+
+	version := 1
+	for from := range migrations {
+		version = max(version, from+1)
+	}
+	return version
+}
+
+// AppliedConfigMigrations returns the migrations applied to the configuration
+// files when they were last loaded (see RegisterConfigMigration).
+//
+// Returns:
+//
+//	The migrations by source, then in the order they were applied, or nil
+//	if every file loaded was at the current version.
+func AppliedConfigMigrations() []ConfigMigration {
+	migrationsMu.RLock()
+	defer migrationsMu.RUnlock()
+
+	sources := make([]string, 0, len(appliedMigrations))
+	for source := range appliedMigrations {
+		sources = append(sources, source)
+	}
+	slices.Sort(sources)
+
+	var applied []ConfigMigration
+	for _, source := range sources {
+		applied = append(applied, appliedMigrations[source]...)
+	}
+	return applied
+}
+
+// Get returns the value of a key.
+//
+// Parameters:
+//   - key  The key (e.g., "log-format").
+//
+// Returns:
+//
+//	The value, and whether the file sets the key to a scalar.
+func (m *ConfigMigrator) Get(
+	key string,
+) (string, bool) {
+	for i := len(m.entries) - 1; i >= 0; i-- {
+		if m.entries[i].key == key && !m.entries[i].nested {
+			return m.entries[i].value, true
+		}
+	}
+	return "", false
+}
+
+// Set sets the value of a key, adding the key if the file does not set it.
+//
+// Parameters:
+//   - key    The key.
+//   - value  The value, written as on the command line (e.g., "30s").
+func (m *ConfigMigrator) Set(
+	key string,
+	value string,
+) {
+	found := false
+	for i := range m.entries {
+		if m.entries[i].key == key {
+			m.entries[i].value, m.entries[i].nested = value, false
+			found = true
+		}
+	}
+	if !found {
+		m.entries = append(m.entries, configEntry{key: key, value: value})
+	}
+}
+
+// Rename renames a key, e.g., after its flag was renamed. If the file also
+// sets the new key, the old one is dropped.
+//
+// Parameters:
+//   - old  The former key.
+//   - new  The new key.
+func (m *ConfigMigrator) Rename(
+	old string,
+	new string,
+) {
+	if _, ok := m.Get(new); ok {
+		m.Delete(old)
+		return
+	}
+	for i := range m.entries {
+		if m.entries[i].key == old {
+			m.entries[i].key = new
+		}
+	}
+}
+
+// Delete removes a key, e.g., after its flag was removed.
+//
+// Parameters:
+//   - key  The key.
+func (m *ConfigMigrator) Delete(
+	key string,
+) {
+	m.entries = slices.DeleteFunc(m.entries, func(e configEntry) bool {
+		return e.key == key
+	})
+}
+
+// Keys returns the keys set by the file, in the order they are written.
+//
+// Returns:
+//
+//	The keys.
+func (m *ConfigMigrator) Keys() []string {
+	keys := make([]string, 0, len(m.entries))
+	for _, e := range m.entries {
+		if !slices.Contains(keys, e.key) {
+			keys = append(keys, e.key)
+		}
+	}
+	return keys
+}
+
+// migrateConfigEntries removes the ConfigVersionKey key from the entries of a
+// configuration file, and applies the registered migrations from its version
+// to the current one, recording them for AppliedConfigMigrations under path,
+// the name of the file in messages (see configSourceName).
+func migrateConfigEntries(
+	path string,
+	entries []configEntry,
+) ([]configEntry, error) {
+	version := 1
+	kept := entries[:0:0]
+	for _, entry := range entries {
+		if entry.key != ConfigVersionKey {
+			kept = append(kept, entry)
+			continue
+		}
+		if entry.nested {
+			return nil, entry.nestedError(path)
+		}
+		v, err := strconv.Atoi(entry.value)
+		if err != nil || v < 1 {
+			return nil, entry.errorf(path, "invalid %s %q: expected a positive integer", ConfigVersionKey, entry.value)
+		}
+		version = v
+	}
+
+	current := ConfigVersion()
+	if version > current {
+		return nil, fmt.Errorf("%s: configuration version %d is newer than the supported version %d", path, version, current)
+	}
+
+	m := &ConfigMigrator{entries: kept}
+	var applied []ConfigMigration
+	for from := version; from < current; from++ {
+		migrationsMu.RLock()
+		migration, ok := migrations[from]
+		migrationsMu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("%s: no configuration migration from version %d", path, from)
+		}
+		if err := migration.migrate(m); err != nil {
+			return nil, fmt.Errorf("%s: failed to migrate configuration from version %d to %d (%s): %w", path, from, from+1, migration.description, err)
+		}
+		applied = append(applied, ConfigMigration{Source: path, From: from, To: from + 1, Description: migration.description})
+	}
+
+	migrationsMu.Lock()
+	if len(applied) > 0 {
+		appliedMigrations[path] = applied
+	} else {
+		delete(appliedMigrations, path)
+	}
+	migrationsMu.Unlock()
+	return m.entries, nil
+}
@@ -0,0 +1,142 @@
+package gocli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// RegisterOption customizes the flags defined by a registrar (e.g.,
+// RegisterLogStdAndFileFlags).
+type RegisterOption func(*registerOptions)
+
+// registerOptions holds the settings of the RegisterOption values.
+type registerOptions struct {
+	defaults Defaults
+	aliases  Aliases
+	prefix   string // prefix of the flag names, ending with a dash unless empty
+}
+
+// Defaults maps flag names (e.g., "log-max-size") to default values, written as
+// on the command line (e.g., "50", "24h", "false"). With WithPrefix, names may
+// be given with or without the prefix.
+type Defaults map[string]string
+
+// WithDefaults replaces default values of the flags of a registrar, so that an
+// embedding application can adapt them (e.g., its log file path or rotation
+// size) without re-implementing the registrar. The new defaults are shown in
+// the help, and environment variables, the configuration file and the command
+// line still override them.
+//
+// The registrar panics if a name is not one of its flags or a value is invalid
+// for its flag, as these are programming errors.
+//
+// Parameters:
+//   - defaults  The default values by flag name.
+//
+// Example:
+//
+//	logConfig := gocli.RegisterLogStdAndFileFlags(fs, "agent", gocli.WithDefaults(gocli.Defaults{
+//		"log-file":     "/data/agent/agent.log",
+//		"log-max-size": "50",
+//		"log-compress": "false",
+//	}))
+func WithDefaults(
+	defaults Defaults,
+) RegisterOption {
+	return func(o *registerOptions) {
+		if o.defaults == nil {
+			o.defaults = make(Defaults, len(defaults))
+		}
+		for name, value := range defaults {
+			o.defaults[name] = value
+		}
+	}
+}
+
+// WithPrefix prefixes the names of the flags of a registrar with prefix and a
+// dash, so that a registrar can be called several times on the same flag set,
+// e.g., by a relay holding two gRPC connections (--upstream-grpc-target and
+// --downstream-grpc-target). The environment variables follow the flag names
+// (e.g., KUBENSAGE_UPSTREAM_GRPC_TARGET), as do the keys of the configuration
+// file and the violations reported by the registrar closure. The configuration
+// structs are unchanged.
+//
+// Parameters:
+//   - prefix  The prefix of the flag names (e.g., "upstream"); prefixes given
+//     by several WithPrefix options are joined in order.
+//
+// Example:
+//
+//	upstreamConfig := gocli.RegisterGrpcClientFlags(fs, "upstream:50051", gocli.WithPrefix("upstream"))
+//	downstreamConfig := gocli.RegisterGrpcClientFlags(fs, "downstream:50051", gocli.WithPrefix("downstream"))
+func WithPrefix(
+	prefix string,
+) RegisterOption {
+	return func(o *registerOptions) {
+		if prefix = strings.TrimSuffix(prefix, "-"); prefix != "" {
+			o.prefix += prefix + "-"
+		}
+	}
+}
+
+// newRegisterOptions returns the settings of the options of a registrar.
+func newRegisterOptions(
+	opts []RegisterOption,
+) registerOptions {
+	var o registerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// optionsPrefix returns the prefix of the flag names of a registrar (see
+// WithPrefix), ending with a dash unless empty.
+func optionsPrefix(
+	opts []RegisterOption,
+) string {
+	return newRegisterOptions(opts).prefix
+}
+
+// applyOptions applies the options of a registrar to the flags of fs not in
+// known. Registrars call it deferred, after bindEnv and groupFlags so that it
+// runs before them, with the flags known before their registration:
+//
+//	defer applyOptions(fs, flagNames(fs), opts)
+func applyOptions(
+	fs *flag.FlagSet,
+	known map[string]struct{},
+	opts []RegisterOption,
+) {
+	o := newRegisterOptions(opts)
+	for name, value := range o.defaults {
+		if o.prefix != "" && !strings.HasPrefix(name, o.prefix) {
+			name = o.prefix + name
+		}
+		f := fs.Lookup(name)
+		if _, ok := known[name]; ok || f == nil {
+			panic(fmt.Sprintf("gocli: cannot set the default of -%s: not a flag of the registrar", name))
+		}
+		if err := setDefault(f, value); err != nil {
+			panic(fmt.Sprintf("gocli: invalid default %q for -%s: %v", value, name, err))
+		}
+	}
+	applyAliases(fs, known, o)
+}
+
+// setDefault sets a flag to a default value, shown in the help and replaced,
+// not appended to, by the first value given for the flag.
+func setDefault(
+	f *flag.Flag,
+	value string,
+) error {
+	if err := f.Value.Set(value); err != nil {
+		return err
+	}
+	f.DefValue = f.Value.String()
+	if binder, ok := f.Value.(defaultBinder); ok {
+		binder.bindDefault()
+	}
+	return nil
+}
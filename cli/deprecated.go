@@ -0,0 +1,167 @@
+package gocli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+)
+
+var (
+	hiddenMu sync.RWMutex
+	hidden   = make(map[*flag.FlagSet]map[string]struct{}) // flags excluded from PrintDefaults, by flag set
+)
+
+// Hide excludes flags from the usage output (see PrintDefaults) while keeping
+// them functional, e.g., for debugging switches not meant for operators.
+//
+// The usage of fs is replaced by one printing the flags with PrintDefaults,
+// which honors hidden flags unlike fs.PrintDefaults.
+//
+// Parameters:
+//   - fs     The flag set holding the flags.
+//   - names  The flag names; it panics if one is not defined in fs.
+func Hide(
+	fs *flag.FlagSet,
+	names ...string,
+) {
+	for _, name := range names {
+		if fs.Lookup(name) == nil {
+			panic(fmt.Sprintf("gocli: cannot hide undefined flag -%s", name))
+		}
+	}
+
+	hiddenMu.Lock()
+	if hidden[fs] == nil {
+		hidden[fs] = make(map[string]struct{})
+	}
+	for _, name := range names {
+		hidden[fs][name] = struct{}{}
+	}
+	hiddenMu.Unlock()
+
+	installUsage(fs)
+}
+
+// Deprecate marks a flag deprecated: it is hidden (see Hide), and a warning
+// naming its replacement is written to the output of fs every time it is set,
+// from the command line, its environment variable or the configuration file.
+//
+// If name is not defined in fs but replacement is, name is defined as an alias
+// of replacement, so that a registrar can rename a flag without breaking the
+// deployments using the old name: values given to the old flag, or to its
+// environment variable when the new flag is not set, go to the new one.
+//
+// Parameters:
+//   - fs           The flag set holding the flags.
+//   - name         The deprecated flag name.
+//   - replacement  The flag to use instead, or the empty string if the flag has
+//     no replacement; it panics if neither name nor replacement is defined.
+//
+// Example:
+//
+//	logConfig := gocli.RegisterLogStdFlags(fs)
+//	gocli.Deprecate(fs, "log-format", "log-encoder") // renamed in 1.3
+func Deprecate(
+	fs *flag.FlagSet,
+	name string,
+	replacement string,
+) {
+	if f := fs.Lookup(name); f != nil {
+		set := false
+		fs.Visit(func(v *flag.Flag) {
+			set = set || v.Name == name
+		})
+		d := &deprecatedFlag{Value: f.Value, fs: fs, name: name, replacement: replacement}
+		f.Value = d
+		if set {
+			d.warn() // already set by its environment variable
+		}
+		Hide(fs, name)
+		return
+	}
+
+	target := fs.Lookup(replacement)
+	if target == nil {
+		panic(fmt.Sprintf("gocli: cannot deprecate undefined flag -%s", name))
+	}
+	d := &deprecatedFlag{
+		Value:       &forwardFlag{fs: fs, target: target},
+		fs:          fs,
+		name:        name,
+		replacement: replacement,
+	}
+	fs.Var(d, name, fmt.Sprintf("Deprecated: use --%s", replacement))
+	Hide(fs, name)
+
+	set := false
+	fs.Visit(func(v *flag.Flag) {
+		set = set || v.Name == replacement
+	})
+	if envName := EnvVarName(name); envName != "" && !set {
+		if value, ok := os.LookupEnv(envName); ok {
+			if err := fs.Set(name, value); err != nil {
+				_, _ = fmt.Fprintf(fs.Output(), "invalid value %q for environment variable %s (flag -%s): %v\n", value, envName, name, err)
+			} else {
+				recordOrigin(fs, target, SourceEnv)
+			}
+		}
+	}
+}
+
+// isHidden reports whether a flag of fs is hidden.
+func isHidden(
+	fs *flag.FlagSet,
+	name string,
+) bool {
+	hiddenMu.RLock()
+	defer hiddenMu.RUnlock()
+	_, ok := hidden[fs][name]
+	return ok
+}
+
+// deprecatedFlag is a flag.Value warning that its flag is deprecated when set.
+type deprecatedFlag struct {
+	flag.Value
+	fs          *flag.FlagSet
+	name        string
+	replacement string
+}
+
+// Set warns that the flag is deprecated and sets it.
+func (d *deprecatedFlag) Set(
+	s string,
+) error {
+	d.warn()
+	return d.Value.Set(s)
+}
+
+// String returns the value of the wrapped flag.
+func (d *deprecatedFlag) String() string {
+	if d.Value == nil {
+		return ""
+	}
+	return d.Value.String()
+}
+
+// IsBoolFlag reports whether the wrapped flag may be given without a value.
+func (d *deprecatedFlag) IsBoolFlag() bool {
+	b, ok := d.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// bindDefault forwards to the wrapped flag (see defaultBinder).
+func (d *deprecatedFlag) bindDefault() {
+	if binder, ok := d.Value.(defaultBinder); ok {
+		binder.bindDefault()
+	}
+}
+
+// warn writes the deprecation warning to the output of the flag set.
+func (d *deprecatedFlag) warn() {
+	if d.replacement == "" {
+		_, _ = fmt.Fprintf(d.fs.Output(), "warning: flag --%s is deprecated\n", d.name)
+		return
+	}
+	_, _ = fmt.Fprintf(d.fs.Output(), "warning: flag --%s is deprecated, use --%s instead\n", d.name, d.replacement)
+}
@@ -0,0 +1,126 @@
+package gocli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DurationOption constrains the values accepted by a Duration flag.
+type DurationOption func(*durationFlag)
+
+// MinDuration rejects durations shorter than d.
+//
+// Parameters:
+//   - d  The shortest accepted duration.
+func MinDuration(
+	d time.Duration,
+) DurationOption {
+	return func(f *durationFlag) {
+		f.min = &d
+	}
+}
+
+// MaxDuration rejects durations longer than d.
+//
+// Parameters:
+//   - d  The longest accepted duration.
+func MaxDuration(
+	d time.Duration,
+) DurationOption {
+	return func(f *durationFlag) {
+		f.max = &d
+	}
+}
+
+// durationFlag is a flag.Value holding a time.Duration within optional bounds,
+// printed in a human-readable form (e.g., "24h" rather than "24h0m0s").
+type durationFlag struct {
+	value *time.Duration
+	min   *time.Duration
+	max   *time.Duration
+}
+
+// Duration defines a time.Duration flag, like flag.Duration, with the behavior
+// of the gocli registrars: it falls back to its environment variable (see
+// EnvVarName) when not given on the command line, it rejects values outside
+// the bounds set by MinDuration and MaxDuration when the command line is
+// parsed, and its default is shown in the help in a human-readable form (e.g.,
+// "(default 24h)"). As with the flag package, a name in back quotes in usage
+// is shown as the value placeholder (e.g., "Scrape `interval`").
+//
+// Parameters:
+//   - fs     The flag set into which the flag is registered.
+//   - name   The flag name.
+//   - value  The default value; it is not checked against the bounds.
+//   - usage  The flag help.
+//   - opts   Bounds of the accepted values.
+//
+// Returns:
+//
+//	A pointer to the flag value.
+//
+// Example:
+//
+//	interval := gocli.Duration(fs, "metrics-interval", 15*time.Second,
+//		"Metrics scrape `interval`", gocli.MinDuration(time.Second))
+func Duration(
+	fs *flag.FlagSet,
+	name string,
+	value time.Duration,
+	usage string,
+	opts ...DurationOption,
+) *time.Duration {
+	defer bindEnv(fs, flagNames(fs))
+
+	p := new(time.Duration)
+	*p = value
+	f := &durationFlag{value: p}
+	for _, opt := range opts {
+		opt(f)
+	}
+	fs.Var(f, name, usage)
+	return p
+}
+
+// String returns the current duration in a human-readable form.
+func (f *durationFlag) String() string {
+	if f.value == nil {
+		return "0s"
+	}
+	return formatDuration(*f.value)
+}
+
+// Set parses a duration and checks its bounds.
+func (f *durationFlag) Set(
+	s string,
+) error {
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return fmt.Errorf("invalid duration %q (e.g., 30s, 5m, 1h30m)", s)
+	}
+	if f.min != nil && d < *f.min {
+		return fmt.Errorf("duration %s is shorter than the minimum %s", formatDuration(d), formatDuration(*f.min))
+	}
+	if f.max != nil && d > *f.max {
+		return fmt.Errorf("duration %s is longer than the maximum %s", formatDuration(d), formatDuration(*f.max))
+	}
+	*f.value = d
+	return nil
+}
+
+// formatDuration returns time.Duration.String without the trailing zero units
+// (e.g., "24h" for "24h0m0s", "1h30m" for "1h30m0s").
+func formatDuration(
+	d time.Duration,
+) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
@@ -0,0 +1,111 @@
+package gocli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// enumFlag is a flag.Value accepting the values of allowed in any case,
+// stored as written in allowed.
+type enumFlag[T ~string] struct {
+	value   *T
+	allowed []T
+}
+
+// Enum defines a flag accepting one of a fixed set of values, e.g., an
+// encoding or a policy, with the behavior of the gocli registrars: it falls
+// back to its environment variable (see EnvVarName) when not given on the
+// command line, and a value outside the set is rejected with the list of valid
+// values when the command line is parsed, rather than when the configuration
+// is used.
+//
+// Values are matched regardless of case, and stored as written in allowed.
+// The valid values are appended to usage (e.g., "Log encoding (json|console|ecs)").
+// An empty string in allowed accepts the empty value, e.g., for an optional
+// setting; it is not listed in the help.
+//
+// It panics if allowed is empty or value is not one of allowed, as these are
+// programming errors.
+//
+// Parameters:
+//   - fs       The flag set into which the flag is registered.
+//   - name     The flag name.
+//   - allowed  The valid values, in the order they are listed in the help.
+//   - value    The default value.
+//   - usage    The flag help, without the valid values.
+//
+// Returns:
+//
+//	A pointer to the flag value.
+//
+// Example:
+//
+//	type Compression string
+//	...
+//	compression := gocli.Enum(fs, "relay-compression", []Compression{"none", "gzip", "zstd"}, "gzip", "Compression of the relay payloads")
+func Enum[T ~string](
+	fs *flag.FlagSet,
+	name string,
+	allowed []T,
+	value T,
+	usage string,
+) *T {
+	defer bindEnv(fs, flagNames(fs))
+
+	if len(allowed) == 0 {
+		panic(fmt.Sprintf("gocli: no allowed values for -%s", name))
+	}
+	p := new(T)
+	f := &enumFlag[T]{value: p, allowed: append([]T(nil), allowed...)}
+	if err := f.Set(string(value)); err != nil {
+		panic(fmt.Sprintf("gocli: invalid default for -%s: %v", name, err))
+	}
+	fs.Var(f, name, usage+" ("+strings.Join(f.listed(), "|")+")")
+	return p
+}
+
+// String returns the current value.
+func (f *enumFlag[T]) String() string {
+	if f.value == nil {
+		return ""
+	}
+	return string(*f.value)
+}
+
+// Get returns the current value as a string, so that the default is quoted in
+// the usage like those of string flags.
+func (f *enumFlag[T]) Get() any {
+	return f.String()
+}
+
+// typeName returns the type shown in the usage.
+func (f *enumFlag[T]) typeName() string {
+	return "string"
+}
+
+// Set stores the allowed value matching s.
+func (f *enumFlag[T]) Set(
+	s string,
+) error {
+	trimmed := strings.TrimSpace(s)
+	for _, valid := range f.allowed {
+		if strings.EqualFold(trimmed, string(valid)) {
+			*f.value = valid
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown value %q (valid values: %s)", s, strings.Join(f.listed(), ", "))
+}
+
+// listed returns the allowed values shown to the user, i.e., all but the
+// empty string.
+func (f *enumFlag[T]) listed() []string {
+	listed := make([]string, 0, len(f.allowed))
+	for _, valid := range f.allowed {
+		if valid != "" {
+			listed = append(listed, string(valid))
+		}
+	}
+	return listed
+}
@@ -0,0 +1,141 @@
+package gocli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// DefaultEnvPrefix is the prefix of the environment variables read by the
+// flag registrars until SetEnvPrefix changes it.
+const DefaultEnvPrefix = "KUBENSAGE"
+
+var (
+	envPrefixMu sync.RWMutex
+	envPrefix   = DefaultEnvPrefix
+)
+
+// SetEnvPrefix changes the prefix of the environment variables read by the
+// flag registrars (e.g., "AGENT" reads AGENT_LOG_LEVEL for --log-level). An
+// empty prefix disables the environment fallback.
+//
+// It only affects flags registered afterwards, so it must be called before the
+// Register*Flags functions.
+//
+// Parameters:
+//   - prefix  The prefix, without the trailing underscore.
+func SetEnvPrefix(
+	prefix string,
+) {
+	envPrefixMu.Lock()
+	defer envPrefixMu.Unlock()
+	envPrefix = strings.TrimSuffix(prefix, "_")
+}
+
+// EnvVarName returns the environment variable read for a flag: the prefix, an
+// underscore and the flag name upper-cased with dashes replaced by underscores
+// (e.g., KUBENSAGE_LOG_LEVEL for --log-level).
+//
+// Parameters:
+//   - flagName  The flag name, without leading dashes.
+//
+// Returns:
+//
+//	The variable name, or the empty string if the environment fallback is
+//	disabled.
+func EnvVarName(
+	flagName string,
+) string {
+	envPrefixMu.RLock()
+	prefix := envPrefix
+	envPrefixMu.RUnlock()
+
+	if prefix == "" {
+		return ""
+	}
+	return prefix + "_" + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// flagNames returns the names of the flags already registered in fs.
+func flagNames(
+	fs *flag.FlagSet,
+) map[string]struct{} {
+	names := make(map[string]struct{})
+	fs.VisitAll(func(f *flag.Flag) {
+		names[f.Name] = struct{}{}
+	})
+	return names
+}
+
+// bindEnv sets every flag of fs not in known from its environment variable
+// (see EnvVarName), giving the precedence flag > env > default: the command
+// line, parsed later, still overrides the value. The flag counts as set for
+// fs.Visit (e.g., for Required), and the help output shows the value taken from
+// the environment as the default. The flags take the presets of the profile of
+// fs first (see RegisterProfileFlag).
+//
+// Registrars call it deferred, with the flags known before their registration:
+//
+//	defer bindEnv(fs, flagNames(fs))
+//
+// Values are validated like command-line values. An invalid value is reported
+// on the flag set output and handled according to its error handling: the
+// process exits with status 2 for flag.ExitOnError, panics for
+// flag.PanicOnError, and keeps the built-in default for flag.ContinueOnError.
+func bindEnv(
+	fs *flag.FlagSet,
+	known map[string]struct{},
+) {
+	set := make(map[string]struct{})
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = struct{}{}
+	})
+
+	fs.VisitAll(func(f *flag.Flag) {
+		if _, ok := known[f.Name]; ok {
+			return
+		}
+		if _, ok := set[f.Name]; ok {
+			return // already bound, e.g., by Duration within a registrar
+		}
+		if isAlias(fs, f.Name) {
+			return // bound through its flag
+		}
+		applyProfile(fs, f)
+		name := EnvVarName(f.Name)
+		if name == "" {
+			return
+		}
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return
+		}
+
+		if err := fs.Set(f.Name, value); err != nil {
+			err = fmt.Errorf("invalid value %q for environment variable %s (flag -%s): %w", value, name, f.Name, err)
+			_, _ = fmt.Fprintln(fs.Output(), err)
+			switch fs.ErrorHandling() {
+			case flag.ExitOnError:
+				os.Exit(2)
+			case flag.PanicOnError:
+				panic(err)
+			}
+			_ = f.Value.Set(f.DefValue)
+			return
+		}
+		f.DefValue = f.Value.String()
+		if binder, ok := f.Value.(defaultBinder); ok {
+			binder.bindDefault()
+		}
+		recordOrigin(fs, f, SourceEnv)
+	})
+}
+
+// defaultBinder is implemented by flag values whose Set depends on previous
+// calls (e.g., StringSlice appending repeated occurrences), to treat the value
+// from the environment as a default.
+type defaultBinder interface {
+	bindDefault()
+}
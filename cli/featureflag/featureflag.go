@@ -0,0 +1,258 @@
+// Package featureflag gates features of an application behind named switches,
+// so that new behavior (e.g., a collector) can be rolled out gradually: each
+// gate has a default and a maturity, can be toggled with the --feature-gates
+// flag (see gocli.RegisterFeatureGatesFlag), and is queried at runtime, with
+// callbacks notified when a reload of the configuration toggles it.
+package featureflag
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Maturity is the stage of a feature in its rollout.
+type Maturity string
+
+// Maturities of a feature, from experimental to stable.
+const (
+	Alpha Maturity = "ALPHA" // Experimental, may change or be removed; usually disabled by default
+	Beta  Maturity = "BETA"  // Well tested, may still change; usually enabled by default
+	GA    Maturity = "GA"    // Stable; always enabled, the gate only remains for compatibility
+)
+
+// Spec describes a feature gate.
+type Spec struct {
+	Default     bool     // Whether the feature is enabled when the gate is not set; must be true for GA
+	Maturity    Maturity // Stage of the feature; GA gates cannot be disabled
+	Description string   // What the feature does, listed in the help (e.g., "Collect GPU metrics")
+}
+
+// Gates holds the feature gates of an application. Gates are added at
+// startup with Add, then set from the --feature-gates flag and queried with
+// Enabled. It is safe for concurrent use.
+//
+// The zero value is not usable; create one with New.
+type Gates struct {
+	mu        sync.RWMutex
+	specs     map[string]Spec
+	overrides map[string]bool // values set explicitly, by gate name
+	callbacks []func(name string, enabled bool)
+}
+
+// New returns a set of feature gates, empty until Add is called.
+//
+// Returns:
+//
+//	The feature gates.
+func New() *Gates {
+	return &Gates{
+		specs:     make(map[string]Spec),
+		overrides: make(map[string]bool),
+	}
+}
+
+// Add declares a feature gate. It panics if the name is empty, holds a
+// comma, an equal sign or a space, is already declared, or if a GA gate is
+// disabled by default, as these are programming errors.
+//
+// Parameters:
+//   - name  The gate name, by convention in CamelCase (e.g., "GPUCollector").
+//   - spec  The default, maturity and description of the gate.
+//
+// Example:
+//
+//	var features = featureflag.New()
+//
+//	const GPUCollector = "GPUCollector"
+//
+//	func init() {
+//		features.Add(GPUCollector, featureflag.Spec{Default: false, Maturity: featureflag.Alpha, Description: "Collect GPU metrics"})
+//	}
+func (g *Gates) Add(
+	name string,
+	spec Spec,
+) {
+	if name == "" || strings.ContainsAny(name, ",= \t") {
+		panic(fmt.Sprintf("featureflag: invalid gate name %q", name))
+	}
+	if spec.Maturity == GA && !spec.Default {
+		panic(fmt.Sprintf("featureflag: GA gate %s must be enabled by default", name))
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.specs[name]; ok {
+		panic(fmt.Sprintf("featureflag: gate %s declared twice", name))
+	}
+	g.specs[name] = spec
+}
+
+// Enabled reports whether a feature is enabled: the value set for its gate,
+// else its default. It panics if the gate is not declared, as this is a
+// programming error.
+//
+// Parameters:
+//   - name  The gate name.
+//
+// Returns:
+//
+//	Whether the feature is enabled.
+func (g *Gates) Enabled(
+	name string,
+) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	spec, ok := g.specs[name]
+	if !ok {
+		panic(fmt.Sprintf("featureflag: undeclared gate %s", name))
+	}
+	return g.enabled(name, spec)
+}
+
+// OnChange registers a callback invoked for every gate toggled by Set, e.g.,
+// to start or stop a collector when the configuration is reloaded. Callbacks
+// run on the goroutine calling Set, gate by gate in name order.
+//
+// Parameters:
+//   - fn  The callback, receiving the gate name and whether it is now enabled.
+func (g *Gates) OnChange(
+	fn func(name string, enabled bool),
+) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.callbacks = append(g.callbacks, fn)
+}
+
+// Set sets the gates from a comma-separated list of name=bool pairs (e.g.,
+// "GPUCollector=true,LegacyParser=false"), implementing flag.Value. Gates
+// missing from the list get their default back, so that setting the flag
+// again (e.g., on a configuration reload) replaces the previous list.
+//
+// Parameters:
+//   - s  The list; empty resets every gate to its default.
+//
+// Returns:
+//
+//	An error joining every unknown gate, invalid value and attempt to
+//	disable a GA gate, in which case no gate is changed.
+func (g *Gates) Set(
+	s string,
+) error {
+	overrides := make(map[string]bool)
+	var errs []error
+
+	g.mu.Lock()
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok {
+			errs = append(errs, fmt.Errorf("missing value for feature gate %s (expected %s=true or %s=false)", name, name, name))
+			continue
+		}
+		spec, known := g.specs[name]
+		if !known {
+			errs = append(errs, fmt.Errorf("unknown feature gate %q", name))
+			continue
+		}
+		enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid value %q for feature gate %s", value, name))
+			continue
+		}
+		if spec.Maturity == GA && !enabled {
+			errs = append(errs, fmt.Errorf("feature gate %s is GA and cannot be disabled", name))
+			continue
+		}
+		overrides[name] = enabled
+	}
+	if len(errs) > 0 {
+		g.mu.Unlock()
+		return errors.Join(errs...)
+	}
+
+	var changed []string
+	for name, spec := range g.specs {
+		was := g.enabled(name, spec)
+		is, ok := overrides[name]
+		if !ok {
+			is = spec.Default
+		}
+		if was != is {
+			changed = append(changed, name)
+		}
+	}
+	g.overrides = overrides
+	slices.Sort(changed)
+	states := make([]bool, len(changed))
+	for i, name := range changed {
+		states[i] = g.enabled(name, g.specs[name])
+	}
+	callbacks := slices.Clone(g.callbacks)
+	g.mu.Unlock()
+
+	for i, name := range changed {
+		for _, fn := range callbacks {
+			fn(name, states[i])
+		}
+	}
+	return nil
+}
+
+// String returns the gates set explicitly, as a comma-separated list of
+// name=bool pairs in name order, implementing flag.Value.
+func (g *Gates) String() string {
+	if g == nil {
+		return ""
+	}
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	pairs := make([]string, 0, len(g.overrides))
+	for name, enabled := range g.overrides {
+		pairs = append(pairs, name+"="+strconv.FormatBool(enabled))
+	}
+	slices.Sort(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Known describes the declared gates, one per line in name order, for the
+// help of the --feature-gates flag.
+//
+// Returns:
+//
+//	The descriptions (e.g., "GPUCollector=true|false (ALPHA - default=false)
+//	Collect GPU metrics").
+func (g *Gates) Known() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	known := make([]string, 0, len(g.specs))
+	for name, spec := range g.specs {
+		line := fmt.Sprintf("%s=true|false (%s - default=%t)", name, spec.Maturity, spec.Default)
+		if spec.Description != "" {
+			line += " " + spec.Description
+		}
+		known = append(known, line)
+	}
+	slices.Sort(known)
+	return known
+}
+
+// enabled reports whether a gate is enabled; g.mu must be held.
+func (g *Gates) enabled(
+	name string,
+	spec Spec,
+) bool {
+	if enabled, ok := g.overrides[name]; ok {
+		return enabled
+	}
+	return spec.Default
+}
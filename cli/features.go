@@ -0,0 +1,57 @@
+package gocli
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/kubensage/common/cli/featureflag"
+)
+
+// RegisterFeatureGatesFlag registers the --feature-gates flag, toggling the
+// feature gates of an application (see featureflag.Gates) for a gated rollout
+// of new features, e.g., a collector enabled on a few nodes first.
+//
+// Registered flags:
+//
+//	--feature-gates  list  Comma-separated name=true|false pairs toggling feature gates (default "")
+//
+// The help lists the gates declared before the registration, with their
+// maturity and default. A gate not in the list keeps its default, and an
+// unknown gate, an invalid value or disabling a GA gate fails the parsing of
+// the command line. The flag takes the value of its environment variable if
+// not given on the command line (see EnvVarName), e.g.
+// KUBENSAGE_FEATURE_GATES, and the configuration file (see
+// RegisterConfigFileFlag) may set it too: a reload setting another list
+// notifies the callbacks of the gates toggled (see featureflag.Gates.OnChange).
+//
+// Parameters:
+//   - fs     The flag set into which the flag will be registered.
+//   - gates  The declared feature gates, set by the flag.
+//   - opts   Options of the registrar (see WithDefaults and WithPrefix).
+//
+// Example:
+//
+//	features := featureflag.New()
+//	features.Add("GPUCollector", featureflag.Spec{Default: false, Maturity: featureflag.Alpha, Description: "Collect GPU metrics"})
+//	gocli.RegisterFeatureGatesFlag(flag.CommandLine, features)
+//	flag.Parse()
+//	if features.Enabled("GPUCollector") {
+//		...
+//	}
+func RegisterFeatureGatesFlag(
+	fs *flag.FlagSet,
+	gates *featureflag.Gates,
+	opts ...RegisterOption,
+) {
+	defer bindEnv(fs, flagNames(fs))
+	defer groupFlags(fs, GroupGeneral, flagNames(fs))
+	defer applyOptions(fs, flagNames(fs), opts)
+
+	prefix := optionsPrefix(opts)
+
+	usage := "Comma-separated `list` of name=true|false pairs toggling feature gates"
+	if known := gates.Known(); len(known) > 0 {
+		usage += ": " + strings.Join(known, "; ")
+	}
+	fs.Var(gates, prefix+"feature-gates", usage)
+}
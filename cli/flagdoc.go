@@ -0,0 +1,178 @@
+package gocli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// FlagDoc describes a flag for the reference documentation of a binary (see
+// ExportFlags).
+type FlagDoc struct {
+	Name    string `json:"name"`              // Flag name, without leading dashes (e.g., "log-level")
+	Alias   string `json:"alias,omitempty"`   // Short alias (see Alias), e.g. "l"
+	Group   string `json:"group"`             // Section of the usage output (see SetFlagGroup)
+	Type    string `json:"type"`              // Type of the value as shown in the usage (e.g., "duration"), "bool" for boolean flags
+	Default string `json:"default,omitempty"` // Default value as shown in the usage, quoted for strings; empty for the zero value of the type
+	Usage   string `json:"usage"`             // Help text, on one line
+	EnvVar  string `json:"env,omitempty"`     // Environment variable of the flag (see EnvVarName); empty if the fallback is disabled
+}
+
+// ExportFlags describes the flags of a flag set, as printed by PrintDefaults,
+// so that downstream repositories can generate the reference of their command
+// line from code (see WriteFlagsMarkdown and WriteFlagsJSON) rather than
+// maintain it by hand.
+//
+// Hidden flags (see Hide and Deprecate) and short aliases, described with
+// their flag, are left out. The defaults are those of fs when called, i.e.,
+// including the environment variables set when the flags were registered.
+//
+// Parameters:
+//   - fs  The flag set whose flags are described.
+//
+// Returns:
+//
+//	The descriptions by section, in the order of the usage output, then by
+//	flag name.
+//
+// Example:
+//
+//	gocli.SetEnvPrefix("KUBENSAGE")
+//	gocli.RegisterLogStdFlags(fs)
+//	_ = gocli.WriteFlagsMarkdown(os.Stdout, gocli.ExportFlags(fs))
+func ExportFlags(
+	fs *flag.FlagSet,
+) []FlagDoc {
+	shown, sections := flagSections(fs)
+
+	var docs []FlagDoc
+	for _, group := range shown {
+		for _, f := range sections[group] {
+			typeName := flagType(f)
+			if typeName == "" && isBoolFlag(f) {
+				typeName = "bool"
+			}
+			def, _ := flagDefault(f)
+			docs = append(docs, FlagDoc{
+				Name:    f.Name,
+				Alias:   aliasOf(fs, f),
+				Group:   group,
+				Type:    typeName,
+				Default: def,
+				Usage:   flagHelp(f),
+				EnvVar:  EnvVarName(f.Name),
+			})
+		}
+	}
+	return docs
+}
+
+// WriteFlagsMarkdown renders flag descriptions as Markdown, one table per
+// section, each under a third-level heading naming the section.
+//
+// Parameters:
+//   - w     The writer receiving the Markdown.
+//   - docs  The descriptions, typically from ExportFlags.
+//
+// Returns:
+//
+//	An error if writing to w fails.
+//
+// Example output:
+//
+//	### Logging
+//
+//	| Flag | Type | Default | Environment | Description |
+//	|------|------|---------|-------------|-------------|
+//	| `-l`, `--log-level` | `level` | `"info"` | `KUBENSAGE_LOG_LEVEL` | Set log level (debug\|info\|warn\|error\|dpanic\|panic\|fatal) |
+func WriteFlagsMarkdown(
+	w io.Writer,
+	docs []FlagDoc,
+) error {
+	var b strings.Builder
+	group := ""
+	for i, doc := range docs {
+		if i == 0 || doc.Group != group {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			group = doc.Group
+			fmt.Fprintf(&b, "### %s\n\n", group)
+			b.WriteString("| Flag | Type | Default | Environment | Description |\n")
+			b.WriteString("|------|------|---------|-------------|-------------|\n")
+		}
+
+		name := markdownCode("--" + doc.Name)
+		if doc.Alias != "" {
+			name = markdownCode("-"+doc.Alias) + ", " + name
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n",
+			name,
+			markdownCode(doc.Type),
+			markdownCode(doc.Default),
+			markdownCode(doc.EnvVar),
+			markdownEscape(doc.Usage),
+		)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteFlagsJSON renders flag descriptions as an indented JSON array of
+// FlagDoc objects, e.g., for a documentation site generator.
+//
+// Parameters:
+//   - w     The writer receiving the JSON.
+//   - docs  The descriptions, typically from ExportFlags.
+//
+// Returns:
+//
+//	An error if writing to w fails.
+func WriteFlagsJSON(
+	w io.Writer,
+	docs []FlagDoc,
+) error {
+	if docs == nil {
+		docs = []FlagDoc{} // "[]" rather than "null"
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(docs)
+}
+
+// markdownCode formats a value as inline code in a table cell, or returns the
+// empty string for an empty value.
+func markdownCode(
+	s string,
+) string {
+	if s == "" {
+		return ""
+	}
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + strings.ReplaceAll(s, "|", `\|`) + fence
+}
+
+// markdownEscape escapes the characters of a text that would break a table
+// cell or be read as Markdown.
+func markdownEscape(
+	s string,
+) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		"|", `\|`,
+		"*", `\*`,
+		"_", `\_`,
+		"<", "&lt;",
+		">", "&gt;",
+	).Replace(s)
+}
@@ -0,0 +1,140 @@
+package gocli
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// floatFlag is a flag.Value holding a float64 within inclusive bounds, written
+// as a percentage (e.g., "75%") if percent is set.
+type floatFlag struct {
+	value   *float64
+	min     float64
+	max     float64
+	percent bool
+}
+
+// Float64InRange defines a float64 flag accepting values within [min, max]
+// only, e.g., a sampling rate between 0 and 1, with the behavior of the gocli
+// registrars: it falls back to its environment variable (see EnvVarName) when
+// not given on the command line, and values outside the bounds are rejected
+// when the command line is parsed.
+//
+// It panics if min is greater than max or either is NaN, as these are
+// programming errors.
+//
+// Parameters:
+//   - fs     The flag set into which the flag is registered.
+//   - name   The flag name.
+//   - value  The default value; it is not checked against the bounds.
+//   - min    The smallest accepted value.
+//   - max    The largest accepted value.
+//   - usage  The flag help.
+//
+// Returns:
+//
+//	A pointer to the flag value.
+//
+// Example:
+//
+//	sampleRate := gocli.Float64InRange(fs, "trace-sample-rate", 0.1, 0, 1, "Fraction of the requests traced")
+func Float64InRange(
+	fs *flag.FlagSet,
+	name string,
+	value float64,
+	min float64,
+	max float64,
+	usage string,
+) *float64 {
+	defer bindEnv(fs, flagNames(fs))
+
+	if !(min <= max) {
+		panic(fmt.Sprintf("gocli: invalid range [%v, %v] for -%s", min, max, name))
+	}
+	p := new(float64)
+	*p = value
+	fs.Var(&floatFlag{value: p, min: min, max: max}, name, usage)
+	return p
+}
+
+// Percent defines a percentage flag, e.g., a CPU threshold, accepting values
+// between 0 and 100 written with or without a percent sign (e.g., "75%",
+// "12.5"). It behaves like Float64InRange, and its default is shown in the help
+// with a percent sign (e.g., "(default 80%)").
+//
+// Parameters:
+//   - fs     The flag set into which the flag is registered.
+//   - name   The flag name.
+//   - value  The default percentage (e.g., 80 for 80%).
+//   - usage  The flag help.
+//
+// Returns:
+//
+//	A pointer to the percentage, between 0 and 100; divide it by 100 for a
+//	ratio.
+//
+// Example:
+//
+//	cpuThreshold := gocli.Percent(fs, "cpu-alert-threshold", 90, "CPU usage `percentage` raising an alert")
+func Percent(
+	fs *flag.FlagSet,
+	name string,
+	value float64,
+	usage string,
+) *float64 {
+	defer bindEnv(fs, flagNames(fs))
+
+	p := new(float64)
+	*p = value
+	fs.Var(&floatFlag{value: p, min: 0, max: 100, percent: true}, name, usage)
+	return p
+}
+
+// String returns the current value, followed by a percent sign for
+// percentages.
+func (f *floatFlag) String() string {
+	var v float64
+	if f.value != nil {
+		v = *f.value
+	}
+	s := strconv.FormatFloat(v, 'g', -1, 64)
+	if f.percent {
+		return s + "%"
+	}
+	return s
+}
+
+// Set parses a number, or a percentage, and checks its bounds.
+func (f *floatFlag) Set(
+	s string,
+) error {
+	trimmed := strings.TrimSpace(s)
+	if f.percent {
+		trimmed = strings.TrimSpace(strings.TrimSuffix(trimmed, "%"))
+	}
+	v, err := strconv.ParseFloat(trimmed, 64)
+	if err != nil || math.IsNaN(v) {
+		if f.percent {
+			return fmt.Errorf("invalid percentage %q (e.g., 75%%, 12.5)", s)
+		}
+		return fmt.Errorf("invalid number %q", s)
+	}
+	if v < f.min || v > f.max {
+		if f.percent {
+			return fmt.Errorf("percentage %s is outside [0%%, 100%%]", f.format(v))
+		}
+		return fmt.Errorf("value %s is outside [%s, %s]", f.format(v), f.format(f.min), f.format(f.max))
+	}
+	*f.value = v
+	return nil
+}
+
+// format returns a value as String does.
+func (f *floatFlag) format(
+	v float64,
+) string {
+	return (&floatFlag{value: &v, percent: f.percent}).String()
+}
@@ -0,0 +1,206 @@
+package gocli
+
+import (
+	"flag"
+	"net/netip"
+	"time"
+)
+
+// GrpcClientConfig holds configuration options for a gRPC client connection,
+// consumed by gogrpc.GrpcConnection.
+type GrpcClientConfig struct {
+	GrpcTarget              string        `log:"grpc-target" validate:"required"`                                 // Server address (e.g., "relay:50051" or "unix:///run/kubensage/relay.sock")
+	GrpcConnectTimeout      time.Duration `log:"grpc-connect-timeout" validate:"min=0s"`                          // Minimum time given to a connection attempt; 0 uses gRPC's default (20s)
+	GrpcKeepaliveTime       time.Duration `log:"grpc-keepalive-time" validate:"min=0s"`                           // Idle time after which the client pings the server; 0 disables keepalive pings
+	GrpcKeepaliveTimeout    time.Duration `log:"grpc-keepalive-timeout" validate:"min=0s"`                        // Time to wait for a ping acknowledgement before closing the connection
+	GrpcMaxMsgSize          ByteSize      `log:"grpc-max-msg-size" validate:"min=0"`                              // Maximum size of sent and received messages; 0 uses gRPC's defaults
+	GrpcTLS                 bool          `log:"grpc-tls"`                                                        // Whether the connection uses TLS; implied by GrpcTLSCAFile and GrpcTLSCertFile
+	GrpcTLSCAFile           string        `log:"grpc-tls-ca" validate:"file-exists"`                              // PEM bundle of the CAs trusted to sign the server certificate; empty uses the system pool
+	GrpcTLSCertFile         string        `log:"grpc-tls-cert" validate:"file-exists,required-with=grpc-tls-key"` // PEM client certificate for mutual TLS; empty disables client authentication
+	GrpcTLSKeyFile          string        `log:"grpc-tls-key" validate:"file-exists,required-with=grpc-tls-cert"` // PEM private key of the client certificate
+	GrpcTLSServerName       string        `log:"grpc-tls-server-name"`                                            // Name expected in the server certificate; empty uses the host of the target
+	GrpcRetryMaxAttempts    int           `log:"grpc-retry-max-attempts" validate:"min=0,max=5"`                  // Attempts of a call failing with UNAVAILABLE, including the first; 0 or 1 disables retries
+	GrpcRetryInitialBackoff time.Duration `log:"grpc-retry-initial-backoff" validate:"min=0s"`                    // Delay before the first retry, randomized and growing exponentially
+	GrpcRetryMaxBackoff     time.Duration `log:"grpc-retry-max-backoff" validate:"min=0s"`                        // Maximum delay between retries
+}
+
+// RegisterGrpcClientFlags registers command-line flags for configuring a gRPC
+// client connection.
+//
+// Registered flags:
+//
+//	--grpc-target                 string     Server address, host:port or unix:///path (default "<target>")
+//	--grpc-connect-timeout        duration   Minimum time given to a connection attempt (default 20s)
+//	--grpc-keepalive-time         duration   Idle time before pinging the server, 0 disables pings (default 0s)
+//	--grpc-keepalive-timeout      duration   Time to wait for a ping acknowledgement (default 20s)
+//	--grpc-max-msg-size           size       Maximum size of sent and received messages, a bare number counts MiB (default 4MiB)
+//	--grpc-tls                    bool       Use TLS, implied by --grpc-tls-ca and --grpc-tls-cert (default false)
+//	--grpc-tls-ca                 string     PEM bundle of the CAs trusted for the server certificate, empty uses the system pool (default "")
+//	--grpc-tls-cert               string     PEM client certificate for mutual TLS (default "")
+//	--grpc-tls-key                string     PEM private key of the client certificate (default "")
+//	--grpc-tls-server-name        string     Name expected in the server certificate, empty uses the target host (default "")
+//	--grpc-retry-max-attempts     int        Attempts of a call failing with UNAVAILABLE, at most 5, 0 or 1 disables retries (default 3)
+//	--grpc-retry-initial-backoff  duration   Delay before the first retry (default 200ms)
+//	--grpc-retry-max-backoff      duration   Maximum delay between retries (default 5s)
+//
+// A flag not given on the command line takes the value of its environment
+// variable if set (see EnvVarName and SetEnvPrefix), e.g. KUBENSAGE_GRPC_TARGET;
+// the precedence is flag > environment > default.
+//
+// Parameters:
+//   - fs      The flag set into which the flags will be registered.
+//   - target  The default server address (e.g., "unix:///run/kubensage/relay.sock").
+//   - opts    Options of the registrar (see WithDefaults and WithPrefix).
+//
+// Returns:
+//
+//	A closure that, when invoked, returns a populated *GrpcClientConfig
+//	containing the values from the parsed flags, checked by Validate, or
+//	an error joining every violation, also reported by ParseOrExit.
+func RegisterGrpcClientFlags(
+	fs *flag.FlagSet,
+	target string,
+	opts ...RegisterOption,
+) func() (*GrpcClientConfig, error) {
+	defer bindEnv(fs, flagNames(fs))
+	defer groupFlags(fs, GroupGrpc, flagNames(fs))
+	defer applyOptions(fs, flagNames(fs), opts)
+
+	prefix := optionsPrefix(opts)
+
+	grpcTarget := fs.String(prefix+"grpc-target", target, "gRPC server `address` (host:port or unix:///path)")
+	grpcConnectTimeout := Duration(fs, prefix+"grpc-connect-timeout", 20*time.Second, "Minimum `time` given to a gRPC connection attempt", MinDuration(0))
+	grpcKeepaliveTime := Duration(fs, prefix+"grpc-keepalive-time", 0, "Idle `time` before pinging the gRPC server (0 disables pings)", MinDuration(0))
+	grpcKeepaliveTimeout := Duration(fs, prefix+"grpc-keepalive-timeout", 20*time.Second, "Max `time` to wait for a gRPC ping acknowledgement", MinDuration(0))
+	grpcMaxMsgSize := bytesVar(fs, prefix+"grpc-max-msg-size", 4*MiB, MiB, "Maximum `size` of sent and received gRPC messages (e.g., 16MiB; a bare number counts MiB)")
+	grpcTLS := fs.Bool(prefix+"grpc-tls", false, "Use TLS for the gRPC connection (implied by --grpc-tls-ca and --grpc-tls-cert)")
+	grpcTLSCAFile := fs.String(prefix+"grpc-tls-ca", "", "PEM bundle of the CAs trusted for the gRPC server certificate (empty uses the system pool)")
+	grpcTLSCertFile := fs.String(prefix+"grpc-tls-cert", "", "PEM client certificate for mutual TLS with the gRPC server")
+	grpcTLSKeyFile := fs.String(prefix+"grpc-tls-key", "", "PEM private key of the gRPC client certificate")
+	grpcTLSServerName := fs.String(prefix+"grpc-tls-server-name", "", "Name expected in the gRPC server certificate (empty uses the target host)")
+	grpcRetryMaxAttempts := fs.Int(prefix+"grpc-retry-max-attempts", 3, "Attempts of a gRPC call failing with UNAVAILABLE, at most 5 (0 or 1 disables retries)")
+	grpcRetryInitialBackoff := Duration(fs, prefix+"grpc-retry-initial-backoff", 200*time.Millisecond, "Initial `delay` before retrying a failed gRPC call", MinDuration(0))
+	grpcRetryMaxBackoff := Duration(fs, prefix+"grpc-retry-max-backoff", 5*time.Second, "Maximum `delay` between gRPC retries", MinDuration(0))
+
+	load := checked(fs, func() (*GrpcClientConfig, error) {
+		cfg := &GrpcClientConfig{
+			GrpcTarget:              *grpcTarget,
+			GrpcConnectTimeout:      *grpcConnectTimeout,
+			GrpcKeepaliveTime:       *grpcKeepaliveTime,
+			GrpcKeepaliveTimeout:    *grpcKeepaliveTimeout,
+			GrpcMaxMsgSize:          *grpcMaxMsgSize,
+			GrpcTLS:                 *grpcTLS,
+			GrpcTLSCAFile:           *grpcTLSCAFile,
+			GrpcTLSCertFile:         *grpcTLSCertFile,
+			GrpcTLSKeyFile:          *grpcTLSKeyFile,
+			GrpcTLSServerName:       *grpcTLSServerName,
+			GrpcRetryMaxAttempts:    *grpcRetryMaxAttempts,
+			GrpcRetryInitialBackoff: *grpcRetryInitialBackoff,
+			GrpcRetryMaxBackoff:     *grpcRetryMaxBackoff,
+		}
+		if err := validateConfig(cfg, prefix); err != nil {
+			return nil, err
+		}
+		recordSources(fs, cfg, prefix)
+		return cfg, nil
+	})
+	AddPreflight(fs, prefix+"gRPC target", func() error {
+		cfg, err := load()
+		if err != nil {
+			return err
+		}
+		if cfg.GrpcTLS || cfg.GrpcTLSCAFile != "" || cfg.GrpcTLSCertFile != "" {
+			if _, err := ClientTLSConfig(&TLSConfig{
+				TLSCertFile: cfg.GrpcTLSCertFile,
+				TLSKeyFile:  cfg.GrpcTLSKeyFile,
+				TLSCAFile:   cfg.GrpcTLSCAFile,
+			}); err != nil {
+				return err
+			}
+		}
+		return preflightGrpcTarget(cfg.GrpcTarget)
+	})
+	return load
+}
+
+// GrpcServerConfig holds configuration options for a gRPC server, consumed by
+// gogrpc.NewGrpcServer.
+type GrpcServerConfig struct {
+	GrpcListen                     string         `log:"grpc-listen" validate:"required"`                                                                                       // Listen address: host:port, or unix:///path for a Unix domain socket
+	GrpcServerTLSCertFile          string         `log:"grpc-server-tls-cert" validate:"file-exists,required-with=grpc-server-tls-key,required-with=grpc-server-tls-client-ca"` // PEM server certificate; empty serves plaintext
+	GrpcServerTLSKeyFile           string         `log:"grpc-server-tls-key" validate:"file-exists,required-with=grpc-server-tls-cert"`                                         // PEM private key of the server certificate
+	GrpcServerTLSClientCAFile      string         `log:"grpc-server-tls-client-ca" validate:"file-exists"`                                                                      // PEM bundle of the CAs signing client certificates; set to require mutual TLS
+	GrpcServerMaxConcurrentStreams int            `log:"grpc-server-max-concurrent-streams" validate:"min=0"`                                                                   // Maximum concurrent streams per client connection; 0 leaves it unlimited
+	GrpcServerMaxMsgSize           ByteSize       `log:"grpc-server-max-msg-size" validate:"min=0"`                                                                             // Maximum size of received and sent messages; 0 uses gRPC's defaults
+	GrpcServerShutdownTimeout      time.Duration  `log:"grpc-server-shutdown-timeout" validate:"min=0s"`                                                                        // Time given to running calls on shutdown before they are cancelled
+	GrpcServerAllowedCIDRs         []netip.Prefix `log:"grpc-server-allowed-cidrs"`                                                                                             // Networks of the clients allowed to connect over TCP; empty allows all
+}
+
+// RegisterGrpcServerFlags registers command-line flags for configuring a gRPC
+// server.
+//
+// Registered flags:
+//
+//	--grpc-listen                         string     Listen address, host:port or unix:///path (default "<listen>")
+//	--grpc-server-tls-cert                string     PEM server certificate, empty serves plaintext (default "")
+//	--grpc-server-tls-key                 string     PEM private key of the server certificate (default "")
+//	--grpc-server-tls-client-ca           string     PEM bundle of the CAs signing client certificates, set to require mutual TLS (default "")
+//	--grpc-server-max-concurrent-streams  int        Maximum concurrent streams per client connection, 0 leaves it unlimited (default 0)
+//	--grpc-server-max-msg-size            size       Maximum size of received and sent messages (default 4MiB)
+//	--grpc-server-shutdown-timeout        duration   Time given to running calls on shutdown (default 10s)
+//	--grpc-server-allowed-cidrs           string     Comma-separated networks of the clients allowed to connect over TCP, repeatable, empty allows all (default "")
+//
+// A flag not given on the command line takes the value of its environment
+// variable if set (see EnvVarName and SetEnvPrefix), e.g. KUBENSAGE_GRPC_LISTEN;
+// the precedence is flag > environment > default. The listen address and the
+// allowed networks are checked and normalized when the command line is parsed
+// (see HostPort and CIDR).
+//
+// Parameters:
+//   - fs      The flag set into which the flags will be registered.
+//   - listen  The default listen address (e.g., ":50051").
+//   - opts    Options of the registrar (see WithDefaults and WithPrefix).
+//
+// Returns:
+//
+//	A closure that, when invoked, returns a populated *GrpcServerConfig
+//	containing the values from the parsed flags, checked by Validate, or
+//	an error joining every violation, also reported by ParseOrExit.
+func RegisterGrpcServerFlags(
+	fs *flag.FlagSet,
+	listen string,
+	opts ...RegisterOption,
+) func() (*GrpcServerConfig, error) {
+	defer bindEnv(fs, flagNames(fs))
+	defer groupFlags(fs, GroupGrpc, flagNames(fs))
+	defer applyOptions(fs, flagNames(fs), opts)
+
+	prefix := optionsPrefix(opts)
+
+	grpcListen := HostPort(fs, prefix+"grpc-listen", listen, "gRPC listen `address` (host:port or unix:///path)", AllowUnixSocket())
+	grpcServerTLSCertFile := fs.String(prefix+"grpc-server-tls-cert", "", "PEM gRPC server certificate (empty serves plaintext)")
+	grpcServerTLSKeyFile := fs.String(prefix+"grpc-server-tls-key", "", "PEM private key of the gRPC server certificate")
+	grpcServerTLSClientCAFile := fs.String(prefix+"grpc-server-tls-client-ca", "", "PEM bundle of the CAs signing gRPC client certificates (set to require mutual TLS)")
+	grpcServerMaxConcurrentStreams := fs.Int(prefix+"grpc-server-max-concurrent-streams", 0, "Maximum concurrent gRPC streams per client connection (0 leaves it unlimited)")
+	grpcServerMaxMsgSize := Bytes(fs, prefix+"grpc-server-max-msg-size", 4*MiB, "Maximum `size` of received and sent gRPC messages (e.g., 16MiB)")
+	grpcServerShutdownTimeout := Duration(fs, prefix+"grpc-server-shutdown-timeout", 10*time.Second, "Max `time` given to running gRPC calls on shutdown", MinDuration(0))
+	grpcServerAllowedCIDRs := CIDR(fs, prefix+"grpc-server-allowed-cidrs", nil, "Client `networks` allowed to connect to the gRPC server over TCP, repeatable (empty allows all)")
+
+	return checked(fs, func() (*GrpcServerConfig, error) {
+		cfg := &GrpcServerConfig{
+			GrpcListen:                     *grpcListen,
+			GrpcServerTLSCertFile:          *grpcServerTLSCertFile,
+			GrpcServerTLSKeyFile:           *grpcServerTLSKeyFile,
+			GrpcServerTLSClientCAFile:      *grpcServerTLSClientCAFile,
+			GrpcServerMaxConcurrentStreams: *grpcServerMaxConcurrentStreams,
+			GrpcServerMaxMsgSize:           *grpcServerMaxMsgSize,
+			GrpcServerShutdownTimeout:      *grpcServerShutdownTimeout,
+			GrpcServerAllowedCIDRs:         *grpcServerAllowedCIDRs,
+		}
+		if err := validateConfig(cfg, prefix); err != nil {
+			return nil, err
+		}
+		recordSources(fs, cfg, prefix)
+		return cfg, nil
+	})
+}
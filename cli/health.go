@@ -0,0 +1,59 @@
+package gocli
+
+import "flag"
+
+// HealthConfig holds configuration options for the liveness and readiness
+// endpoints, consumed by gometrics.NewHealthServer.
+type HealthConfig struct {
+	HealthListen string `log:"health-listen"`                   // Listen address of the HTTP server (e.g., ":8081"); empty disables the endpoints
+	HealthPath   string `log:"health-path" validate:"required"` // URL path prefix of the endpoints, served at <path>/live and <path>/ready (e.g., "/health")
+}
+
+// RegisterHealthFlags registers command-line flags for configuring the
+// liveness and readiness endpoints probed by Kubernetes.
+//
+// Registered flags:
+//
+//	--health-listen  string   Listen address of the health HTTP server, empty disables it (default "<listen>")
+//	--health-path    string   URL path prefix of the liveness (<path>/live) and readiness (<path>/ready) endpoints (default "/health")
+//
+// A flag not given on the command line takes the value of its environment
+// variable if set (see EnvVarName and SetEnvPrefix), e.g. KUBENSAGE_HEALTH_LISTEN;
+// the precedence is flag > environment > default.
+//
+// Parameters:
+//   - fs      The flag set into which the flags will be registered.
+//   - listen  The default listen address (e.g., ":8081").
+//   - opts    Options of the registrar (see WithDefaults and WithPrefix).
+//
+// Returns:
+//
+//	A closure that, when invoked, returns a populated *HealthConfig
+//	containing the values from the parsed flags, checked by Validate, or
+//	an error joining every violation, also reported by ParseOrExit.
+func RegisterHealthFlags(
+	fs *flag.FlagSet,
+	listen string,
+	opts ...RegisterOption,
+) func() (*HealthConfig, error) {
+	defer bindEnv(fs, flagNames(fs))
+	defer groupFlags(fs, GroupHealth, flagNames(fs))
+	defer applyOptions(fs, flagNames(fs), opts)
+
+	prefix := optionsPrefix(opts)
+
+	healthListen := HostPort(fs, prefix+"health-listen", listen, "Listen `address` of the health HTTP server (empty disables)")
+	healthPath := fs.String(prefix+"health-path", "/health", "URL `path` prefix of the liveness (<path>/live) and readiness (<path>/ready) endpoints")
+
+	return checked(fs, func() (*HealthConfig, error) {
+		cfg := &HealthConfig{
+			HealthListen: *healthListen,
+			HealthPath:   *healthPath,
+		}
+		if err := validateConfig(cfg, prefix); err != nil {
+			return nil, err
+		}
+		recordSources(fs, cfg, prefix)
+		return cfg, nil
+	})
+}
@@ -0,0 +1,45 @@
+package gocli
+
+import "flag"
+
+// validLogLevels lists the levels accepted by the log level flags, from the
+// most to the least verbose.
+var validLogLevels = []string{"debug", "info", "warn", "error", "dpanic", "panic", "fatal"}
+
+// ValidLogLevels returns the levels accepted by the log level flags (e.g.,
+// --log-level), from the most to the least verbose.
+//
+// Returns:
+//
+//	A new slice holding the level names; callers may modify it.
+func ValidLogLevels() []string {
+	return append([]string(nil), validLogLevels...)
+}
+
+// logLevelVar defines a log level flag validated when the command line is parsed
+// (see Enum), so that a typo is reported with the list of valid levels instead
+// of failing when the logger is built.
+//
+// Parameters:
+//   - fs          The flag set into which the flag is registered.
+//   - name        The flag name.
+//   - value       The default value.
+//   - usage       The flag help, without the valid levels.
+//   - allowEmpty  Whether the empty string is accepted (e.g., for overrides).
+//
+// Returns:
+//
+//	A pointer to the flag value.
+func logLevelVar(
+	fs *flag.FlagSet,
+	name string,
+	value string,
+	usage string,
+	allowEmpty bool,
+) *string {
+	allowed := validLogLevels
+	if allowEmpty {
+		allowed = append([]string{""}, validLogLevels...)
+	}
+	return Enum(fs, name, allowed, value, usage)
+}
@@ -0,0 +1,63 @@
+package gocli
+
+import "flag"
+
+// MetricsConfig holds configuration options for the Prometheus scrape
+// endpoint, consumed by gometrics.NewServer.
+type MetricsConfig struct {
+	MetricsEnabled bool   `log:"metrics-enabled"`                    // Whether the scrape endpoint is served
+	MetricsListen  string `log:"metrics-listen" validate:"required"` // Listen address of the HTTP server (e.g., ":9090")
+	MetricsPath    string `log:"metrics-path" validate:"required"`   // URL path of the scrape endpoint (e.g., "/metrics")
+}
+
+// RegisterMetricsFlags registers command-line flags for configuring the
+// Prometheus scrape endpoint.
+//
+// Registered flags:
+//
+//	--metrics-enabled  bool     Serve the Prometheus scrape endpoint (default true)
+//	--metrics-listen   string   Listen address of the metrics HTTP server (default "<listen>")
+//	--metrics-path     string   URL path of the scrape endpoint (default "/metrics")
+//
+// A flag not given on the command line takes the value of its environment
+// variable if set (see EnvVarName and SetEnvPrefix), e.g. KUBENSAGE_METRICS_LISTEN;
+// the precedence is flag > environment > default.
+//
+// Parameters:
+//   - fs      The flag set into which the flags will be registered.
+//   - listen  The default listen address (e.g., ":9090").
+//   - opts    Options of the registrar (see WithDefaults and WithPrefix).
+//
+// Returns:
+//
+//	A closure that, when invoked, returns a populated *MetricsConfig
+//	containing the values from the parsed flags, checked by Validate, or
+//	an error joining every violation, also reported by ParseOrExit.
+func RegisterMetricsFlags(
+	fs *flag.FlagSet,
+	listen string,
+	opts ...RegisterOption,
+) func() (*MetricsConfig, error) {
+	defer bindEnv(fs, flagNames(fs))
+	defer groupFlags(fs, GroupMetrics, flagNames(fs))
+	defer applyOptions(fs, flagNames(fs), opts)
+
+	prefix := optionsPrefix(opts)
+
+	metricsEnabled := fs.Bool(prefix+"metrics-enabled", true, "Serve the Prometheus scrape endpoint")
+	metricsListen := fs.String(prefix+"metrics-listen", listen, "Listen `address` of the metrics HTTP server")
+	metricsPath := fs.String(prefix+"metrics-path", "/metrics", "URL `path` of the Prometheus scrape endpoint")
+
+	return checked(fs, func() (*MetricsConfig, error) {
+		cfg := &MetricsConfig{
+			MetricsEnabled: *metricsEnabled,
+			MetricsListen:  *metricsListen,
+			MetricsPath:    *metricsPath,
+		}
+		if err := validateConfig(cfg, prefix); err != nil {
+			return nil, err
+		}
+		recordSources(fs, cfg, prefix)
+		return cfg, nil
+	})
+}
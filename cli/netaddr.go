@@ -0,0 +1,295 @@
+package gocli
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// ipFlag is a flag.Value holding an IP address, IPv4-mapped IPv6 addresses
+// being stored as IPv4 (e.g., "::ffff:10.0.0.1" as "10.0.0.1").
+type ipFlag struct {
+	value *netip.Addr
+}
+
+// IP defines an IP address flag, IPv4 or IPv6 with an optional zone (e.g.,
+// "10.0.0.1", "fe80::1%eth0"), with the behavior of the gocli registrars: it
+// falls back to its environment variable (see EnvVarName) when not given on
+// the command line, and invalid addresses are rejected when the command line
+// is parsed. An empty value leaves the address unset (the zero netip.Addr).
+//
+// Parameters:
+//   - fs     The flag set into which the flag is registered.
+//   - name   The flag name.
+//   - value  The default address, or the zero netip.Addr for none.
+//   - usage  The flag help.
+//
+// Returns:
+//
+//	A pointer to the flag value.
+//
+// Example:
+//
+//	advertise := gocli.IP(fs, "advertise-ip", netip.Addr{}, "IP `address` advertised to the relay (empty uses the pod IP)")
+func IP(
+	fs *flag.FlagSet,
+	name string,
+	value netip.Addr,
+	usage string,
+) *netip.Addr {
+	defer bindEnv(fs, flagNames(fs))
+
+	p := new(netip.Addr)
+	*p = value.Unmap()
+	fs.Var(&ipFlag{value: p}, name, usage)
+	return p
+}
+
+// String returns the address, or the empty string if it is unset.
+func (f *ipFlag) String() string {
+	if f.value == nil || !f.value.IsValid() {
+		return ""
+	}
+	return f.value.String()
+}
+
+// Set parses an IP address.
+func (f *ipFlag) Set(
+	s string,
+) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		*f.value = netip.Addr{}
+		return nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return fmt.Errorf("invalid IP address %q (e.g., 10.0.0.1, fd00::1)", s)
+	}
+	*f.value = addr.Unmap()
+	return nil
+}
+
+// cidrFlag is a flag.Value collecting CIDR prefixes from comma-separated
+// values and repeated occurrences, like stringSliceFlag.
+type cidrFlag struct {
+	values  *[]netip.Prefix
+	replace bool // whether the next Set replaces the values instead of appending
+}
+
+// CIDR defines a flag holding a list of CIDR prefixes, e.g., an allow-list of
+// client networks, given as comma-separated values, repeated occurrences, or
+// both (e.g., "--allow 10.0.0.0/8,192.168.0.0/16 --allow fd00::/8"). It
+// behaves like StringSlice, except that invalid prefixes are rejected when the
+// command line is parsed.
+//
+// Prefixes are normalized: host bits are cleared (e.g., "10.1.2.3/8" is
+// stored as "10.0.0.0/8"), and a bare address stands for itself alone (e.g.,
+// "10.0.0.1" as "10.0.0.1/32").
+//
+// Parameters:
+//   - fs     The flag set into which the flag is registered.
+//   - name   The flag name.
+//   - value  The default prefixes, replaced by the first value given.
+//   - usage  The flag help.
+//
+// Returns:
+//
+//	A pointer to the flag values.
+//
+// Example:
+//
+//	allowed := gocli.CIDR(fs, "admin-allowed-cidrs", nil, "Client `networks` allowed to connect, repeatable (empty allows all)")
+func CIDR(
+	fs *flag.FlagSet,
+	name string,
+	value []netip.Prefix,
+	usage string,
+) *[]netip.Prefix {
+	defer bindEnv(fs, flagNames(fs))
+
+	p := new([]netip.Prefix)
+	for _, prefix := range value {
+		*p = append(*p, prefix.Masked())
+	}
+	fs.Var(&cidrFlag{values: p, replace: true}, name, usage)
+	return p
+}
+
+// String returns the prefixes joined by commas.
+func (f *cidrFlag) String() string {
+	if f.values == nil {
+		return ""
+	}
+	items := make([]string, len(*f.values))
+	for i, prefix := range *f.values {
+		items[i] = prefix.String()
+	}
+	return strings.Join(items, ",")
+}
+
+// Set adds comma-separated prefixes, replacing the current ones on the first
+// call.
+func (f *cidrFlag) Set(
+	s string,
+) error {
+	var values []netip.Prefix
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		prefix, err := parseCIDR(item)
+		if err != nil {
+			return err
+		}
+		values = append(values, prefix)
+	}
+
+	if f.replace {
+		*f.values = values
+		f.replace = false
+	} else {
+		*f.values = append(*f.values, values...)
+	}
+	return nil
+}
+
+// bindDefault makes the values set from the environment a default, replaced by
+// the command line.
+func (f *cidrFlag) bindDefault() {
+	f.replace = true
+}
+
+// parseCIDR parses a CIDR prefix, or a bare address standing for itself, and
+// clears its host bits.
+func parseCIDR(
+	s string,
+) (netip.Prefix, error) {
+	if !strings.Contains(s, "/") {
+		addr, err := netip.ParseAddr(s)
+		if err != nil || addr.Zone() != "" {
+			return netip.Prefix{}, fmt.Errorf("invalid CIDR %q (e.g., 10.0.0.0/8, fd00::/8)", s)
+		}
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid CIDR %q (e.g., 10.0.0.0/8, fd00::/8)", s)
+	}
+	return prefix.Masked(), nil
+}
+
+// HostPortOption widens the values accepted by a HostPort flag.
+type HostPortOption func(*hostPortFlag)
+
+// AllowUnixSocket accepts unix:///path addresses, naming a Unix domain socket,
+// besides host:port ones.
+func AllowUnixSocket() HostPortOption {
+	return func(f *hostPortFlag) {
+		f.unix = true
+	}
+}
+
+// hostPortFlag is a flag.Value holding a host:port address, normalized by
+// net.JoinHostPort.
+type hostPortFlag struct {
+	value *string
+	unix  bool // whether unix:///path addresses are accepted
+}
+
+// HostPort defines a host:port address flag, e.g., a listen or dial address,
+// with the behavior of the gocli registrars: it falls back to its environment
+// variable (see EnvVarName) when not given on the command line, and invalid
+// addresses are rejected when the command line is parsed.
+//
+// The host is a name, an IP address (IPv6 in brackets, e.g., "[::1]:50051"),
+// or empty for every interface (e.g., ":9090"); the port is a number between 0
+// and 65535. Values are normalized (e.g., "LOCALHOST:0080" is stored as
+// "localhost:80", "[::ffff:127.0.0.1]:80" as "127.0.0.1:80"). An empty value
+// leaves the address unset.
+//
+// Parameters:
+//   - fs     The flag set into which the flag is registered.
+//   - name   The flag name.
+//   - value  The default address; it is not normalized.
+//   - usage  The flag help.
+//   - opts   Other accepted addresses (see AllowUnixSocket).
+//
+// Returns:
+//
+//	A pointer to the flag value.
+//
+// Example:
+//
+//	listen := gocli.HostPort(fs, "admin-listen", ":8081", "Admin HTTP listen `address`")
+func HostPort(
+	fs *flag.FlagSet,
+	name string,
+	value string,
+	usage string,
+	opts ...HostPortOption,
+) *string {
+	defer bindEnv(fs, flagNames(fs))
+
+	p := new(string)
+	*p = value
+	f := &hostPortFlag{value: p}
+	for _, opt := range opts {
+		opt(f)
+	}
+	fs.Var(f, name, usage)
+	return p
+}
+
+// String returns the address.
+func (f *hostPortFlag) String() string {
+	if f.value == nil {
+		return ""
+	}
+	return *f.value
+}
+
+// Set parses and normalizes an address.
+func (f *hostPortFlag) Set(
+	s string,
+) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		*f.value = ""
+		return nil
+	}
+	if path, ok := strings.CutPrefix(s, "unix://"); ok {
+		if !f.unix {
+			return fmt.Errorf("invalid address %q: Unix domain sockets are not accepted", s)
+		}
+		if path == "" {
+			return fmt.Errorf("invalid address %q: missing socket path", s)
+		}
+		*f.value = s
+		return nil
+	}
+
+	example := "e.g., :50051, relay:50051, [::1]:50051"
+	if f.unix {
+		example += ", unix:///run/app.sock"
+	}
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		return fmt.Errorf("invalid address %q (%s)", s, example)
+	}
+	n, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return fmt.Errorf("invalid port %q in address %q (0 to 65535)", port, s)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		host = addr.Unmap().String()
+	} else if host = strings.ToLower(host); strings.ContainsAny(host, " /[]") {
+		return fmt.Errorf("invalid host %q in address %q (%s)", host, s, example)
+	}
+	*f.value = net.JoinHostPort(host, strconv.FormatUint(n, 10))
+	return nil
+}
@@ -0,0 +1,284 @@
+package gocli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Exit codes of ParseOrExit, shared by the kubensage binaries.
+const (
+	ExitUsage      = 2 // Invalid command line: unknown flag, invalid flag value or positional argument
+	ExitValidation = 3 // Invalid configuration: a check added with AddCheck failed
+
+	ExitCheckFailed = 1 // A check or preflight check failed in --check-config mode (see RegisterCheckConfigFlag)
+)
+
+var (
+	checksMu sync.Mutex
+	checks   = make(map[*flag.FlagSet][]func() error) // checks run by ParseOrExit, by flag set
+
+	valuesMu sync.Mutex
+	values   = make(map[*flag.FlagSet]*sync.RWMutex) // guards of the flag values reapplied by a Watcher, by flag set
+)
+
+// ParseLenient parses the command line like fs.Parse, except that flags not
+// defined in fs are skipped with a warning on the output of fs instead of
+// failing, e.g., "warning: ignoring unknown flag --log-new-option". This eases
+// rolling upgrades, where newer manifests pass flags that older binaries do
+// not know yet.
+//
+// An unknown flag given without "=" is assumed to take the next argument as
+// its value unless that argument starts with a dash, so unknown boolean flags
+// followed by positional arguments must be written --name=true to be skipped
+// cleanly. As with fs.Parse, flags end at the first positional argument or at
+// "--".
+//
+// Parameters:
+//   - fs    The flag set to parse into.
+//   - args  The command-line arguments, without the program name (e.g., os.Args[1:]).
+//
+// Returns:
+//
+//	The names of the skipped flags, and the error of fs.Parse for the other
+//	arguments (e.g., an invalid value), handled according to the error
+//	handling of fs.
+//
+// Example:
+//
+//	if _, err := gocli.ParseLenient(flag.CommandLine, os.Args[1:]); err != nil {
+//		os.Exit(2)
+//	}
+func ParseLenient(
+	fs *flag.FlagSet,
+	args []string,
+) ([]string, error) {
+	var unknown []string
+	kept := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if len(arg) < 2 || arg[0] != '-' || arg == "--" {
+			kept = append(kept, args[i:]...) // positional arguments follow
+			break
+		}
+
+		name := strings.TrimPrefix(arg[1:], "-")
+		name, _, hasValue := strings.Cut(name, "=")
+		if name == "" || name[0] == '-' || name[0] == '=' {
+			kept = append(kept, arg) // malformed, reported by fs.Parse
+			continue
+		}
+
+		if f := fs.Lookup(name); f != nil || name == "h" || name == "help" {
+			kept = append(kept, arg)
+			if !hasValue && f != nil && !isBoolFlag(f) && i+1 < len(args) {
+				i++
+				kept = append(kept, args[i])
+			}
+			continue
+		}
+
+		unknown = append(unknown, name)
+		_, _ = fmt.Fprintf(fs.Output(), "warning: ignoring unknown flag --%s\n", name)
+		if !hasValue && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			i++ // its value
+		}
+	}
+
+	return unknown, fs.Parse(kept)
+}
+
+// isBoolFlag reports whether a flag may be given without a value.
+func isBoolFlag(
+	f *flag.Flag,
+) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// AddCheck adds a check run by ParseOrExit once fs is parsed, e.g., a call to
+// Required. Checks run in the order they were added, after the configuration
+// file (see RegisterConfigFileFlag) is applied. The closures of the gocli
+// registrars are added as checks when they are registered, so an invalid
+// configuration (e.g., --grpc-tls-cert without --grpc-tls-key) is reported
+// right after parsing.
+//
+// Parameters:
+//   - fs     The flag set whose configuration is checked.
+//   - check  The check, returning an error, possibly joining several, if the
+//     configuration is invalid.
+//
+// Example:
+//
+//	gocli.AddCheck(fs, func() error { return gocli.Required(fs, "node-name") })
+func AddCheck(
+	fs *flag.FlagSet,
+	check func() error,
+) {
+	checksMu.Lock()
+	defer checksMu.Unlock()
+	checks[fs] = append(checks[fs], check)
+}
+
+// prependCheck adds a check run by ParseOrExit before the others, e.g., the
+// loading of the configuration file, on which the other checks depend.
+func prependCheck(
+	fs *flag.FlagSet,
+	check func() error,
+) {
+	checksMu.Lock()
+	defer checksMu.Unlock()
+	checks[fs] = append([]func() error{check}, checks[fs]...)
+}
+
+// checked adds the closure of a registrar as a check of fs (see AddCheck), and
+// returns it. Registrars wrap their closure with it:
+//
+//	return checked(fs, func() (*XConfig, error) { ... })
+//
+// The returned closure reads the flags under the read lock of valuesLock, so
+// that it does not race with a configuration file reapplied by a Watcher.
+func checked[T any](
+	fs *flag.FlagSet,
+	load func() (*T, error),
+) func() (*T, error) {
+	locked := func() (*T, error) {
+		mu := valuesLock(fs)
+		mu.RLock()
+		defer mu.RUnlock()
+		return load()
+	}
+	AddCheck(fs, func() error {
+		_, err := locked()
+		return err
+	})
+	return locked
+}
+
+// valuesLock returns the lock guarding the values of the flags of fs: the
+// configuration file is applied to them under the write lock, and the closures
+// of the registrars read them under the read lock.
+func valuesLock(
+	fs *flag.FlagSet,
+) *sync.RWMutex {
+	valuesMu.Lock()
+	defer valuesMu.Unlock()
+	mu, ok := values[fs]
+	if !ok {
+		mu = new(sync.RWMutex)
+		values[fs] = mu
+	}
+	return mu
+}
+
+// ParseOrExit parses the command line into fs and exits the process if it is
+// invalid, with the conventions shared by the kubensage binaries:
+//
+//   - -h and --help print the usage and exit with status 0;
+//   - an invalid flag or positional argument (see PositionalArg) prints the
+//     error and the usage, and exits with ExitUsage (2);
+//   - a failed check (see AddCheck), including an invalid configuration of a
+//     gocli registrar, prints every violation, and exits with ExitValidation
+//     (3);
+//   - with --check-config (see RegisterCheckConfigFlag), the checks and the
+//     preflight checks are reported, and the process exits with 0 if they
+//     passed, else with ExitCheckFailed (1).
+//
+// Errors are logged as structured entries by logger, or printed on the output
+// of fs one per line if logger is nil, as the logger of a binary usually
+// depends on the flags being parsed.
+//
+// Parameters:
+//   - fs      The flag set to parse into.
+//   - args    The command-line arguments, without the program name (e.g., os.Args[1:]).
+//   - logger  The logger reporting errors, or nil.
+//
+// Example:
+//
+//	logConfig := gocli.RegisterLogStdFlags(flag.CommandLine)
+//	gocli.ParseOrExit(flag.CommandLine, os.Args[1:], nil)
+//	cfg, _ := logConfig() // valid, checked by ParseOrExit
+func ParseOrExit(
+	fs *flag.FlagSet,
+	args []string,
+	logger *zap.Logger,
+) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		if logger != nil { // fs.Parse printed the error and the usage
+			logger.Error("invalid command line", zap.Error(err))
+		}
+		os.Exit(ExitUsage)
+	}
+	if hasArgs(fs) {
+		if _, err := ParseArgs(fs); err != nil {
+			reportErrors(fs, logger, "invalid command line", err)
+			fs.Usage()
+			os.Exit(ExitUsage)
+		}
+	}
+
+	checksMu.Lock()
+	pending := append([]func() error(nil), checks[fs]...)
+	checksMu.Unlock()
+
+	var errs []error
+	for _, check := range pending {
+		if err := check(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if checkConfigEnabled(fs) {
+		os.Exit(runCheckConfig(fs, logger, errs))
+	}
+	if err := errors.Join(errs...); err != nil {
+		reportErrors(fs, logger, "invalid configuration", err)
+		os.Exit(ExitValidation)
+	}
+}
+
+// reportErrors logs an error, one entry field per joined error, or prints it
+// on the output of fs if logger is nil.
+func reportErrors(
+	fs *flag.FlagSet,
+	logger *zap.Logger,
+	msg string,
+	err error,
+) {
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = flattenErrors(joined.Unwrap())
+	}
+
+	if logger != nil {
+		logger.Error(msg, zap.Errors("errors", errs))
+		return
+	}
+	_, _ = fmt.Fprintf(fs.Output(), "%s:\n", msg)
+	for _, err := range errs {
+		_, _ = fmt.Fprintf(fs.Output(), "  %v\n", err)
+	}
+}
+
+// flattenErrors returns the errors of errs, replacing those joining several
+// errors (see errors.Join) with the errors they join.
+func flattenErrors(
+	errs []error,
+) []error {
+	var flat []error
+	for _, err := range errs {
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			flat = append(flat, flattenErrors(joined.Unwrap())...)
+			continue
+		}
+		flat = append(flat, err)
+	}
+	return flat
+}
@@ -0,0 +1,60 @@
+package gocli
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// DefaultLogFile returns the default log file path of an application on the
+// current platform:
+//
+//   - Windows: %ProgramData%\kubensage\logs\<appName>.log
+//   - other platforms, running as root: /var/log/kubensage/<appName>.log
+//   - other platforms, unprivileged (e.g., rootless containers, developer
+//     machines): <user cache dir>/kubensage/logs/<appName>.log
+//
+// If the relevant base directory cannot be determined, the path falls back to
+// the system temporary directory.
+//
+// Parameters:
+//   - appName  The name of the application (used as the file name).
+//
+// Returns:
+//
+//	The absolute path of the default log file.
+func DefaultLogFile(
+	appName string,
+) string {
+	fileName := appName + ".log"
+
+	if runtime.GOOS == "windows" {
+		if programData := os.Getenv("ProgramData"); programData != "" {
+			return filepath.Join(programData, "kubensage", "logs", fileName)
+		}
+		return filepath.Join(os.TempDir(), "kubensage", "logs", fileName)
+	}
+
+	if os.Geteuid() == 0 {
+		return filepath.Join("/var/log/kubensage", fileName)
+	}
+	if cacheDir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(cacheDir, "kubensage", "logs", fileName)
+	}
+	return filepath.Join(os.TempDir(), "kubensage", "logs", fileName)
+}
+
+// DefaultAuditFile returns the default audit log file path of an application: a
+// file named <appName>-audit.log next to DefaultLogFile.
+//
+// Parameters:
+//   - appName  The name of the application (used in the file name).
+//
+// Returns:
+//
+//	The absolute path of the default audit log file.
+func DefaultAuditFile(
+	appName string,
+) string {
+	return filepath.Join(filepath.Dir(DefaultLogFile(appName)), appName+"-audit.log")
+}
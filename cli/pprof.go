@@ -0,0 +1,57 @@
+package gocli
+
+import "flag"
+
+// PprofConfig holds configuration options for the pprof profiling endpoint,
+// consumed by gometrics.NewPprofServer.
+type PprofConfig struct {
+	PprofListen string `log:"pprof-listen"` // Listen address of the pprof HTTP server (e.g., "127.0.0.1:6060"); empty disables profiling
+}
+
+// RegisterProfilingFlags registers command-line flags for configuring the
+// opt-in pprof profiling endpoint.
+//
+// Registered flags:
+//
+//	--pprof-listen  string   Listen address of the pprof HTTP server, empty disables it (default "")
+//
+// The endpoint exposes the memory and stacks of the process without
+// authentication: it should listen on a loopback address and be reached
+// through a port forward (e.g., kubectl port-forward).
+//
+// A flag not given on the command line takes the value of its environment
+// variable if set (see EnvVarName and SetEnvPrefix), e.g. KUBENSAGE_PPROF_LISTEN;
+// the precedence is flag > environment > default.
+//
+// Parameters:
+//   - fs    The flag set into which the flags will be registered.
+//   - opts  Options of the registrar (see WithDefaults and WithPrefix).
+//
+// Returns:
+//
+//	A closure that, when invoked, returns a populated *PprofConfig
+//	containing the values from the parsed flags, checked by Validate, or
+//	an error joining every violation, also reported by ParseOrExit.
+func RegisterProfilingFlags(
+	fs *flag.FlagSet,
+	opts ...RegisterOption,
+) func() (*PprofConfig, error) {
+	defer bindEnv(fs, flagNames(fs))
+	defer groupFlags(fs, GroupProfiling, flagNames(fs))
+	defer applyOptions(fs, flagNames(fs), opts)
+
+	prefix := optionsPrefix(opts)
+
+	pprofListen := fs.String(prefix+"pprof-listen", "", "Listen `address` of the pprof HTTP server, e.g., 127.0.0.1:6060 (empty disables)")
+
+	return checked(fs, func() (*PprofConfig, error) {
+		cfg := &PprofConfig{
+			PprofListen: *pprofListen,
+		}
+		if err := validateConfig(cfg, prefix); err != nil {
+			return nil, err
+		}
+		recordSources(fs, cfg, prefix)
+		return cfg, nil
+	})
+}
@@ -0,0 +1,273 @@
+package gocli
+
+import (
+	"flag"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Built-in profiles of the --profile flag (see RegisterProfileFlag).
+const (
+	ProfileDev  = "dev"  // Local development: verbose, human-readable logs, small buffer, fast failures
+	ProfileProd = "prod" // Production clusters: sampled JSON logs, stack traces on errors, keepalive pings
+	ProfileEdge = "edge" // Constrained nodes on unreliable links: quiet logs, small log files, patient gRPC connections
+)
+
+// profileNamePattern is the pattern of a valid profile name.
+var profileNamePattern = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+var (
+	profilesMu sync.RWMutex
+	profiles   = map[string]Defaults{ // presets of defaults, by profile name
+		ProfileDev: {
+			"log-level":            "debug",
+			"log-dev":              "true",
+			"log-encoder":          "console",
+			"log-caller":           "true",
+			"buffer-capacity":      "1000",
+			"grpc-connect-timeout": "5s",
+		},
+		ProfileProd: {
+			"log-level":               "info",
+			"log-encoder":             "json",
+			"log-sampling-initial":    "100",
+			"log-sampling-thereafter": "100",
+			"log-stacktrace-level":    "error",
+			"buffer-capacity":         "10000",
+			"grpc-keepalive-time":     "30s",
+			"grpc-keepalive-timeout":  "10s",
+		},
+		ProfileEdge: {
+			"log-level":                  "warn",
+			"log-encoder":                "json",
+			"log-sampling-initial":       "10",
+			"log-sampling-thereafter":    "1000",
+			"log-max-size":               "5MiB",
+			"log-max-backups":            "2",
+			"buffer-capacity":            "20000",
+			"buffer-overflow-policy":     "overwrite",
+			"grpc-connect-timeout":       "60s",
+			"grpc-keepalive-time":        "60s",
+			"grpc-keepalive-timeout":     "20s",
+			"grpc-retry-max-attempts":    "5",
+			"grpc-retry-max-backoff":     "30s",
+			"grpc-retry-initial-backoff": "1s",
+		},
+	}
+	profileFlags = make(map[*flag.FlagSet]*profileFlag) // values of the --profile flags, by flag set
+)
+
+// profileFlag is the flag.Value of --profile, replacing the defaults of the
+// flags of fs with the presets of the profile it is set to.
+type profileFlag struct {
+	fs       *flag.FlagSet
+	name     string
+	replaced map[string]string // defaults replaced by the profile, by flag name
+}
+
+// RegisterProfile registers a profile of the --profile flag (see
+// RegisterProfileFlag), e.g., for the deployments of an application that
+// the built-in profiles do not fit. It is typically called from an init
+// function, before the flag is registered so that the help lists it.
+//
+// It panics if name is invalid (lowercase letters, digits and dashes,
+// starting with a letter) or already registered, including the built-in
+// ProfileDev, ProfileProd and ProfileEdge, as these are programming errors.
+//
+// Parameters:
+//   - name      The profile name (e.g., "lab").
+//   - defaults  The preset default values by flag name, written as on the
+//     command line (e.g., "30s"); flags the binary does not register are
+//     ignored, and prefixed flags (see WithPrefix) are given with their
+//     prefix.
+//
+// Example:
+//
+//	func init() {
+//		gocli.RegisterProfile("lab", gocli.Defaults{
+//			"log-level":       "debug",
+//			"buffer-capacity": "500",
+//		})
+//	}
+func RegisterProfile(
+	name string,
+	defaults Defaults,
+) {
+	if !profileNamePattern.MatchString(name) {
+		panic(fmt.Sprintf("gocli: invalid profile name %q", name))
+	}
+
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	if _, ok := profiles[name]; ok {
+		panic(fmt.Sprintf("gocli: profile %q registered twice", name))
+	}
+	profiles[name] = maps.Clone(defaults)
+}
+
+// RegisterProfileFlag registers the --profile flag, applying a named preset
+// of defaults (e.g., log level, sampling, buffer capacity and keepalive
+// timings) suited to a kind of deployment, so that operators pick a profile
+// rather than tune every flag.
+//
+// Registered flags:
+//
+//	--profile  profile  Apply the preset defaults of a profile before the other flags (dev|edge|prod) (default "<profile>")
+//
+// The presets replace the defaults of the flags, including those given with
+// WithDefaults, and are shown in the help when the profile is set by the
+// default or the environment. Everything else still overrides them: the
+// precedence is flag > environment > file > profile > default, and Source
+// reports the presets as SourceDefault. Changing the profile, e.g., on the
+// command line after KUBENSAGE_PROFILE, restores the defaults replaced by
+// the previous one. The flag takes the value of its environment variable if
+// not given on the command line (see EnvVarName), e.g. KUBENSAGE_PROFILE,
+// and applies to the flags registered after it as well.
+//
+// The built-in profiles are ProfileDev, ProfileProd and ProfileEdge; others
+// are added with RegisterProfile.
+//
+// It panics if profile is neither empty nor a registered profile, as this is
+// a programming error.
+//
+// Parameters:
+//   - fs       The flag set into which the flag will be registered.
+//   - profile  The default profile (e.g., "prod"), or the empty string for
+//     none.
+//
+// Returns:
+//
+//	A closure returning the name of the applied profile, or the empty
+//	string if none is.
+//
+// Example:
+//
+//	profile := gocli.RegisterProfileFlag(flag.CommandLine, gocli.ProfileProd)
+//	logConfig := gocli.RegisterLogStdFlags(flag.CommandLine)
+//	gocli.ParseOrExit(flag.CommandLine, os.Args[1:], nil)
+//	fmt.Println(profile()) // "dev" with --profile dev
+func RegisterProfileFlag(
+	fs *flag.FlagSet,
+	profile string,
+) func() string {
+	defer bindEnv(fs, flagNames(fs))
+	defer groupFlags(fs, GroupGeneral, flagNames(fs))
+
+	p := &profileFlag{fs: fs, replaced: make(map[string]string)}
+	if err := p.Set(profile); err != nil {
+		panic(fmt.Sprintf("gocli: invalid default for -profile: %v", err))
+	}
+
+	profilesMu.Lock()
+	profileFlags[fs] = p
+	names := slices.Sorted(maps.Keys(profiles))
+	profilesMu.Unlock()
+
+	fs.Var(p, "profile", "Apply the preset defaults of a `profile` before the other flags ("+strings.Join(names, "|")+")")
+	return func() string {
+		return p.name
+	}
+}
+
+// String returns the name of the applied profile.
+func (p *profileFlag) String() string {
+	if p == nil {
+		return ""
+	}
+	return p.name
+}
+
+// Get returns the name of the applied profile, so that the default is quoted
+// in the usage like those of string flags.
+func (p *profileFlag) Get() any {
+	return p.String()
+}
+
+// Set applies a profile to the flags of fs not set yet, after restoring the
+// defaults replaced by the previous profile. The empty string applies none.
+func (p *profileFlag) Set(
+	name string,
+) error {
+	profilesMu.RLock()
+	defaults, ok := profiles[name]
+	names := slices.Sorted(maps.Keys(profiles))
+	profilesMu.RUnlock()
+	if !ok && name != "" {
+		return fmt.Errorf("unknown profile %q (expected %s)", name, strings.Join(names, "|"))
+	}
+
+	set := setFlags(p.fs)
+	for flagName, def := range p.replaced {
+		if _, ok := set[flagName]; !ok {
+			_ = setDefault(p.fs.Lookup(flagName), def)
+		}
+	}
+	clear(p.replaced)
+	p.name = name
+
+	for flagName := range defaults {
+		if f := p.fs.Lookup(flagName); f != nil {
+			if _, ok := set[flagName]; !ok {
+				if err := p.apply(f, defaults[flagName]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// apply replaces the default of a flag with the preset value of the profile,
+// recording the replaced default.
+func (p *profileFlag) apply(
+	f *flag.Flag,
+	value string,
+) error {
+	if _, ok := p.replaced[f.Name]; ok {
+		return nil // already applied, e.g., by the bindEnv of a flag type
+	}
+	def := f.DefValue
+	if err := setDefault(f, value); err != nil {
+		return fmt.Errorf("invalid value %q for -%s in profile %q: %w", value, f.Name, p.name, err)
+	}
+	p.replaced[f.Name] = def
+	return nil
+}
+
+// applyProfile applies the profile of the --profile flag of fs, if any, to a
+// flag registered after it. bindEnv calls it on the flags it binds, before
+// their environment variable.
+func applyProfile(
+	fs *flag.FlagSet,
+	f *flag.Flag,
+) {
+	profilesMu.RLock()
+	p := profileFlags[fs]
+	var value string
+	var ok bool
+	if p != nil {
+		value, ok = profiles[p.name][f.Name]
+	}
+	profilesMu.RUnlock()
+	if !ok {
+		return
+	}
+	if err := p.apply(f, value); err != nil {
+		panic(fmt.Sprintf("gocli: %v", err))
+	}
+}
+
+// setFlags returns the names of the flags of fs set since its creation.
+func setFlags(
+	fs *flag.FlagSet,
+) map[string]struct{} {
+	set := make(map[string]struct{})
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = struct{}{}
+	})
+	return set
+}
@@ -0,0 +1,110 @@
+package gocli
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+var (
+	interactiveMu sync.RWMutex
+	interactive   = make(map[*flag.FlagSet]*bool) // values of the --interactive flags, by flag set
+)
+
+// RegisterInteractiveFlag registers the --interactive flag, with which
+// Required prompts on the terminal for the required flags that are not set
+// instead of failing, e.g., for the debugging tools run by hand.
+//
+// Registered flags:
+//
+//	--interactive  bool  Prompt on the terminal for the missing required flags (default false)
+//
+// Prompts are only shown when the standard input is a terminal, so that the
+// flag has no effect in a container or a script, where Required still fails.
+// The value of a secret (see Secret) is read without echo. A value typed at
+// the prompt counts as given on the command line; an empty one leaves the
+// flag missing. The flag takes the value of its environment variable if not
+// given on the command line (see EnvVarName), e.g. KUBENSAGE_INTERACTIVE.
+//
+// Parameters:
+//   - fs  The flag set into which the flag will be registered.
+//
+// Example:
+//
+//	gocli.RegisterInteractiveFlag(fs)
+//	apiToken := gocli.Secret(fs, "api-token", "API `token`")
+//	gocli.AddCheck(fs, func() error { return gocli.Required(fs, "relay-address", "api-token") })
+//	gocli.ParseOrExit(fs, os.Args[1:], nil)
+func RegisterInteractiveFlag(
+	fs *flag.FlagSet,
+) {
+	defer bindEnv(fs, flagNames(fs))
+	defer groupFlags(fs, GroupGeneral, flagNames(fs))
+
+	enabled := fs.Bool("interactive", false, "Prompt on the terminal for the missing required flags")
+
+	interactiveMu.Lock()
+	defer interactiveMu.Unlock()
+	interactive[fs] = enabled
+}
+
+// promptEnabled reports whether the missing required flags of fs are prompted
+// for: --interactive is set and the standard input is a terminal.
+func promptEnabled(
+	fs *flag.FlagSet,
+) bool {
+	interactiveMu.RLock()
+	enabled := interactive[fs]
+	interactiveMu.RUnlock()
+
+	return enabled != nil && *enabled && term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// promptFlag prompts on the output of fs for the value of a flag, read from in,
+// or without echo from the terminal for a secret, until a valid value is
+// entered. It returns whether the flag was set: an empty value or the end of
+// the input leave it unset.
+func promptFlag(
+	fs *flag.FlagSet,
+	f *flag.Flag,
+	in *bufio.Reader,
+) bool {
+	out := fs.Output()
+	_, usage := flag.UnquoteUsage(f)
+	_, secret := f.Value.(*SecretString)
+
+	for {
+		_, _ = fmt.Fprintf(out, "--%s (%s): ", f.Name, usage)
+
+		var value string
+		if secret {
+			data, err := term.ReadPassword(int(os.Stdin.Fd()))
+			_, _ = fmt.Fprintln(out)
+			if err != nil {
+				return false
+			}
+			value = string(data)
+		} else {
+			line, err := in.ReadString('\n')
+			if err != nil && line == "" {
+				_, _ = fmt.Fprintln(out)
+				return false
+			}
+			value = strings.TrimRight(line, "\r\n")
+		}
+
+		if value == "" {
+			return false
+		}
+		if err := fs.Set(f.Name, value); err != nil {
+			_, _ = fmt.Fprintf(out, "invalid value for --%s: %v\n", f.Name, err)
+			continue
+		}
+		return true
+	}
+}
@@ -0,0 +1,82 @@
+package gocli
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Required reports the mandatory flags of fs that were not provided, all in one
+// error, so that a binary does not need to check each of them after parsing.
+//
+// A flag counts as provided when it was set on the command line, by its
+// environment variable (see EnvVarName) or by the configuration file (see
+// RegisterConfigFileFlag), even to its default value. Call it after fs.Parse
+// and after applying the configuration file.
+//
+// With --interactive (see RegisterInteractiveFlag) and a terminal on the
+// standard input, the missing flags are prompted for before being reported.
+//
+// Parameters:
+//   - fs     The parsed flag set.
+//   - names  The names of the mandatory flags, without leading dashes.
+//
+// Returns:
+//
+//	nil if every flag was provided, else an error listing the missing ones
+//	with their environment variable (e.g., "missing required flags:
+//	--log-elastic-url (or KUBENSAGE_LOG_ELASTIC_URL)"). Names that are not
+//	flags of fs are reported as well.
+//
+// Example:
+//
+//	flag.Parse()
+//	if err := gocli.Required(flag.CommandLine, "relay-address", "node-name"); err != nil {
+//		fmt.Fprintln(os.Stderr, err)
+//		flag.Usage()
+//		os.Exit(2)
+//	}
+func Required(
+	fs *flag.FlagSet,
+	names ...string,
+) error {
+	set := make(map[string]struct{})
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = struct{}{}
+	})
+
+	prompt := promptEnabled(fs)
+	var in *bufio.Reader
+	var missing []string
+	var errs []error
+	for _, name := range names {
+		if fs.Lookup(name) == nil {
+			errs = append(errs, fmt.Errorf("required flag --%s is not defined", name))
+			continue
+		}
+		if _, ok := set[name]; ok {
+			continue
+		}
+		if prompt {
+			if in == nil {
+				in = bufio.NewReader(os.Stdin)
+			}
+			if promptFlag(fs, fs.Lookup(name), in) {
+				continue
+			}
+		}
+		if env := EnvVarName(name); env != "" {
+			missing = append(missing, fmt.Sprintf("--%s (or %s)", name, env))
+		} else {
+			missing = append(missing, "--"+name)
+		}
+	}
+
+	if len(missing) > 0 {
+		errs = append([]error{fmt.Errorf("missing required flags: %s", strings.Join(missing, ", "))}, errs...)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,335 @@
+package gocli
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ScheduleSpec is the schedule of a periodic job, e.g., a report interval or
+// a cleanup job: either a fixed interval, written as a Go duration (e.g.,
+// "15m"), or a cron expression (e.g., "0 3 * * *"). It implements the
+// gogo.Schedule interface, so it can be passed to gogo.RunPeriodically.
+//
+// Cron expressions have the five standard fields, minute (0-59), hour
+// (0-23), day of month (1-31), month (1-12 or jan-dec) and day of week (0-7
+// or sun-sat, 0 and 7 being Sunday), each a "*", a value, a range (e.g.,
+// "1-5") or a comma-separated list of them, optionally followed by a step
+// (e.g., "*/15", "0-30/10"). As in cron, a time matches when both day fields
+// do, or either of them if neither is "*". The descriptors @yearly
+// (@annually), @monthly, @weekly, @daily (@midnight) and @hourly, and "@every
+// <duration>" are accepted too. Times are in the local time zone unless the
+// expression starts with CRON_TZ=<zone> (e.g., "CRON_TZ=UTC 0 3 * * *").
+//
+// The zero value never runs.
+type ScheduleSpec struct {
+	expr  string        // the schedule, normalized (e.g., "15m", "0 3 * * *")
+	every time.Duration // the interval, for durations
+	cron  *cronSchedule // the fields, for cron expressions
+}
+
+// cronSchedule holds the values matched by the fields of a cron expression,
+// as bit sets.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool // whether the day fields are "*"
+	loc                           *time.Location
+}
+
+// cronField describes a field of a cron expression.
+type cronField struct {
+	name     string
+	min, max int
+	names    []string // names of the values from min, if any
+}
+
+// cronFields are the fields of a cron expression, in order.
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// cronDescriptors maps the cron descriptors to their expression.
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseSchedule parses a schedule: a positive Go duration, or a cron
+// expression (see ScheduleSpec).
+//
+// Parameters:
+//   - s  The schedule (e.g., "1h30m", "*/5 * * * *", "@daily").
+//
+// Returns:
+//
+//	The schedule, or an error if s is malformed.
+func ParseSchedule(
+	s string,
+) (ScheduleSpec, error) {
+	expr := strings.Join(strings.Fields(s), " ")
+	if expr == "" {
+		return ScheduleSpec{}, fmt.Errorf("invalid schedule %q (e.g., 15m, */5 * * * *, @daily)", s)
+	}
+
+	if interval, ok := strings.CutPrefix(expr, "@every "); ok {
+		expr = interval
+	}
+	if !strings.ContainsAny(expr, " @") {
+		d, err := time.ParseDuration(expr)
+		if err != nil || d <= 0 {
+			return ScheduleSpec{}, fmt.Errorf("invalid schedule %q: not a positive duration nor a cron expression (e.g., 15m, */5 * * * *, @daily)", s)
+		}
+		return ScheduleSpec{expr: formatDuration(d), every: d}, nil
+	}
+
+	cron := &cronSchedule{loc: time.Local}
+	fields := strings.Fields(expr)
+	if zone, ok := strings.CutPrefix(fields[0], "CRON_TZ="); ok {
+		loc, err := ParseTimeZone(zone)
+		if err != nil {
+			return ScheduleSpec{}, fmt.Errorf("invalid schedule %q: %w", s, err)
+		}
+		cron.loc = loc
+		fields = fields[1:]
+	}
+	if len(fields) == 1 {
+		descriptor, ok := cronDescriptors[strings.ToLower(fields[0])]
+		if !ok {
+			return ScheduleSpec{}, fmt.Errorf("invalid schedule %q: unknown descriptor %q (e.g., @hourly, @daily)", s, fields[0])
+		}
+		fields = strings.Fields(descriptor)
+	}
+	if len(fields) != len(cronFields) {
+		return ScheduleSpec{}, fmt.Errorf("invalid schedule %q: a cron expression has 5 fields (minute hour day-of-month month day-of-week), got %d", s, len(fields))
+	}
+
+	sets := make([]uint64, len(fields))
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return ScheduleSpec{}, fmt.Errorf("invalid schedule %q: %w", s, err)
+		}
+		sets[i] = set
+	}
+	cron.minute, cron.hour, cron.dom, cron.month = sets[0], sets[1], sets[2], sets[3]
+	cron.dow = sets[4]
+	if cron.dow&(1<<7) != 0 {
+		cron.dow |= 1 // 7 is Sunday too
+	}
+	cron.domStar, cron.dowStar = fields[2] == "*", fields[4] == "*"
+
+	return ScheduleSpec{expr: expr, cron: cron}, nil
+}
+
+// parseCronField parses a field of a cron expression into the set of values
+// it matches.
+func parseCronField(
+	s string,
+	field cronField,
+) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(s, ",") {
+		spec, stepText, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s field %q", stepText, field.name, s)
+			}
+			step = n
+		}
+
+		lo, hi := field.min, field.max
+		if spec != "*" {
+			from, to, isRange := strings.Cut(spec, "-")
+			var err error
+			if lo, err = cronValue(from, field); err != nil {
+				return 0, fmt.Errorf("%w in %s field %q", err, field.name, s)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = cronValue(to, field); err != nil {
+					return 0, fmt.Errorf("%w in %s field %q", err, field.name, s)
+				}
+			} else if hasStep {
+				hi = field.max
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q in %s field %q", spec, field.name, s)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+// cronValue parses a value of a cron field, a number or a name.
+func cronValue(
+	s string,
+	field cronField,
+) (int, error) {
+	for i, name := range field.names {
+		if strings.EqualFold(s, name) {
+			return field.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < field.min || v > field.max {
+		return 0, fmt.Errorf("invalid value %q (%d-%d)", s, field.min, field.max)
+	}
+	return v, nil
+}
+
+// Schedule defines a flag holding a schedule, a Go duration or a cron
+// expression (see ScheduleSpec), with the behavior of the gocli registrars: it
+// falls back to its environment variable (see EnvVarName) when not given on
+// the command line, and invalid schedules are rejected when the command line
+// is parsed.
+//
+// It panics if value is not a valid schedule, as this is a programming error.
+//
+// Parameters:
+//   - fs     The flag set into which the flag is registered.
+//   - name   The flag name.
+//   - value  The default schedule (e.g., "1h", "0 3 * * *"), or empty for none.
+//   - usage  The flag help.
+//
+// Returns:
+//
+//	A pointer to the schedule.
+//
+// Example:
+//
+//	cleanup := gocli.Schedule(fs, "cleanup-schedule", "0 3 * * *", "Cleanup `schedule`, a duration or a cron expression")
+//	...
+//	gogo.RunPeriodically(ctx, *cleanup, removeExpiredFiles)
+func Schedule(
+	fs *flag.FlagSet,
+	name string,
+	value string,
+	usage string,
+) *ScheduleSpec {
+	defer bindEnv(fs, flagNames(fs))
+
+	p := new(ScheduleSpec)
+	if value != "" {
+		if err := p.Set(value); err != nil {
+			panic(fmt.Sprintf("gocli: invalid default for -%s: %v", name, err))
+		}
+	}
+	fs.Var(p, name, usage)
+	return p
+}
+
+// Next returns the first time of the schedule after a time: after plus the
+// interval for durations, else the first minute after it matching the cron
+// expression.
+//
+// Parameters:
+//   - after  The time after which the next run is due.
+//
+// Returns:
+//
+//	The time of the next run, or the zero time if there is none (the zero
+//	ScheduleSpec, or a cron expression matching no date, e.g., "0 0 30 2 *").
+func (s ScheduleSpec) Next(
+	after time.Time,
+) time.Time {
+	switch {
+	case s.every > 0:
+		return after.Add(s.every)
+	case s.cron != nil:
+		return s.cron.next(after)
+	default:
+		return time.Time{}
+	}
+}
+
+// next returns the first minute after a time matching the expression, looking
+// 5 years ahead at most.
+func (c *cronSchedule) next(
+	after time.Time,
+) time.Time {
+	t := after.In(c.loc).Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + 5
+	for t.Year() <= limit {
+		year, month, day := t.Date()
+		switch {
+		case c.month&(1<<uint(month)) == 0:
+			t = time.Date(year, month+1, 1, 0, 0, 0, 0, c.loc)
+		case !c.dayMatches(t):
+			t = time.Date(year, month, day+1, 0, 0, 0, 0, c.loc)
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(year, month, day, t.Hour()+1, 0, 0, 0, c.loc)
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches reports whether the day of a time matches the day fields.
+func (c *cronSchedule) dayMatches(
+	t time.Time,
+) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// IsZero reports whether the schedule is unset, i.e., never runs.
+func (s ScheduleSpec) IsZero() bool {
+	return s.expr == ""
+}
+
+// String returns the schedule, normalized (e.g., "1h30m", "0 3 * * *").
+func (s ScheduleSpec) String() string {
+	return s.expr
+}
+
+// Set parses a schedule, so that *ScheduleSpec can be used with fs.Var; an
+// empty value unsets it.
+func (s *ScheduleSpec) Set(
+	value string,
+) error {
+	if strings.TrimSpace(value) == "" {
+		*s = ScheduleSpec{}
+		return nil
+	}
+	spec, err := ParseSchedule(value)
+	if err != nil {
+		return err
+	}
+	*s = spec
+	return nil
+}
+
+// MarshalText returns the schedule, normalized.
+func (s ScheduleSpec) MarshalText() ([]byte, error) {
+	return []byte(s.expr), nil
+}
+
+// UnmarshalText parses a schedule, e.g., for LoadConfigFile.
+func (s *ScheduleSpec) UnmarshalText(
+	text []byte,
+) error {
+	return s.Set(string(text))
+}
@@ -0,0 +1,148 @@
+package gocli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// secretMask replaces a secret wherever it would be printed.
+const secretMask = "***"
+
+// SecretString holds a credential (e.g., an API token or a password) that must
+// not be printed: String, GoString, MarshalText and LogValue return "***", so
+// the value is masked in the help output, in fmt verbs, in JSON and in the
+// startup log of golog (which honors golog.LogValuer). Only Value reveals it.
+//
+// The zero value holds no secret and prints as the empty string.
+type SecretString struct {
+	value string
+}
+
+// Secret defines a flag holding a credential. The flag accepts:
+//
+//	literal          The secret itself (e.g., --api-token s3cr3t).
+//	@/path/to/file   The content of the file, without its trailing newline,
+//	                 e.g., a mounted Kubernetes secret.
+//	env:VARNAME      The value of the environment variable VARNAME.
+//
+// The indirections are resolved when the flag is set, so an unreadable file or
+// unset variable fails the parsing of the command line. A literal starting with
+// "@" or "env:" must be given through a file. Like the gocli registrars, the
+// flag falls back to its environment variable (see EnvVarName), whose value may
+// use the same indirections, and the configuration file (see
+// RegisterConfigFileFlag) may set it too.
+//
+// Parameters:
+//   - fs     The flag set into which the flag is registered.
+//   - name   The flag name.
+//   - usage  The flag help.
+//
+// Returns:
+//
+//	A pointer to the secret, empty until set.
+//
+// Example:
+//
+//	apiToken := gocli.Secret(fs, "api-token", "API `token` (literal, @file or env:VAR)")
+//	...
+//	req.Header.Set("Authorization", "Bearer "+apiToken.Value())
+func Secret(
+	fs *flag.FlagSet,
+	name string,
+	usage string,
+) *SecretString {
+	defer bindEnv(fs, flagNames(fs))
+
+	p := new(SecretString)
+	fs.Var(p, name, usage)
+	return p
+}
+
+// ResolveSecret resolves a secret given as a literal, @/path/to/file or
+// env:VARNAME (see Secret).
+//
+// Parameters:
+//   - s  The secret or its indirection.
+//
+// Returns:
+//
+//	The secret, or an error naming the file or variable that cannot be read;
+//	the error never holds the secret.
+func ResolveSecret(
+	s string,
+) (SecretString, error) {
+	if path, ok := strings.CutPrefix(s, "@"); ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			var pathErr *os.PathError
+			if errors.As(err, &pathErr) {
+				err = pathErr.Err
+			}
+			return SecretString{}, fmt.Errorf("failed to read secret file %s: %w", path, err)
+		}
+		return SecretString{value: strings.TrimRight(string(data), "\r\n")}, nil
+	}
+	if name, ok := strings.CutPrefix(s, "env:"); ok {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return SecretString{}, fmt.Errorf("secret environment variable %s is not set", name)
+		}
+		return SecretString{value: value}, nil
+	}
+	return SecretString{value: s}, nil
+}
+
+// Value returns the secret.
+func (s SecretString) Value() string {
+	return s.value
+}
+
+// IsSet reports whether the secret is not empty.
+func (s SecretString) IsSet() bool {
+	return s.value != ""
+}
+
+// String returns "***", or the empty string if the secret is empty.
+func (s SecretString) String() string {
+	if s.value == "" {
+		return ""
+	}
+	return secretMask
+}
+
+// GoString masks the secret in the %#v verb.
+func (s SecretString) GoString() string {
+	return fmt.Sprintf("gocli.SecretString(%q)", s.String())
+}
+
+// MarshalText masks the secret in encoders such as encoding/json.
+func (s SecretString) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// LogValue masks the secret in the startup log of golog.
+func (s SecretString) LogValue() any {
+	return s.String()
+}
+
+// Set resolves the secret from a flag value (see Secret).
+func (s *SecretString) Set(
+	value string,
+) error {
+	resolved, err := ResolveSecret(value)
+	if err != nil {
+		return err
+	}
+	*s = resolved
+	return nil
+}
+
+// UnmarshalText resolves the secret like Set, e.g., for LoadConfigFile.
+func (s *SecretString) UnmarshalText(
+	text []byte,
+) error {
+	return s.Set(string(text))
+}
@@ -0,0 +1,65 @@
+package gocli
+
+import "flag"
+
+// Service modes of the --service-mode flag, consumed by goservice.Run.
+const (
+	ServiceModeAuto    = "auto"    // Detect the service manager: Windows service control manager, then systemd
+	ServiceModeNone    = "none"    // Run as a plain process
+	ServiceModeSystemd = "systemd" // Notify systemd of the readiness and ping its watchdog (Type=notify units)
+	ServiceModeWindows = "windows" // Handle the requests of the Windows service control manager
+)
+
+// ServiceConfig holds configuration options for running as a service outside
+// Kubernetes, consumed by goservice.Run.
+type ServiceConfig struct {
+	ServiceMode     string `log:"service-mode" validate:"oneof=auto none systemd windows"` // Service manager integration: auto, none, systemd or windows
+	ServiceWatchdog bool   `log:"service-watchdog"`                                        // Whether the systemd watchdog is pinged when the unit sets WatchdogSec
+}
+
+// RegisterServiceFlags registers command-line flags for running as a systemd
+// or Windows service, for agents deployed outside Kubernetes.
+//
+// Registered flags:
+//
+//	--service-mode      string   Service manager integration, "auto", "none", "systemd" or "windows" (default "auto")
+//	--service-watchdog  bool     Ping the systemd watchdog when the unit sets WatchdogSec (default true)
+//
+// A flag not given on the command line takes the value of its environment
+// variable if set (see EnvVarName and SetEnvPrefix), e.g. KUBENSAGE_SERVICE_MODE;
+// the precedence is flag > environment > default.
+//
+// Parameters:
+//   - fs    The flag set into which the flags will be registered.
+//   - opts  Options of the registrar (see WithDefaults and WithPrefix).
+//
+// Returns:
+//
+//	A closure that, when invoked, returns a populated *ServiceConfig
+//	containing the values from the parsed flags, checked by Validate, or
+//	an error joining every violation, also reported by ParseOrExit.
+func RegisterServiceFlags(
+	fs *flag.FlagSet,
+	opts ...RegisterOption,
+) func() (*ServiceConfig, error) {
+	defer bindEnv(fs, flagNames(fs))
+	defer groupFlags(fs, GroupService, flagNames(fs))
+	defer applyOptions(fs, flagNames(fs), opts)
+
+	prefix := optionsPrefix(opts)
+
+	serviceMode := Enum(fs, prefix+"service-mode", []string{ServiceModeAuto, ServiceModeNone, ServiceModeSystemd, ServiceModeWindows}, ServiceModeAuto, "Service manager integration")
+	serviceWatchdog := fs.Bool(prefix+"service-watchdog", true, "Ping the systemd watchdog when the unit sets WatchdogSec")
+
+	return checked(fs, func() (*ServiceConfig, error) {
+		cfg := &ServiceConfig{
+			ServiceMode:     *serviceMode,
+			ServiceWatchdog: *serviceWatchdog,
+		}
+		if err := validateConfig(cfg, prefix); err != nil {
+			return nil, err
+		}
+		recordSources(fs, cfg, prefix)
+		return cfg, nil
+	})
+}
@@ -0,0 +1,86 @@
+package gocli
+
+import (
+	"flag"
+	"strings"
+)
+
+// stringSliceFlag is a flag.Value collecting strings from comma-separated values
+// and repeated occurrences. The first value given replaces the default; later
+// ones are appended.
+type stringSliceFlag struct {
+	values  *[]string
+	replace bool // whether the next Set replaces the values instead of appending
+}
+
+// StringSlice defines a flag holding a list of strings, given as
+// comma-separated values, repeated occurrences, or both (e.g., "--label a,b
+// --label c" yields [a b c]). Surrounding spaces and empty items are dropped.
+//
+// Like the gocli registrars, it falls back to its environment variable (see
+// EnvVarName) as a comma-separated list when not given on the command line;
+// values given on the command line then replace those of the environment
+// rather than adding to them. As with the flag package, a name in back quotes
+// in usage is shown as the value placeholder.
+//
+// Parameters:
+//   - fs     The flag set into which the flag is registered.
+//   - name   The flag name.
+//   - value  The default values, replaced by the first value given.
+//   - usage  The flag help.
+//
+// Returns:
+//
+//	A pointer to the flag values.
+//
+// Example:
+//
+//	relays := gocli.StringSlice(fs, "relay-endpoint", nil, "Relay `address`, repeatable")
+func StringSlice(
+	fs *flag.FlagSet,
+	name string,
+	value []string,
+	usage string,
+) *[]string {
+	defer bindEnv(fs, flagNames(fs))
+
+	p := new([]string)
+	*p = append([]string(nil), value...)
+	fs.Var(&stringSliceFlag{values: p, replace: true}, name, usage)
+	return p
+}
+
+// String returns the values joined by commas.
+func (f *stringSliceFlag) String() string {
+	if f.values == nil {
+		return ""
+	}
+	return strings.Join(*f.values, ",")
+}
+
+// Set adds comma-separated values, replacing the current ones on the first
+// call.
+func (f *stringSliceFlag) Set(
+	s string,
+) error {
+	var values []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+
+	if f.replace {
+		*f.values = values
+		f.replace = false
+	} else {
+		*f.values = append(*f.values, values...)
+	}
+	return nil
+}
+
+// bindDefault makes the values set from the environment a default, replaced by
+// the command line.
+func (f *stringSliceFlag) bindDefault() {
+	f.replace = true
+}
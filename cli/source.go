@@ -0,0 +1,257 @@
+package gocli
+
+import (
+	"flag"
+	"reflect"
+	"runtime"
+	"sync"
+	"weak"
+)
+
+// ValueSource tells where the value of a configuration field came from.
+type ValueSource int
+
+// Sources of configuration values, by increasing precedence.
+const (
+	SourceDefault ValueSource = iota // Default of the flag, possibly replaced with WithDefaults
+	SourceFile                       // Configuration file (see RegisterConfigFileFlag)
+	SourceEnv                        // Environment variable of the flag (see EnvVarName)
+	SourceFlag                       // Command line
+)
+
+// String returns the name of the source (e.g., "env").
+func (s ValueSource) String() string {
+	switch s {
+	case SourceFile:
+		return "file"
+	case SourceEnv:
+		return "env"
+	case SourceFlag:
+		return "flag"
+	default:
+		return "default"
+	}
+}
+
+// flagOrigin records a flag value set by its environment variable or the
+// configuration file.
+type flagOrigin struct {
+	source ValueSource
+	value  string // value of the flag once set, telling whether it was set again since
+}
+
+// fieldSource is the source of a field of a configuration.
+type fieldSource struct {
+	field  string // Go name of the field, prefixed with those of its parent structs (e.g., "LogLevel")
+	flag   string // name of the flag setting the field (e.g., "log-level")
+	source ValueSource
+}
+
+// configSources holds the sources of the fields of a configuration returned by
+// a registrar.
+type configSources struct {
+	config func() any // the configuration, or nil once garbage collected
+	fields []fieldSource
+}
+
+var (
+	sourcesMu   sync.RWMutex
+	origins     = make(map[*flag.FlagSet]map[string]flagOrigin) // flags set by env or file, by flag set
+	configs     = make(map[uint64]*configSources)               // sources of the configurations returned by registrars
+	nextConfigs uint64
+)
+
+// Source returns where the value of a field of a configuration came from: the
+// command line, the environment, the configuration file or the default. It
+// answers "why is it using that setting?" when debugging a deployment.
+//
+// The configuration must be one returned by a gocli registrar closure (e.g.,
+// the *LogStdConfig of RegisterLogStdFlags); the sources are those at the time
+// the closure was invoked. A flag given on the command line with the value its
+// environment variable or the configuration file already set is reported as
+// set by them.
+//
+// Parameters:
+//   - cfg    The configuration, as returned by the registrar closure.
+//   - field  The Go name of the field (e.g., "LogLevel"), prefixed with those of
+//     its parent structs and a dot for nested fields, or the name of its flag
+//     (e.g., "log-level").
+//
+// Returns:
+//
+//	The source of the field, or SourceDefault if cfg was not returned by a
+//	registrar or field is not set by a flag.
+//
+// Example:
+//
+//	cfg, err := logConfig()
+//	...
+//	fmt.Println(gocli.Source(cfg, "LogLevel")) // env
+func Source(
+	cfg any,
+	field string,
+) ValueSource {
+	for _, f := range lookupSources(cfg) {
+		if f.field == field || f.flag == field {
+			return f.source
+		}
+	}
+	return SourceDefault
+}
+
+// Sources returns where the values of a configuration came from, like Source,
+// by flag name (e.g., "log-level").
+//
+// Parameters:
+//   - cfg  The configuration, as returned by a registrar closure.
+//
+// Returns:
+//
+//	The source of every field set by a flag, or nil if cfg was not returned
+//	by a registrar.
+func Sources(
+	cfg any,
+) map[string]ValueSource {
+	fields := lookupSources(cfg)
+	if fields == nil {
+		return nil
+	}
+	sources := make(map[string]ValueSource, len(fields))
+	for _, f := range fields {
+		sources[f.flag] = f.source
+	}
+	return sources
+}
+
+// lookupSources returns the sources recorded for a configuration, or nil.
+func lookupSources(
+	cfg any,
+) []fieldSource {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return nil
+	}
+
+	sourcesMu.RLock()
+	defer sourcesMu.RUnlock()
+	for _, c := range configs {
+		if c.config() == cfg {
+			return c.fields
+		}
+	}
+	return nil
+}
+
+// recordSources records the sources of the fields of a configuration returned
+// by a registrar, whose flags are named by the keys of the fields (see
+// configKey) after prefix. Registrar closures call it once the configuration
+// is validated. The record is dropped when the configuration is garbage
+// collected.
+func recordSources[T any](
+	fs *flag.FlagSet,
+	cfg *T,
+	prefix string,
+) {
+	set := make(map[string]struct{})
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = struct{}{}
+	})
+	fields := structSources(fs, set, reflect.ValueOf(cfg).Elem(), "", prefix)
+
+	w := weak.Make(cfg)
+	sourcesMu.Lock()
+	id := nextConfigs
+	nextConfigs++
+	configs[id] = &configSources{
+		config: func() any {
+			if p := w.Value(); p != nil {
+				return p
+			}
+			return nil
+		},
+		fields: fields,
+	}
+	sourcesMu.Unlock()
+
+	runtime.AddCleanup(cfg, func(id uint64) {
+		sourcesMu.Lock()
+		defer sourcesMu.Unlock()
+		delete(configs, id)
+	}, id)
+}
+
+// structSources returns the sources of the fields of a struct, whose Go names
+// are prefixed with path and whose flags are named after prefix.
+func structSources(
+	fs *flag.FlagSet,
+	set map[string]struct{},
+	v reflect.Value,
+	path string,
+	prefix string,
+) []fieldSource {
+	var fields []fieldSource
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if key, ok := configKey(field); ok {
+			if f := fs.Lookup(prefix + key); f != nil {
+				fields = append(fields, fieldSource{
+					field:  path + field.Name,
+					flag:   f.Name,
+					source: flagSource(fs, set, f),
+				})
+				continue
+			}
+		}
+		if field.Type.Kind() == reflect.Struct && !field.Type.Implements(stringerType) {
+			fields = append(fields, structSources(fs, set, v.Field(i), path+field.Name+".", prefix)...)
+		}
+	}
+	return fields
+}
+
+// flagSource returns where the current value of a flag of fs came from, set
+// holding the flags set on fs.
+func flagSource(
+	fs *flag.FlagSet,
+	set map[string]struct{},
+	f *flag.Flag,
+) ValueSource {
+	if _, ok := set[f.Name]; !ok {
+		return SourceDefault
+	}
+	sourcesMu.RLock()
+	origin, ok := origins[fs][f.Name]
+	sourcesMu.RUnlock()
+	if ok && origin.value == f.Value.String() {
+		return origin.source
+	}
+	return SourceFlag
+}
+
+// recordOrigin records that a flag of fs was set by its environment variable
+// or the configuration file.
+func recordOrigin(
+	fs *flag.FlagSet,
+	f *flag.Flag,
+	source ValueSource,
+) {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	if origins[fs] == nil {
+		origins[fs] = make(map[string]flagOrigin)
+	}
+	origins[fs][f.Name] = flagOrigin{source: source, value: f.Value.String()}
+}
+
+// forgetOrigin drops the origin of a flag of fs reset to its previous value.
+func forgetOrigin(
+	fs *flag.FlagSet,
+	name string,
+) {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	delete(origins[fs], name)
+}
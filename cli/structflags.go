@@ -0,0 +1,154 @@
+package gocli
+
+import (
+	"flag"
+	"fmt"
+	"net/netip"
+	"reflect"
+	"time"
+)
+
+// RegisterFlagsFromStruct registers one flag per field of T tagged with
+// `flag:"name"`, sparing new configuration types a hand-written registrar:
+//
+//	type RelayConfig struct {
+//		Listen   string             `flag:"relay-listen" default:":50052" usage:"Listen address" validate:"required"`
+//		Workers  int                `flag:"relay-workers" default:"4" usage:"Number of workers" validate:"min=1"`
+//		Timeout  time.Duration      `flag:"relay-timeout" default:"30s" usage:"Request timeout"`
+//		Peers    []string           `flag:"relay-peer" usage:"Peer address, repeatable" group:"Relay"`
+//		MaxBatch gocli.ByteSize     `flag:"relay-max-batch" default:"1MiB" usage:"Maximum batch size"`
+//		Token    gocli.SecretString `flag:"relay-token" usage:"API token (literal, @file or env:VAR)"`
+//	}
+//
+// Tags:
+//
+//	flag      The flag name; untagged fields are left unchanged, except nested
+//	          structs not implementing fmt.Stringer, whose tagged fields are
+//	          registered too.
+//	default   The default value, written as on the command line; the zero value
+//	          of the field if absent.
+//	usage     The flag help.
+//	group     The usage section of the flag (see SetFlagGroup).
+//	validate  The rules checked by the closure (see Validate).
+//
+// Fields may be strings, bools, integers, floats, time.Duration, ByteSize,
+// []string (see StringSlice), netip.Addr (see IP), []netip.Prefix (see CIDR),
+// or any type whose pointer implements flag.Value (e.g., SecretString). The flags behave like those of the other registrars,
+// and the `flag` tag also names the fields in the errors of Validate and
+// LoadConfigFile.
+//
+// A flag not given on the command line takes the value of its environment
+// variable if set (see EnvVarName and SetEnvPrefix), e.g. KUBENSAGE_RELAY_LISTEN;
+// the precedence is flag > environment > default.
+//
+// It panics if T is not a struct, a field has an unsupported type or a default
+// is invalid, as these are programming errors.
+//
+// Parameters:
+//   - fs    The flag set into which the flags will be registered.
+//   - opts  Options of the registrar (see WithDefaults and WithPrefix).
+//
+// Returns:
+//
+//	A closure that, when invoked, returns a new *T holding the values from
+//	the parsed flags, checked by Validate, or an error joining every
+//	violation, also reported by ParseOrExit.
+//
+// Example:
+//
+//	relayConfig := gocli.RegisterFlagsFromStruct[RelayConfig](flag.CommandLine)
+//	flag.Parse()
+//	cfg, err := relayConfig()
+func RegisterFlagsFromStruct[T any](
+	fs *flag.FlagSet,
+	opts ...RegisterOption,
+) func() (*T, error) {
+	defer bindEnv(fs, flagNames(fs))
+	defer applyOptions(fs, flagNames(fs), opts)
+
+	values := new(T)
+	v := reflect.ValueOf(values).Elem()
+	if v.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("gocli: cannot register flags from %T: not a struct", *values))
+	}
+	prefix := optionsPrefix(opts)
+	registerStructFlags(fs, v, prefix)
+
+	return checked(fs, func() (*T, error) {
+		cfg := new(T)
+		*cfg = *values
+		if err := validateConfig(cfg, prefix); err != nil {
+			return nil, err
+		}
+		recordSources(fs, cfg, prefix)
+		return cfg, nil
+	})
+}
+
+// registerStructFlags registers the tagged fields of a struct, bound to the
+// fields themselves, their flag names following prefix.
+func registerStructFlags(
+	fs *flag.FlagSet,
+	v reflect.Value,
+	prefix string,
+) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		value := v.Field(i)
+
+		name, ok := field.Tag.Lookup("flag")
+		if !ok || name == "" || name == "-" {
+			if field.Type.Kind() == reflect.Struct && !field.Type.Implements(stringerType) {
+				registerStructFlags(fs, value, prefix)
+			}
+			continue
+		}
+
+		name = prefix + name
+		usage := field.Tag.Get("usage")
+		switch p := value.Addr().Interface().(type) {
+		case *time.Duration:
+			fs.Var(&durationFlag{value: p}, name, usage)
+		case *ByteSize:
+			fs.Var(&byteSizeFlag{value: p, unit: Byte}, name, usage)
+		case *[]string:
+			fs.Var(&stringSliceFlag{values: p, replace: true}, name, usage)
+		case *netip.Addr:
+			fs.Var(&ipFlag{value: p}, name, usage)
+		case *[]netip.Prefix:
+			fs.Var(&cidrFlag{values: p, replace: true}, name, usage)
+		case flag.Value:
+			fs.Var(p, name, usage)
+		case *string:
+			fs.StringVar(p, name, "", usage)
+		case *bool:
+			fs.BoolVar(p, name, false, usage)
+		case *int:
+			fs.IntVar(p, name, 0, usage)
+		case *int64:
+			fs.Int64Var(p, name, 0, usage)
+		case *uint:
+			fs.UintVar(p, name, 0, usage)
+		case *uint64:
+			fs.Uint64Var(p, name, 0, usage)
+		case *float64:
+			fs.Float64Var(p, name, 0, usage)
+		default:
+			panic(fmt.Sprintf("gocli: cannot register flag -%s: unsupported field type %s", name, field.Type))
+		}
+
+		f := fs.Lookup(name)
+		f.DefValue = f.Value.String()
+		if def, ok := field.Tag.Lookup("default"); ok {
+			if err := setDefault(f, def); err != nil {
+				panic(fmt.Sprintf("gocli: invalid default %q for -%s: %v", def, name, err))
+			}
+		}
+		if group := field.Tag.Get("group"); group != "" {
+			SetFlagGroup(fs, group, name)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package gocli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// validTimeFormats lists the named formats accepted by the time format flags,
+// those of golog.
+var validTimeFormats = []string{"iso8601", "rfc3339", "rfc3339nano", "epochmillis"}
+
+// timeFormatProbe is formatted with a layout to tell whether it holds elements
+// of the reference time: none of its elements formats as in the reference.
+var timeFormatProbe = time.Date(2017, time.November, 28, 9, 37, 49, 123456789, time.FixedZone("", 3*3600))
+
+// ValidTimeFormats returns the named formats accepted by the time format
+// flags (e.g., --log-time-format), besides Go layouts.
+//
+// Returns:
+//
+//	A new slice holding the format names; callers may modify it.
+func ValidTimeFormats() []string {
+	return append([]string(nil), validTimeFormats...)
+}
+
+// ParseTimeFormat parses a timestamp format: one of ValidTimeFormats,
+// regardless of case, or a layout of the time package (e.g., "2006-01-02
+// 15:04:05.000"), which must hold at least one element of the reference time.
+//
+// Parameters:
+//   - s  The format (e.g., "rfc3339", "Jan _2 15:04:05").
+//
+// Returns:
+//
+//	The format name in lower case, or the layout unchanged, or an error if
+//	s is neither.
+func ParseTimeFormat(
+	s string,
+) (string, error) {
+	for _, name := range validTimeFormats {
+		if strings.EqualFold(strings.TrimSpace(s), name) {
+			return name, nil
+		}
+	}
+	if strings.TrimSpace(s) == "" || timeFormatProbe.Format(s) == s {
+		return "", fmt.Errorf("invalid time format %q: expected %s or a Go layout (e.g., \"2006-01-02 15:04:05\")",
+			s, strings.Join(validTimeFormats, ", "))
+	}
+	return s, nil
+}
+
+// ParseTimeZone parses a time zone: a name of the tz database (e.g.,
+// "Europe/Rome", "America/New_York"), "UTC" or "Local", the latter two
+// regardless of case.
+//
+// Binaries running in images without the tz database (e.g., distroless or
+// scratch) embed it by importing time/tzdata; otherwise every name but UTC
+// and Local is rejected.
+//
+// Parameters:
+//   - s  The time zone (e.g., "Europe/Rome").
+//
+// Returns:
+//
+//	The location, or an error if s is not a known time zone.
+func ParseTimeZone(
+	s string,
+) (*time.Location, error) {
+	name := strings.TrimSpace(s)
+	switch {
+	case strings.EqualFold(name, "UTC"):
+		return time.UTC, nil
+	case strings.EqualFold(name, "Local"):
+		return time.Local, nil
+	case name == "":
+		return nil, fmt.Errorf("invalid time zone %q (e.g., UTC, Local, Europe/Rome)", s)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q: not in the tz database (e.g., UTC, Local, Europe/Rome)", s)
+	}
+	return loc, nil
+}
+
+// timeFormatFlag is a flag.Value holding a timestamp format (see
+// ParseTimeFormat).
+type timeFormatFlag struct {
+	value *string
+}
+
+// TimeFormat defines a flag holding a timestamp format, one of
+// ValidTimeFormats or a Go layout (see ParseTimeFormat), with the behavior of
+// the gocli registrars: it falls back to its environment variable (see
+// EnvVarName) when not given on the command line, and invalid formats are
+// rejected when the command line is parsed. The named formats are appended
+// to usage (e.g., "Timestamp format (iso8601|rfc3339|rfc3339nano|epochmillis
+// or a Go layout)"), and stored in lower case.
+//
+// It panics if value is not a valid format, as this is a programming error.
+//
+// Parameters:
+//   - fs     The flag set into which the flag is registered.
+//   - name   The flag name.
+//   - value  The default format (e.g., "iso8601").
+//   - usage  The flag help, without the named formats.
+//
+// Returns:
+//
+//	A pointer to the flag value.
+//
+// Example:
+//
+//	format := gocli.TimeFormat(fs, "report-time-format", "rfc3339", "Timestamp `format` of the reports")
+func TimeFormat(
+	fs *flag.FlagSet,
+	name string,
+	value string,
+	usage string,
+) *string {
+	defer bindEnv(fs, flagNames(fs))
+
+	p := new(string)
+	f := &timeFormatFlag{value: p}
+	if err := f.Set(value); err != nil {
+		panic(fmt.Sprintf("gocli: invalid default for -%s: %v", name, err))
+	}
+	fs.Var(f, name, usage+" ("+strings.Join(validTimeFormats, "|")+" or a Go layout)")
+	return p
+}
+
+// String returns the current format.
+func (f *timeFormatFlag) String() string {
+	if f.value == nil {
+		return ""
+	}
+	return *f.value
+}
+
+// Get returns the current format, so that the default is quoted in the usage
+// like those of string flags.
+func (f *timeFormatFlag) Get() any {
+	return f.String()
+}
+
+// Set parses a format.
+func (f *timeFormatFlag) Set(
+	s string,
+) error {
+	format, err := ParseTimeFormat(s)
+	if err != nil {
+		return err
+	}
+	*f.value = format
+	return nil
+}
+
+// typeName returns the name of the value in the usage.
+func (f *timeFormatFlag) typeName() string {
+	return "format"
+}
+
+// timeZoneFlag is a flag.Value holding a time zone name (see ParseTimeZone).
+type timeZoneFlag struct {
+	value *string
+}
+
+// TimeZone defines a flag holding a time zone of the tz database (see
+// ParseTimeZone), with the behavior of the gocli registrars: it falls back to
+// its environment variable (see EnvVarName) when not given on the command
+// line, and unknown zones are rejected when the command line is parsed rather
+// than when the zone is used. An empty value leaves the zone unset, e.g., for
+// the local zone. The value is the canonical name of the zone (e.g., "UTC"
+// for "utc"), whose location is given by ParseTimeZone.
+//
+// It panics if value is neither empty nor a known zone, as this is a
+// programming error.
+//
+// Parameters:
+//   - fs     The flag set into which the flag is registered.
+//   - name   The flag name.
+//   - value  The default time zone (e.g., "UTC"), or empty for none.
+//   - usage  The flag help.
+//
+// Returns:
+//
+//	A pointer to the flag value.
+//
+// Example:
+//
+//	zone := gocli.TimeZone(fs, "report-time-zone", "UTC", "Time `zone` of the daily reports")
+//	...
+//	loc, _ := gocli.ParseTimeZone(*zone) // valid, checked by the flag
+func TimeZone(
+	fs *flag.FlagSet,
+	name string,
+	value string,
+	usage string,
+) *string {
+	defer bindEnv(fs, flagNames(fs))
+
+	p := new(string)
+	f := &timeZoneFlag{value: p}
+	if err := f.Set(value); err != nil {
+		panic(fmt.Sprintf("gocli: invalid default for -%s: %v", name, err))
+	}
+	fs.Var(f, name, usage)
+	return p
+}
+
+// String returns the current time zone name.
+func (f *timeZoneFlag) String() string {
+	if f.value == nil {
+		return ""
+	}
+	return *f.value
+}
+
+// Get returns the current time zone name, so that the default is quoted in
+// the usage like those of string flags.
+func (f *timeZoneFlag) Get() any {
+	return f.String()
+}
+
+// Set parses a time zone; an empty value unsets it.
+func (f *timeZoneFlag) Set(
+	s string,
+) error {
+	if strings.TrimSpace(s) == "" {
+		*f.value = ""
+		return nil
+	}
+	loc, err := ParseTimeZone(s)
+	if err != nil {
+		return err
+	}
+	*f.value = loc.String()
+	return nil
+}
+
+// typeName returns the name of the value in the usage.
+func (f *timeZoneFlag) typeName() string {
+	return "zone"
+}
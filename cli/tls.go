@@ -0,0 +1,246 @@
+package gocli
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// TLSConfig holds the TLS settings of a client or server endpoint, turned into
+// a *tls.Config by ClientTLSConfig or ServerTLSConfig.
+type TLSConfig struct {
+	TLSCertFile           string   `log:"tls-cert" validate:"file-exists,required-with=tls-key"` // PEM certificate presented by the endpoint; required by servers, set on clients for mutual TLS
+	TLSKeyFile            string   `log:"tls-key" validate:"file-exists,required-with=tls-cert"` // PEM private key of the certificate
+	TLSCAFile             string   `log:"tls-ca" validate:"file-exists"`                         // PEM bundle of the CAs trusted for the peer certificate; empty uses the system pool on clients, set on servers to require client certificates
+	TLSInsecureSkipVerify bool     `log:"tls-insecure-skip-verify"`                              // Whether clients accept any server certificate; for testing only
+	TLSMinVersion         string   `log:"tls-min-version" validate:"oneof=1.0 1.1 1.2 1.3"`      // Minimum TLS version; empty uses 1.2
+	TLSCipherSuites       []string `log:"tls-cipher-suites"`                                     // Cipher suites allowed up to TLS 1.2, by Go name (e.g., "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"); empty uses Go's defaults
+}
+
+// tlsVersions maps the values of --tls-min-version to TLS versions.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// RegisterTLSFlags registers command-line flags for configuring the TLS
+// settings of an endpoint, their names starting with prefix so that several
+// endpoints of an application (e.g., its gRPC server and its metrics HTTP
+// server) can be configured apart.
+//
+// Registered flags, for the prefix "metrics":
+//
+//	--metrics-tls-cert                  string   PEM certificate of the endpoint (default "")
+//	--metrics-tls-key                   string   PEM private key of the certificate (default "")
+//	--metrics-tls-ca                    string   PEM bundle of the CAs trusted for the peer certificate (default "")
+//	--metrics-tls-insecure-skip-verify  bool     Accept any server certificate, for testing only (default false)
+//	--metrics-tls-min-version           version  Minimum TLS version, 1.0|1.1|1.2|1.3 (default "1.2")
+//	--metrics-tls-cipher-suites         suites   Cipher suites allowed up to TLS 1.2, comma-separated Go names, empty uses Go's defaults (default "")
+//
+// A flag not given on the command line takes the value of its environment
+// variable if set (see EnvVarName and SetEnvPrefix), e.g.
+// KUBENSAGE_METRICS_TLS_CERT; the precedence is flag > environment > default.
+//
+// Parameters:
+//   - fs      The flag set into which the flags will be registered.
+//   - prefix  The prefix of the flag names (e.g., "metrics"), or the empty
+//     string for flags named --tls-cert and so on.
+//   - opts    Options of the registrar (see WithDefaults and WithPrefix).
+//
+// Returns:
+//
+//	A closure that, when invoked, returns a populated *TLSConfig containing
+//	the values from the parsed flags, checked by Validate, or an error
+//	joining every violation, each naming its flag, also reported by
+//	ParseOrExit.
+//
+// Example:
+//
+//	tlsConfig := gocli.RegisterTLSFlags(fs, "metrics")
+//	fs.Parse(os.Args[1:])
+//	cfg, err := tlsConfig()
+//	...
+//	serverTLS, err := gocli.ServerTLSConfig(cfg)
+func RegisterTLSFlags(
+	fs *flag.FlagSet,
+	prefix string,
+	opts ...RegisterOption,
+) func() (*TLSConfig, error) {
+	defer bindEnv(fs, flagNames(fs))
+	defer groupFlags(fs, GroupTLS, flagNames(fs))
+	defer applyOptions(fs, flagNames(fs), opts)
+
+	if prefix != "" {
+		prefix += "-"
+	}
+	prefix = optionsPrefix(opts) + prefix
+	tlsCertFile := fs.String(prefix+"tls-cert", "", "PEM certificate of the endpoint (required by servers, set on clients for mutual TLS)")
+	tlsKeyFile := fs.String(prefix+"tls-key", "", "PEM private key of the certificate")
+	tlsCAFile := fs.String(prefix+"tls-ca", "", "PEM bundle of the CAs trusted for the peer certificate (empty uses the system pool on clients, set on servers to require client certificates)")
+	tlsInsecureSkipVerify := fs.Bool(prefix+"tls-insecure-skip-verify", false, "Accept any server certificate (for testing only)")
+	tlsMinVersion := Enum(fs, prefix+"tls-min-version", []string{"", "1.0", "1.1", "1.2", "1.3"}, "1.2", "Minimum TLS `version`")
+	tlsCipherSuites := StringSlice(fs, prefix+"tls-cipher-suites", nil, "Cipher `suites` allowed up to TLS 1.2, comma-separated Go names (empty uses Go's defaults)")
+
+	return checked(fs, func() (*TLSConfig, error) {
+		cfg := &TLSConfig{
+			TLSCertFile:           *tlsCertFile,
+			TLSKeyFile:            *tlsKeyFile,
+			TLSCAFile:             *tlsCAFile,
+			TLSInsecureSkipVerify: *tlsInsecureSkipVerify,
+			TLSMinVersion:         *tlsMinVersion,
+			TLSCipherSuites:       append([]string(nil), *tlsCipherSuites...),
+		}
+		errs := validateStruct(reflect.ValueOf(cfg).Elem(), prefix)
+		for _, name := range cfg.TLSCipherSuites {
+			if _, err := cipherSuiteID(name); err != nil {
+				errs = append(errs, fmt.Errorf("%stls-cipher-suites: %w", prefix, err))
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+		recordSources(fs, cfg, prefix)
+		return cfg, nil
+	})
+}
+
+// ClientTLSConfig builds the TLS settings of a client from a TLSConfig: the
+// server certificate is verified against the CAs of TLSCAFile, else the
+// system pool, and the certificate of TLSCertFile, if set, is presented for
+// mutual TLS. The caller may set the ServerName of the result.
+//
+// Parameters:
+//   - cfg  The TLS configuration (e.g., from RegisterTLSFlags).
+//
+// Returns:
+//
+//	The TLS settings, or an error if a file cannot be loaded, only one of the
+//	certificate and key is set, or a version or cipher suite is unknown.
+func ClientTLSConfig(
+	cfg *TLSConfig,
+) (*tls.Config, error) {
+	tlsConfig, err := baseTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig.InsecureSkipVerify = cfg.TLSInsecureSkipVerify
+
+	if cfg.TLSCAFile != "" {
+		pool, err := loadCertPool(cfg.TLSCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.TLSCertFile != "" || cfg.TLSKeyFile != "" {
+		if cfg.TLSCertFile == "" || cfg.TLSKeyFile == "" {
+			return nil, errors.New("TLS client certificate and key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// ServerTLSConfig builds the TLS settings of a server from a TLSConfig: the
+// certificate of TLSCertFile is presented to clients, and if TLSCAFile is set,
+// clients must present a certificate signed by one of its CAs.
+//
+// Parameters:
+//   - cfg  The TLS configuration (e.g., from RegisterTLSFlags).
+//
+// Returns:
+//
+//	The TLS settings, or an error if a file cannot be loaded, the certificate
+//	or key is missing, or a version or cipher suite is unknown.
+func ServerTLSConfig(
+	cfg *TLSConfig,
+) (*tls.Config, error) {
+	tlsConfig, err := baseTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.TLSCertFile == "" || cfg.TLSKeyFile == "" {
+		return nil, errors.New("TLS server certificate and key must be set together, and are required by a client CA")
+	}
+	cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS server certificate: %w", err)
+	}
+	tlsConfig.Certificates = []tls.Certificate{cert}
+
+	if cfg.TLSCAFile != "" {
+		pool, err := loadCertPool(cfg.TLSCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// baseTLSConfig builds the TLS settings shared by clients and servers: the
+// minimum version and the cipher suites.
+func baseTLSConfig(
+	cfg *TLSConfig,
+) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.TLSMinVersion != "" {
+		version, ok := tlsVersions[cfg.TLSMinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unknown TLS version %q (1.0|1.1|1.2|1.3)", cfg.TLSMinVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+
+	for _, name := range cfg.TLSCipherSuites {
+		id, err := cipherSuiteID(name)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.CipherSuites = append(tlsConfig.CipherSuites, id)
+	}
+	return tlsConfig, nil
+}
+
+// cipherSuiteID returns the ID of a cipher suite considered secure by Go,
+// given by its name.
+func cipherSuiteID(
+	name string,
+) (uint16, error) {
+	for _, suite := range tls.CipherSuites() {
+		if strings.EqualFold(suite.Name, name) {
+			return suite.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown or insecure cipher suite %q", name)
+}
+
+// loadCertPool reads a PEM bundle of CA certificates.
+func loadCertPool(
+	path string,
+) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate found in CA file %s", path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,369 @@
+package gocli
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Sections of the usage output (see PrintDefaults), in which the registrars
+// place their flags.
+const (
+	GroupGeneral   = "General"   // Flags of the application, --config and --version
+	GroupLogging   = "Logging"   // Flags of the RegisterLog*Flags registrars
+	GroupGrpc      = "gRPC"      // Flags of RegisterGrpcClientFlags and RegisterGrpcServerFlags
+	GroupMetrics   = "Metrics"   // Flags of RegisterMetricsFlags
+	GroupProfiling = "Profiling" // Flags of RegisterProfilingFlags
+	GroupBuffer    = "Buffer"    // Flags of RegisterBufferFlags
+	GroupTLS       = "TLS"       // Flags of RegisterTLSFlags
+	GroupService   = "Service"   // Flags of RegisterServiceFlags
+	GroupHealth    = "Health"    // Flags of RegisterHealthFlags
+)
+
+// flagGroups records the usage section of the flags of a flag set.
+type flagGroups struct {
+	order []string          // sections in order of first registration
+	of    map[string]string // section of each grouped flag
+}
+
+var (
+	usageMu    sync.RWMutex
+	groups     = make(map[*flag.FlagSet]*flagGroups) // sections of the flags, by flag set
+	usageOwned = make(map[*flag.FlagSet]struct{})    // flag sets whose usage calls PrintDefaults
+)
+
+// SetFlagGroup places flags in a section of the usage output (see
+// PrintDefaults), e.g., to gather the flags of an application component.
+// Flags in no section are listed under GroupGeneral.
+//
+// Parameters:
+//   - fs     The flag set holding the flags.
+//   - group  The section title (e.g., GroupLogging or "Storage").
+//   - names  The flag names; it panics if one is not defined in fs.
+func SetFlagGroup(
+	fs *flag.FlagSet,
+	group string,
+	names ...string,
+) {
+	for _, name := range names {
+		if fs.Lookup(name) == nil {
+			panic(fmt.Sprintf("gocli: cannot group undefined flag -%s", name))
+		}
+	}
+
+	usageMu.Lock()
+	g := groups[fs]
+	if g == nil {
+		g = &flagGroups{order: []string{GroupGeneral}, of: make(map[string]string)}
+		groups[fs] = g
+	}
+	if !slices.Contains(g.order, group) {
+		g.order = append(g.order, group)
+	}
+	for _, name := range names {
+		g.of[name] = group
+	}
+	usageMu.Unlock()
+
+	installUsage(fs)
+}
+
+// groupFlags places the flags of fs not in known in a section, like
+// bindEnv. Registrars call it deferred, with the flags known before their
+// registration:
+//
+//	defer groupFlags(fs, GroupLogging, flagNames(fs))
+func groupFlags(
+	fs *flag.FlagSet,
+	group string,
+	known map[string]struct{},
+) {
+	var names []string
+	fs.VisitAll(func(f *flag.Flag) {
+		if _, ok := known[f.Name]; !ok {
+			names = append(names, f.Name)
+		}
+	})
+	SetFlagGroup(fs, group, names...)
+}
+
+// PrintDefaults prints the flags of fs to the output of fs, replacing the flat
+// listing of fs.PrintDefaults: flags are listed by section (see SetFlagGroup),
+// each under its title unless all flags are in GroupGeneral, sorted by name,
+// with their usage and default aligned, and hidden or deprecated flags are
+// omitted (see Hide). Short aliases (see Alias) precede the name of their flag.
+//
+// The flag sets passed to the gocli registrars, to Hide and to SetFlagGroup
+// print their usage with it (for flag.CommandLine, flag.Usage is replaced), as
+// do those of a CommandSet. An application customizing its usage must do so
+// afterwards, and call PrintDefaults instead of fs.PrintDefaults.
+//
+// Parameters:
+//   - fs  The flag set to describe.
+//
+// Example output:
+//
+//	General:
+//	      --node-name string  Name of the node (default "worker-1")
+//
+//	Logging:
+//	      --log-caller        Include the calling file and line in every entry
+//	  -l, --log-level level   Set log level (debug|info|warn|error|dpanic|panic|fatal) (default info)
+func PrintDefaults(
+	fs *flag.FlagSet,
+) {
+	printDefaults(fs, false)
+}
+
+// printDefaults implements PrintDefaults, printing the section titles even
+// when all flags are in GroupGeneral if titled is set.
+func printDefaults(
+	fs *flag.FlagSet,
+	titled bool,
+) {
+	shown, sections := flagSections(fs)
+
+	aligned := false // whether some flag has a short alias, the others being indented to line up
+	for _, group := range shown {
+		for _, f := range sections[group] {
+			aligned = aligned || aliasOf(fs, f) != ""
+		}
+	}
+	width := 0
+	for _, group := range shown {
+		for _, f := range sections[group] {
+			width = max(width, len(flagName(fs, f, aligned)))
+		}
+	}
+
+	out := fs.Output()
+	for i, group := range shown {
+		if i > 0 {
+			_, _ = fmt.Fprintln(out)
+		}
+		if titled || len(shown) > 1 || group != GroupGeneral {
+			_, _ = fmt.Fprintf(out, "%s:\n", group)
+		}
+		for _, f := range sections[group] { // VisitAll sorts by name
+			_, _ = fmt.Fprintf(out, "%-*s  %s\n", width, flagName(fs, f, aligned), flagUsage(f))
+		}
+	}
+}
+
+// flagSections returns the sections of the usage output holding flags of fs,
+// in order, with their flags sorted by name, hidden flags excluded.
+func flagSections(
+	fs *flag.FlagSet,
+) ([]string, map[string][]*flag.Flag) {
+	sections := make(map[string][]*flag.Flag)
+	fs.VisitAll(func(f *flag.Flag) {
+		if isHidden(fs, f.Name) {
+			return
+		}
+		group := groupOf(fs, f)
+		sections[group] = append(sections[group], f)
+	})
+
+	order := []string{GroupGeneral}
+	usageMu.RLock()
+	if g := groups[fs]; g != nil {
+		order = slices.Clone(g.order)
+	}
+	usageMu.RUnlock()
+	for group := range sections {
+		if !slices.Contains(order, group) {
+			order = append(order, group) // a global flag section of a CommandSet
+		}
+	}
+
+	var shown []string
+	for _, group := range order {
+		if len(sections[group]) > 0 {
+			shown = append(shown, group)
+		}
+	}
+	return shown, sections
+}
+
+// groupOf returns the section of a flag of fs, looking up the global flag set
+// for the global flags of a CommandSet.
+func groupOf(
+	fs *flag.FlagSet,
+	f *flag.Flag,
+) string {
+	name := f.Name
+	if forward, ok := f.Value.(*forwardFlag); ok {
+		fs, name = forward.fs, forward.target.Name
+	}
+
+	usageMu.RLock()
+	defer usageMu.RUnlock()
+	if g := groups[fs]; g != nil {
+		if group, ok := g.of[name]; ok {
+			return group
+		}
+	}
+	return GroupGeneral
+}
+
+// flagValue returns the value of a flag, or of the global flag it forwards to
+// for the global flags of a CommandSet, for its type name.
+func flagValue(
+	f *flag.Flag,
+) flag.Value {
+	if forward, ok := f.Value.(*forwardFlag); ok {
+		return forward.target.Value
+	}
+	return f.Value
+}
+
+// typeNamer is implemented by flag values of gocli whose type is shown in the
+// usage by a name other than the "value" of the flag package (e.g., "string").
+type typeNamer interface {
+	typeName() string
+}
+
+// flagName formats the name and type of a flag of fs, preceded by its short
+// alias (e.g., "  -l, --log-level level"), or by as many spaces if aligned is
+// set and it has none.
+func flagName(
+	fs *flag.FlagSet,
+	f *flag.Flag,
+	aligned bool,
+) string {
+	name := "  --" + f.Name
+	if short := aliasOf(fs, f); short != "" {
+		name = "  -" + short + ", --" + f.Name
+	} else if aligned {
+		name = "      --" + f.Name
+	}
+
+	if typeName := flagType(f); typeName != "" {
+		return name + " " + typeName
+	}
+	return name
+}
+
+// flagType returns the type of a flag shown in the usage (e.g., "duration"),
+// or the empty string for boolean flags.
+func flagType(
+	f *flag.Flag,
+) string {
+	value := flagValue(f)
+	typeName, _ := flag.UnquoteUsage(&flag.Flag{Name: f.Name, Usage: f.Usage, Value: value})
+	if namer, ok := value.(typeNamer); ok && typeName == "value" {
+		typeName = namer.typeName()
+	}
+	return typeName
+}
+
+// flagUsage formats the usage and default of a flag.
+func flagUsage(
+	f *flag.Flag,
+) string {
+	usage := flagHelp(f)
+	if def, ok := flagDefault(f); ok {
+		return usage + " (default " + def + ")"
+	}
+	return usage
+}
+
+// flagHelp returns the usage of a flag on one line, without the back quotes
+// naming its type.
+func flagHelp(
+	f *flag.Flag,
+) string {
+	_, usage := flag.UnquoteUsage(&flag.Flag{Name: f.Name, Usage: f.Usage, Value: flagValue(f)})
+	return strings.ReplaceAll(usage, "\n", " ")
+}
+
+// flagDefault formats the default of a flag as shown in the usage, quoted for
+// string flags, and reports whether it is shown: the zero value of the flag
+// type is not, like in fs.PrintDefaults.
+func flagDefault(
+	f *flag.Flag,
+) (string, bool) {
+	value := flagValue(f)
+	if isZeroDefault(value, f.DefValue) {
+		return "", false
+	}
+	if getter, ok := value.(flag.Getter); ok {
+		if _, ok := getter.Get().(string); ok {
+			return strconv.Quote(f.DefValue), true
+		}
+	}
+	return f.DefValue, true
+}
+
+// isZeroDefault reports whether a default is the zero value of its flag type,
+// which the usage does not show, like fs.PrintDefaults.
+func isZeroDefault(
+	value flag.Value,
+	defValue string,
+) (zero bool) {
+	typ := reflect.TypeOf(value)
+	var z reflect.Value
+	if typ.Kind() == reflect.Pointer {
+		z = reflect.New(typ.Elem())
+	} else {
+		z = reflect.Zero(typ)
+	}
+
+	defer func() {
+		if recover() != nil {
+			zero = defValue == ""
+		}
+	}()
+	return defValue == z.Interface().(flag.Value).String()
+}
+
+// installUsage makes the usage of fs print its positional arguments (see
+// PositionalArg) and PrintDefaults, unless it is printed by a CommandSet, which
+// prints them itself.
+func installUsage(
+	fs *flag.FlagSet,
+) {
+	usageMu.RLock()
+	_, owned := usageOwned[fs]
+	usageMu.RUnlock()
+	if owned {
+		return
+	}
+
+	usage := func() {
+		if fs.Name() == "" {
+			_, _ = fmt.Fprintf(fs.Output(), "Usage:\n")
+		} else {
+			_, _ = fmt.Fprintf(fs.Output(), "Usage of %s:\n", fs.Name())
+		}
+		if !hasArgs(fs) {
+			PrintDefaults(fs)
+			return
+		}
+		_, _ = fmt.Fprintf(fs.Output(), "  %s [flags] %s\n\n", fs.Name(), argsSynopsis(fs))
+		printArgs(fs.Output(), fs)
+		if hasFlags(fs) {
+			_, _ = fmt.Fprintln(fs.Output())
+			printDefaults(fs, true)
+		}
+	}
+	if fs == flag.CommandLine {
+		flag.Usage = usage
+	} else {
+		fs.Usage = usage
+	}
+}
+
+// ownUsage records a flag set whose usage calls PrintDefaults, so that
+// installUsage leaves it in place.
+func ownUsage(
+	fs *flag.FlagSet,
+) {
+	usageMu.Lock()
+	defer usageMu.Unlock()
+	usageOwned[fs] = struct{}{}
+}
@@ -0,0 +1,363 @@
+package gocli
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validate checks a configuration struct against the rules of its `validate`
+// tags and reports every violation at once.
+//
+// Rules are separated by commas:
+//
+//	required           The value is not the zero value of its type.
+//	min=N, max=N       Numbers are within the bound, written as a duration for
+//	                   time.Duration fields (e.g., "min=1s") and as a size for ByteSize
+//	                   fields (e.g., "max=1GiB"); strings have at least or at most N bytes.
+//	oneof=a b c        The value is one of the space-separated words.
+//	timeformat         The value is a timestamp format (see ParseTimeFormat).
+//	timezone           The value is a time zone of the tz database (see ParseTimeZone).
+//	file-exists        The path names an existing regular file.
+//	dir-writable       The directory of the path, or the path itself if it is a
+//	                   directory, is writable or can be created under a writable directory.
+//	required-with=KEY  The value is set (not zero) when the field whose key is KEY is set.
+//	required-unless=KEY V
+//	                   The value is set unless the field whose key is KEY has the value V
+//	                   (e.g., "required-unless=log-output journald").
+//	excluded-with=KEY  The value is not set when the field whose key is KEY is set.
+//
+// Rules do not write to the filesystem: dir-writable checks the permissions of
+// the directory, and the preflight checks of the registrars (see AddPreflight)
+// also create a file in it to find out.
+//
+// Except for required, required-with and required-unless, rules accept empty
+// strings, so that optional settings can be left unset. Nested structs are
+// validated too, their violations being prefixed with the key of the struct
+// field. Violations name fields by their `log` tag, else their `flag` tag, else
+// their `json` tag, else their Go name, i.e., the flag names for the gocli
+// configs.
+//
+// Parameters:
+//   - cfg  The struct, or a pointer to it, to validate.
+//
+// Returns:
+//
+//	nil if every rule holds, else an error joining one error per violation
+//	(e.g., "log-max-size: must be at least 0, got -1").
+func Validate(
+	cfg any,
+) error {
+	return validateConfig(cfg, "")
+}
+
+// validateConfig implements Validate, prefixing the keys of the violations
+// with prefix, e.g., for the flags of a registrar given WithPrefix.
+func validateConfig(
+	cfg any,
+	prefix string,
+) error {
+	v := reflect.ValueOf(cfg)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot validate %T: not a struct", cfg)
+	}
+	return errors.Join(validateStruct(v, prefix)...)
+}
+
+// validateStruct returns the violations of the fields of a struct, prefixing
+// their keys with prefix.
+func validateStruct(
+	v reflect.Value,
+	prefix string,
+) []error {
+	var errs []error
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key, ok := configKey(field)
+		if !ok {
+			key = field.Name
+		}
+		key = prefix + key
+		value := v.Field(i)
+
+		if rules, ok := field.Tag.Lookup("validate"); ok {
+			for _, rule := range strings.Split(rules, ",") {
+				if rule = strings.TrimSpace(rule); rule == "" {
+					continue
+				}
+				var err error
+				if name, arg, _ := strings.Cut(rule, "="); fieldRules[name] {
+					err = checkFieldRule(v, value, name, arg, prefix)
+				} else {
+					err = checkRule(value, rule)
+				}
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				}
+			}
+		}
+
+		if value.Kind() == reflect.Pointer && !value.IsNil() {
+			value = value.Elem()
+		}
+		if value.Kind() == reflect.Struct && value.Type() != reflect.TypeOf(time.Time{}) {
+			errs = append(errs, validateStruct(value, key+".")...)
+		}
+	}
+	return errs
+}
+
+// fieldRules are the rules relating a field to another field of its struct.
+var fieldRules = map[string]bool{
+	"required-with":   true,
+	"required-unless": true,
+	"excluded-with":   true,
+}
+
+// checkFieldRule checks a rule relating a field value to the field of the
+// struct v whose key starts arg, named in the errors after prefix.
+func checkFieldRule(
+	v reflect.Value,
+	value reflect.Value,
+	name string,
+	arg string,
+	prefix string,
+) error {
+	key, want, _ := strings.Cut(arg, " ")
+	other, ok := fieldByKey(v, key)
+	if !ok {
+		return fmt.Errorf("unknown field %q in rule %s=%s", key, name, arg)
+	}
+
+	switch name {
+	case "required-with":
+		if !other.IsZero() && value.IsZero() {
+			return fmt.Errorf("is required when %s is set", prefix+key)
+		}
+		return nil
+	case "required-unless":
+		if value.IsZero() && fmt.Sprint(other.Interface()) != strings.TrimSpace(want) {
+			return fmt.Errorf("is required unless %s is %s", prefix+key, strings.TrimSpace(want))
+		}
+		return nil
+	default: // excluded-with
+		if !other.IsZero() && !value.IsZero() {
+			return fmt.Errorf("cannot be set together with %s", prefix+key)
+		}
+		return nil
+	}
+}
+
+// fieldByKey returns the field of the struct v whose key (see configKey) is
+// key.
+func fieldByKey(
+	v reflect.Value,
+	key string,
+) (reflect.Value, bool) {
+	for i := 0; i < v.NumField(); i++ {
+		if k, ok := configKey(v.Type().Field(i)); ok && k == key {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// checkRule checks one rule against a field value.
+func checkRule(
+	value reflect.Value,
+	rule string,
+) error {
+	name, arg, _ := strings.Cut(rule, "=")
+
+	if name == "required" {
+		if value.IsZero() {
+			return errors.New("is required")
+		}
+		return nil
+	}
+	if value.Kind() == reflect.String && value.String() == "" {
+		return nil
+	}
+
+	switch name {
+	case "min", "max":
+		return checkBound(value, name, arg)
+	case "oneof":
+		allowed := strings.Fields(arg)
+		if value.Kind() != reflect.String {
+			return fmt.Errorf("rule %q applies to strings only", rule)
+		}
+		if !slices.Contains(allowed, value.String()) {
+			return fmt.Errorf("must be one of %s, got %q", strings.Join(allowed, ", "), value.String())
+		}
+		return nil
+	case "timeformat":
+		if value.Kind() != reflect.String {
+			return fmt.Errorf("rule %q applies to strings only", rule)
+		}
+		_, err := ParseTimeFormat(value.String())
+		return err
+	case "timezone":
+		if value.Kind() != reflect.String {
+			return fmt.Errorf("rule %q applies to strings only", rule)
+		}
+		_, err := ParseTimeZone(value.String())
+		return err
+	case "file-exists":
+		if value.Kind() != reflect.String {
+			return fmt.Errorf("rule %q applies to strings only", rule)
+		}
+		return checkFileExists(value.String())
+	case "dir-writable":
+		if value.Kind() != reflect.String {
+			return fmt.Errorf("rule %q applies to strings only", rule)
+		}
+		return checkDirWritable(value.String())
+	default:
+		return fmt.Errorf("unknown validation rule %q", rule)
+	}
+}
+
+// checkBound checks a min or max rule.
+func checkBound(
+	value reflect.Value,
+	name string,
+	arg string,
+) error {
+	var order int
+	var bound, got string
+
+	switch {
+	case value.Type() == reflect.TypeOf(time.Duration(0)):
+		limit, err := time.ParseDuration(arg)
+		if err != nil {
+			return fmt.Errorf("invalid duration in rule %s=%s", name, arg)
+		}
+		d := time.Duration(value.Int())
+		order, bound, got = cmp.Compare(d, limit), limit.String(), d.String()
+	case value.Type() == reflect.TypeOf(ByteSize(0)):
+		limit, err := ParseByteSize(arg)
+		if err != nil {
+			return fmt.Errorf("invalid size in rule %s=%s", name, arg)
+		}
+		b := ByteSize(value.Int())
+		order, bound, got = cmp.Compare(b, limit), limit.String(), b.String()
+	case value.CanInt():
+		limit, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer in rule %s=%s", name, arg)
+		}
+		order, bound, got = cmp.Compare(value.Int(), limit), arg, strconv.FormatInt(value.Int(), 10)
+	case value.CanUint():
+		limit, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer in rule %s=%s", name, arg)
+		}
+		order, bound, got = cmp.Compare(value.Uint(), limit), arg, strconv.FormatUint(value.Uint(), 10)
+	case value.CanFloat():
+		limit, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number in rule %s=%s", name, arg)
+		}
+		order, bound, got = cmp.Compare(value.Float(), limit), arg, strconv.FormatFloat(value.Float(), 'g', -1, 64)
+	case value.Kind() == reflect.String:
+		limit, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf("invalid length in rule %s=%s", name, arg)
+		}
+		length := len(value.String())
+		switch {
+		case name == "min" && length < limit:
+			return fmt.Errorf("must be at least %d bytes long, got %d", limit, length)
+		case name == "max" && length > limit:
+			return fmt.Errorf("must be at most %d bytes long, got %d", limit, length)
+		}
+		return nil
+	default:
+		return fmt.Errorf("rule %s applies to numbers and strings only", name)
+	}
+
+	switch {
+	case name == "min" && order < 0:
+		return fmt.Errorf("must be at least %s, got %s", bound, got)
+	case name == "max" && order > 0:
+		return fmt.Errorf("must be at most %s, got %s", bound, got)
+	}
+	return nil
+}
+
+// checkFileExists checks that path names an existing regular file.
+func checkFileExists(
+	path string,
+) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("file %s is not accessible: %w", path, err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", path)
+	}
+	return nil
+}
+
+// checkDirWritable checks that files can be created in the directory of path,
+// or in path itself if it is a directory. A missing directory is accepted if its
+// nearest existing ancestor is writable, since log directories are created at
+// startup.
+func checkDirWritable(
+	path string,
+) error {
+	if path == "" {
+		return nil
+	}
+	dir, err := existingDir(path)
+	if err != nil {
+		return err
+	}
+	if err := accessWritable(dir); err != nil {
+		return fmt.Errorf("directory %s is not writable: %w", dir, err)
+	}
+	return nil
+}
+
+// existingDir returns the directory of path, or path itself if it is a
+// directory, or else the nearest existing ancestor of that directory.
+func existingDir(
+	path string,
+) (string, error) {
+	dir := filepath.Dir(path)
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		dir = path
+	}
+
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return "", fmt.Errorf("%s is not a directory", dir)
+			}
+			return dir, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("directory %s is not accessible: %w", dir, err)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no existing directory above %s", path)
+		}
+		dir = parent
+	}
+}
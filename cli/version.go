@@ -0,0 +1,161 @@
+package gocli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+)
+
+// Build metadata injected at link time, e.g.:
+//
+//	go build -ldflags "-X github.com/kubensage/common/cli.Version=1.4.0 \
+//	  -X github.com/kubensage/common/cli.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/kubensage/common/cli.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Values left empty are taken from the build information recorded by the Go
+// toolchain when available (see VersionInfo). Applications may also assign
+// them at startup, before the flags are parsed.
+var (
+	Version   string // Application version (e.g., "1.4.0")
+	Commit    string // VCS revision the binary was built from
+	BuildDate string // Build time, preferably RFC 3339 in UTC
+)
+
+// BuildInfo describes the build of the running binary.
+type BuildInfo struct {
+	Version   string // Version, or the main module version, or "devel"
+	Commit    string // Commit, or the VCS revision recorded by the toolchain; empty if unknown
+	Dirty     bool   // Whether the toolchain recorded uncommitted changes
+	BuildDate string // BuildDate, or the VCS commit time recorded by the toolchain; empty if unknown
+	GoVersion string // Go version the binary was built with
+	Platform  string // GOOS/GOARCH of the binary
+}
+
+// VersionInfo returns the build metadata of the running binary: the values
+// injected in Version, Commit and BuildDate, completed with the build
+// information recorded by the Go toolchain (main module version, VCS revision
+// and commit time).
+//
+// Returns:
+//
+//	The build metadata.
+func VersionInfo() BuildInfo {
+	info := BuildInfo{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && build.Main.Version != "(devel)" {
+			info.Version = build.Main.Version
+		}
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = setting.Value
+				}
+			case "vcs.modified":
+				info.Dirty, _ = strconv.ParseBool(setting.Value)
+			}
+		}
+	}
+
+	if info.Version == "" {
+		info.Version = "devel"
+	}
+	return info
+}
+
+// versionFlag is a boolean flag.Value printing the build metadata and exiting
+// as soon as it is parsed, like -help.
+type versionFlag struct {
+	appName string
+	output  io.Writer
+}
+
+// RegisterVersionFlag registers the --version flag, which prints the build
+// metadata of the binary (see VersionInfo) on standard output and exits with
+// status 0 when given, before the other flags are validated.
+//
+// Registered flags:
+//
+//	--version  bool  Print version information and exit (default false)
+//
+// Unlike the other registrars, the flag does not fall back to an environment
+// variable.
+//
+// Parameters:
+//   - fs       The flag set into which the flag will be registered.
+//   - appName  The name of the application, printed first.
+//
+// Example output:
+//
+//	agent 1.4.0
+//	  commit:     3f36989c (dirty)
+//	  build date: 2025-06-01T12:00:00Z
+//	  go version: go1.24.4
+//	  platform:   linux/amd64
+func RegisterVersionFlag(
+	fs *flag.FlagSet,
+	appName string,
+) {
+	fs.Var(&versionFlag{appName: appName, output: os.Stdout}, "version", "Print version information and exit")
+}
+
+// IsBoolFlag allows the flag to be given without a value.
+func (f *versionFlag) IsBoolFlag() bool {
+	return true
+}
+
+// String returns "false": the flag never holds a value.
+func (f *versionFlag) String() string {
+	return "false"
+}
+
+// Set prints the build metadata and exits if the value is true.
+func (f *versionFlag) Set(
+	s string,
+) error {
+	enabled, err := strconv.ParseBool(s)
+	if err != nil || !enabled {
+		return err
+	}
+
+	printVersion(f.output, f.appName)
+	os.Exit(0)
+	return nil
+}
+
+// printVersion writes the build metadata of the binary to w.
+func printVersion(
+	w io.Writer,
+	appName string,
+) {
+	info := VersionInfo()
+	commit := info.Commit
+	if commit == "" {
+		commit = "unknown"
+	}
+	if info.Dirty {
+		commit += " (dirty)"
+	}
+	buildDate := info.BuildDate
+	if buildDate == "" {
+		buildDate = "unknown"
+	}
+
+	_, _ = fmt.Fprintf(w, "%s %s\n  commit:     %s\n  build date: %s\n  go version: %s\n  platform:   %s\n",
+		appName, info.Version, commit, buildDate, info.GoVersion, info.Platform)
+}
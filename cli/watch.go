@@ -0,0 +1,301 @@
+package gocli
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// stringerType is the reflect.Type of fmt.Stringer. Structs implementing it
+// (e.g., time.Time, SecretString) are compared as values rather than walked.
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+// Change is a configuration value changed by a reload.
+type Change struct {
+	Key string // Key of the field: its `log` tag, else its `flag` tag, else its `json` tag, else its Go name (e.g., "log-level"); fields of nested structs are prefixed with the key of the struct and a dot
+	Old any    // Value before the reload
+	New any    // Value after the reload
+}
+
+// Watcher holds a configuration reloaded on SIGHUP, reporting the changed
+// values to callbacks. It is created by Watch.
+type Watcher[T any] struct {
+	load func() (*T, error)
+
+	reloadMu sync.Mutex // serializes reloads, so that callbacks see changes in order
+
+	mu        sync.Mutex
+	current   *T
+	callbacks []func(cfg *T, changes []Change)
+	onError   func(err error)
+	stop      func()
+}
+
+// Watch loads a configuration and reloads it every time the process receives
+// SIGHUP, calling the onChange callbacks with the new configuration and its
+// changed values, so that settings such as the log level or a buffer size can
+// be adjusted without restarting the process.
+//
+// The loader is typically the closure of RegisterConfigFileFlag followed by
+// the closure of a registrar: invoking them again re-reads the configuration
+// file, keeping the precedence flag > environment > file > default. The
+// command line and the environment of a running process are fixed, so values
+// taken from them never change on reload. A configuration the loader rejects
+// (e.g., failing Validate) is reported to the error handler (see OnError) and
+// the current one is kept.
+//
+// Callbacks run on the watcher goroutine, one reload at a time, and only when
+// some value changed. Settings they cannot apply at runtime (e.g., a listen
+// address) should be reported rather than ignored.
+//
+// Reloads set the flags of the flag set from the watcher goroutine. The
+// closures of RegisterConfigFileFlag and of the gocli registrars (including
+// RegisterFlagsFromStruct) set and read them under a lock, so the configuration
+// must be read through them, Current or the callbacks: reading the flag
+// pointers directly (e.g., the result of fs.String) races with the reloads.
+//
+// Parameters:
+//   - load      Returns the configuration; called once by Watch, then on every
+//     reload from the watcher goroutine.
+//   - onChange  Callbacks invoked after a reload changing some value; more can
+//     be registered with OnChange.
+//
+// Returns:
+//
+//	The watcher, holding the loaded configuration (see Current), or an error
+//	if the first load fails, in which case no signal handler is installed.
+//
+// Example:
+//
+//	loadConfig := gocli.RegisterConfigFileFlag(flag.CommandLine)
+//	logConfig := gocli.RegisterLogStdFlags(flag.CommandLine)
+//	flag.Parse()
+//	watcher, err := gocli.Watch(func() (*gocli.LogStdConfig, error) {
+//		if err := loadConfig(); err != nil {
+//			return nil, err
+//		}
+//		return logConfig()
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer watcher.Stop()
+//	watcher.Poll(gocli.ConfigPollInterval(flag.CommandLine))
+//	logger, handle, err := golog.NewStdLogger(watcher.Current())
+//	watcher.OnChange(func(cfg *gocli.LogStdConfig, changes []gocli.Change) {
+//		for _, change := range changes {
+//			if change.Key == "log-level" {
+//				_ = handle.SetLevel(cfg.LogLevel)
+//			}
+//		}
+//	})
+func Watch[T any](
+	load func() (*T, error),
+	onChange ...func(cfg *T, changes []Change),
+) (*Watcher[T], error) {
+	cfg, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher[T]{
+		load:      load,
+		current:   cfg,
+		callbacks: onChange,
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigCh, syscall.SIGHUP)
+
+	go func() {
+		for {
+			select {
+			case <-sigCh:
+				if err := w.Reload(); err != nil {
+					w.reportError(err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	w.stop = func() {
+		once.Do(func() {
+			signal.Stop(sigCh)
+			close(done)
+		})
+	}
+	return w, nil
+}
+
+// Current returns the configuration of the last successful load.
+func (w *Watcher[T]) Current() *T {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+// OnChange registers a callback invoked after every reload changing some
+// value.
+//
+// Parameters:
+//   - fn  The callback, receiving the new configuration and its changes.
+func (w *Watcher[T]) OnChange(
+	fn func(cfg *T, changes []Change),
+) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.callbacks = append(w.callbacks, fn)
+}
+
+// OnError sets the handler of the errors of the reloads triggered by SIGHUP or
+// by Poll, which are written to standard error by default.
+//
+// Parameters:
+//   - fn  The handler, e.g., logging the error.
+func (w *Watcher[T]) OnError(
+	fn func(err error),
+) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onError = fn
+}
+
+// Reload loads the configuration and, if some value changed, makes it current
+// and invokes the callbacks, like on SIGHUP. It can be called from a file
+// watcher or an admin endpoint.
+//
+// Returns:
+//
+//	An error if the loader fails; the current configuration is then kept.
+func (w *Watcher[T]) Reload() error {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	cfg, err := w.load()
+	if err != nil {
+		return fmt.Errorf("failed to reload configuration: %w", err)
+	}
+
+	w.mu.Lock()
+	changes := diffConfig(reflect.ValueOf(w.current).Elem(), reflect.ValueOf(cfg).Elem(), "")
+	if len(changes) == 0 {
+		w.mu.Unlock()
+		return nil
+	}
+	w.current = cfg
+	callbacks := append([]func(*T, []Change){}, w.callbacks...)
+	w.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn(cfg, changes)
+	}
+	return nil
+}
+
+// Poll reloads the configuration every interval, like on SIGHUP, so that a
+// configuration served at a URL or mounted from a ConfigMap (see
+// RegisterConfigFileFlag) is picked up without signaling the process. The
+// callbacks only run when some value changed, and errors go to the error
+// handler (see OnError). Stop ends the polling.
+//
+// Parameters:
+//   - interval  The time between reloads; 0 or less disables polling.
+//
+// Example:
+//
+//	watcher.Poll(gocli.ConfigPollInterval(flag.CommandLine))
+func (w *Watcher[T]) Poll(
+	interval time.Duration,
+) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := w.Reload(); err != nil {
+					w.reportError(err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	stop := w.stop
+	w.stop = func() {
+		stop()
+		once.Do(func() { close(done) })
+	}
+}
+
+// Stop uninstalls the SIGHUP handler and ends the polling (see Poll). Reload
+// can still be called.
+func (w *Watcher[T]) Stop() {
+	w.mu.Lock()
+	stop := w.stop
+	w.mu.Unlock()
+	stop()
+}
+
+// reportError passes the error of a reload to the error handler.
+func (w *Watcher[T]) reportError(
+	err error,
+) {
+	w.mu.Lock()
+	onError := w.onError
+	w.mu.Unlock()
+
+	if onError == nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	onError(err)
+}
+
+// diffConfig returns the exported fields of two values of a struct type that
+// differ, recursing into nested structs. Values are reported as is, so that a
+// SecretString prints masked.
+func diffConfig(
+	old, new reflect.Value,
+	prefix string,
+) []Change {
+	var changes []Change
+	for i := 0; i < old.NumField(); i++ {
+		field := old.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key, ok := configKey(field)
+		if !ok {
+			key = field.Name
+		}
+		key = prefix + key
+
+		oldValue, newValue := old.Field(i), new.Field(i)
+		if field.Type.Kind() == reflect.Struct && !field.Type.Implements(stringerType) {
+			changes = append(changes, diffConfig(oldValue, newValue, key+".")...)
+			continue
+		}
+		if !reflect.DeepEqual(oldValue.Interface(), newValue.Interface()) {
+			changes = append(changes, Change{Key: key, Old: oldValue.Interface(), New: newValue.Interface()})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,23 @@
+//go:build !unix
+
+package gocli
+
+import (
+	"errors"
+	"os"
+)
+
+// accessWritable checks that the process may create files in dir from its
+// permission bits, without creating one, where access(2) is not available.
+func accessWritable(
+	dir string,
+) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if info.Mode().Perm()&0o222 == 0 {
+		return errors.New("read-only")
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build unix
+
+package gocli
+
+import "golang.org/x/sys/unix"
+
+// accessWritable checks that the process may create files in dir, as reported
+// by access(2), without creating one.
+func accessWritable(
+	dir string,
+) error {
+	return unix.Access(dir, unix.W_OK)
+}
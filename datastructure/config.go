@@ -0,0 +1,53 @@
+package datastructure
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/kubensage/common/cli"
+)
+
+// NewRingBufferFromConfig creates a RingBuffer sized and configured by cfg,
+// typically obtained from gocli.RegisterBufferFlags. The memory limit of cfg
+// is enforced by the buffer (see WithMaxBytes), measuring the items with the
+// function given WithSizeFunc. The flush batch size of cfg is meant for the
+// consumer popping items from the buffer (see PopN).
+//
+// Parameters:
+//   - cfg: the buffer configuration.
+//   - opts: further options, such as WithSizeFunc, applied after those of cfg.
+//
+// Returns:
+//   - A pointer to a new RingBuffer[T].
+//   - An error if the capacity is not positive, the overflow policy is unknown,
+//     or a memory limit is set without a size function for T.
+//
+// Example:
+//
+//	buffer, err := datastructure.NewRingBufferFromConfig(bufferConfig,
+//		datastructure.WithSizeFunc(func(m *pb.Metrics) int { return proto.Size(m) }))
+func NewRingBufferFromConfig[T any](cfg *gocli.BufferConfig, opts ...Option) (*RingBuffer[T], error) {
+	if cfg.BufferCapacity <= 0 {
+		return nil, fmt.Errorf("invalid buffer capacity %d: must be positive", cfg.BufferCapacity)
+	}
+	policy := OverflowOverwrite
+	if cfg.BufferOverflowPolicy != "" {
+		var err error
+		if policy, err = ParseOverflowPolicy(cfg.BufferOverflowPolicy); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.BufferMaxBytes < 0 {
+		return nil, fmt.Errorf("invalid buffer memory limit %s: must not be negative", cfg.BufferMaxBytes)
+	}
+	opts = append([]Option{WithOverflowPolicy(policy), WithMaxBytes(int64(cfg.BufferMaxBytes))}, opts...)
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if _, ok := o.sizeOf.(func(T) int); o.maxBytes > 0 && !ok {
+		return nil, fmt.Errorf("buffer memory limit %s requires WithSizeFunc for %s", cfg.BufferMaxBytes, reflect.TypeFor[T]())
+	}
+	return NewRingBuffer[T](cfg.BufferCapacity, opts...), nil
+}
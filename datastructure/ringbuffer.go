@@ -1,54 +1,233 @@
 package datastructure
 
-import "sync"
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// OverflowPolicy decides what happens to an element added to a full RingBuffer.
+type OverflowPolicy int
+
+const (
+	// OverflowOverwrite overwrites the oldest element with the new one (default).
+	OverflowOverwrite OverflowPolicy = iota
+
+	// OverflowDropNewest discards the new element, keeping the oldest ones.
+	OverflowDropNewest
+)
+
+// ParseOverflowPolicy returns the policy named by s: "overwrite" or
+// "drop-newest".
+//
+// Parameters:
+//   - s: the policy name.
+//
+// Returns:
+//   - the policy.
+//   - an error if the name is unknown.
+func ParseOverflowPolicy(s string) (OverflowPolicy, error) {
+	switch s {
+	case "overwrite":
+		return OverflowOverwrite, nil
+	case "drop-newest":
+		return OverflowDropNewest, nil
+	default:
+		return 0, fmt.Errorf("unknown overflow policy %q (valid policies: overwrite, drop-newest)", s)
+	}
+}
+
+// String returns the name of the policy, as accepted by ParseOverflowPolicy.
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowOverwrite:
+		return "overwrite"
+	case OverflowDropNewest:
+		return "drop-newest"
+	default:
+		return fmt.Sprintf("OverflowPolicy(%d)", int(p))
+	}
+}
+
+// Option configures a RingBuffer created by NewRingBuffer.
+type Option func(*options)
+
+// options holds the settings applied by Options.
+type options struct {
+	overflow OverflowPolicy
+	maxBytes int64
+	sizeOf   any // func(T) int for the element type T of the buffer
+}
+
+// WithOverflowPolicy sets what Add does when the buffer is full.
+//
+// Parameters:
+//   - policy: the overflow policy; OverflowOverwrite by default.
+func WithOverflowPolicy(policy OverflowPolicy) Option {
+	return func(o *options) {
+		o.overflow = policy
+	}
+}
+
+// WithMaxBytes bounds the total size of the elements held by the buffer, as
+// measured by the function given WithSizeFunc: an element that does not fit is
+// handled like one added to a full buffer, the oldest elements being
+// overwritten until it fits, or the element being discarded with the
+// OverflowDropNewest policy. An element larger than the limit is always
+// discarded.
+//
+// Parameters:
+//   - limit: the maximum total size; 0 leaves only the capacity limit.
+func WithMaxBytes(limit int64) Option {
+	return func(o *options) {
+		o.maxBytes = limit
+	}
+}
+
+// WithSizeFunc sets how the size of an element is measured for WithMaxBytes,
+// e.g., proto.Size for protobuf messages.
+//
+// Parameters:
+//   - sizeOf: returns the size of an element, in bytes; T must be the element
+//     type of the buffer.
+func WithSizeFunc[T any](sizeOf func(T) int) Option {
+	return func(o *options) {
+		o.sizeOf = sizeOf
+	}
+}
 
 // RingBuffer is a generic, thread-safe circular buffer for elements of type T.
 //
-// It has fixed capacity and uses FIFO semantics. When the buffer is full,
-// inserting a new element overwrites the oldest one.
+// It has fixed capacity, and optionally a maximum total size (see WithMaxBytes),
+// and uses FIFO semantics. When the buffer is full, inserting a new element
+// overwrites the oldest one, or is discarded with the OverflowDropNewest policy.
 //
 // All operations are safe for concurrent use by multiple goroutines.
 type RingBuffer[T any] struct {
-	data     []T        // underlying storage
-	capacity int        // fixed capacity
-	start    int        // index of the oldest element
-	size     int        // current number of elements
-	mu       sync.Mutex // mutex for thread safety
+	data     []T            // underlying storage
+	capacity int            // fixed capacity
+	start    int            // index of the oldest element
+	size     int            // current number of elements
+	overflow OverflowPolicy // what Add does when the buffer is full
+	dropped  uint64         // elements lost to overflow
+	maxBytes int64          // maximum total size of the elements; 0 if unbounded
+	sizeOf   func(T) int    // size of an element, set if maxBytes is
+	sizes    []int          // size of the element at the same index of data
+	bytes    int64          // total size of the elements held
+	mu       sync.Mutex     // mutex for thread safety
 }
 
 // NewRingBuffer creates a new empty RingBuffer with the given capacity.
 //
 // Parameters:
 //   - cap: maximum number of elements the buffer can hold.
+//   - opts: options such as WithOverflowPolicy and WithMaxBytes.
 //
 // Returns:
 //
 //	A pointer to a new RingBuffer[T].
-func NewRingBuffer[T any](cap int) *RingBuffer[T] {
-	return &RingBuffer[T]{
+//
+// NewRingBuffer panics if WithMaxBytes is given without WithSizeFunc, or with
+// the size function of another element type.
+func NewRingBuffer[T any](cap int, opts ...Option) *RingBuffer[T] {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	b := &RingBuffer[T]{
 		data:     make([]T, cap),
 		capacity: cap,
+		overflow: o.overflow,
+	}
+	if o.maxBytes > 0 {
+		sizeOf, ok := o.sizeOf.(func(T) int)
+		if !ok {
+			panic(fmt.Sprintf("datastructure: WithMaxBytes requires WithSizeFunc for %s, got %T", reflect.TypeFor[T](), o.sizeOf))
+		}
+		b.maxBytes, b.sizeOf, b.sizes = o.maxBytes, sizeOf, make([]int, cap)
 	}
+	return b
 }
 
 // Add inserts an item into the buffer.
 //
 // If the buffer is not full, the item is added at the next free position.
-// If the buffer is full, the oldest item is overwritten (circular behavior).
+// If the buffer is full, or the item would exceed its maximum total size, the
+// oldest items are overwritten (circular behavior), or the item is discarded
+// with the OverflowDropNewest policy. Either way the lost elements are counted
+// by Dropped.
 //
 // Parameters:
 //   - item: the value of type T to be added.
 func (b *RingBuffer[T]) Add(item T) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
+	b.add(item)
+}
+
+// AddAll inserts items into the buffer in order, like successive calls to Add
+// but under a single lock acquisition, e.g., for a batch received at once.
+//
+// Parameters:
+//   - items: the values of type T to be added, oldest first.
+func (b *RingBuffer[T]) AddAll(items ...T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, item := range items {
+		b.add(item)
+	}
+}
+
+// add inserts an item into the buffer; b.mu must be held.
+func (b *RingBuffer[T]) add(item T) {
+	var n int
+	if b.maxBytes > 0 {
+		n = b.sizeOf(item)
+		if int64(n) > b.maxBytes {
+			b.dropped++
+			return
+		}
+	}
+
+	if b.full(n) {
+		if b.overflow == OverflowDropNewest {
+			b.dropped++
+			return
+		}
+		for b.full(n) {
+			b.removeOldest()
+			b.dropped++
+		}
+	}
 
 	idx := (b.start + b.size) % b.capacity
 	b.data[idx] = item
-	if b.size < b.capacity {
-		b.size++
-	} else {
-		b.start = (b.start + 1) % b.capacity
+	if b.maxBytes > 0 {
+		b.sizes[idx] = n
+		b.bytes += int64(n)
 	}
+	b.size++
+}
+
+// full reports whether an item of n bytes does not fit in the buffer; b.mu
+// must be held.
+func (b *RingBuffer[T]) full(n int) bool {
+	return b.size == b.capacity || (b.maxBytes > 0 && b.bytes+int64(n) > b.maxBytes)
+}
+
+// removeOldest removes the oldest item, the buffer not being empty, and
+// returns it; b.mu must be held.
+func (b *RingBuffer[T]) removeOldest() T {
+	item := b.data[b.start]
+	var zeroValue T
+	b.data[b.start] = zeroValue // remove reference for GC
+	if b.maxBytes > 0 {
+		b.bytes -= int64(b.sizes[b.start])
+		b.sizes[b.start] = 0
+	}
+	b.start = (b.start + 1) % b.capacity
+	b.size--
+	return item
 }
 
 // Pop removes and returns the oldest item from the buffer.
@@ -64,12 +243,92 @@ func (b *RingBuffer[T]) Pop() (zero T, result T, ok bool) {
 	if b.size == 0 {
 		return zero, zero, false
 	}
-	result = b.data[b.start]
-	var zeroValue T
-	b.data[b.start] = zeroValue // remove reference for GC
-	b.start = (b.start + 1) % b.capacity
-	b.size--
-	return zero, result, true
+	return zero, b.removeOldest(), true
+}
+
+// Peek returns the oldest item without removing it, e.g., to inspect the
+// next item to process before committing to a Pop.
+//
+// Returns:
+//   - the oldest element in the buffer, or the zero value of T if it is empty.
+//   - true if the buffer holds an element; false if it is empty.
+func (b *RingBuffer[T]) Peek() (T, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.size == 0 {
+		var zero T
+		return zero, false
+	}
+	return b.data[b.start], true
+}
+
+// PeekN returns up to n of the oldest items without removing them, e.g., to
+// size a gRPC batch before popping it.
+//
+// Parameters:
+//   - n: the maximum number of items to return.
+//
+// Returns:
+//   - a new slice holding the min(n, Len()) oldest elements, oldest first,
+//     or nil if n is not positive or the buffer is empty.
+func (b *RingBuffer[T]) PeekN(n int) []T {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n = min(n, b.size)
+	if n <= 0 {
+		return nil
+	}
+	return b.oldest(n)
+}
+
+// Snapshot returns a copy of the items in the buffer without removing them,
+// e.g., for a debugging endpoint or a metric showing what is queued, without
+// disturbing the consumer.
+//
+// Returns:
+//   - a new slice holding every element, oldest first; empty, not nil, if
+//     the buffer is empty.
+func (b *RingBuffer[T]) Snapshot() []T {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.oldest(b.size)
+}
+
+// oldest copies the n oldest items, n being at most b.size; b.mu must be
+// held.
+func (b *RingBuffer[T]) oldest(n int) []T {
+	items := make([]T, n)
+	for i := range items {
+		items[i] = b.data[(b.start+i)%b.capacity]
+	}
+	return items
+}
+
+// PopN removes and returns up to n of the oldest items from the buffer under a
+// single lock acquisition, e.g., for a sender flushing a batch.
+//
+// Parameters:
+//   - n: the maximum number of items to remove.
+//
+// Returns:
+//   - a new slice holding the min(n, Len()) oldest elements, oldest first,
+//     or nil if n is not positive or the buffer is empty.
+//   - the number of elements left in the buffer, e.g., to flush another batch.
+func (b *RingBuffer[T]) PopN(n int) ([]T, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n = min(n, b.size)
+	if n <= 0 {
+		return nil, b.size
+	}
+	items := make([]T, n)
+	for i := range items {
+		items[i] = b.removeOldest()
+	}
+	return items, b.size
 }
 
 // Readd reinserts an item into the position it was last popped from,
@@ -82,16 +341,25 @@ func (b *RingBuffer[T]) Pop() (zero T, result T, ok bool) {
 //
 // Returns:
 //   - true if the item was successfully reinserted.
-//   - false if the buffer is already full.
+//   - false if the buffer is already full, or the item would exceed its
+//     maximum total size.
 func (b *RingBuffer[T]) Readd(item T) bool {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	if b.size == b.capacity {
+	var n int
+	if b.maxBytes > 0 {
+		n = b.sizeOf(item)
+	}
+	if b.full(n) {
 		return false
 	}
 	b.start = (b.start - 1 + b.capacity) % b.capacity
 	b.data[b.start] = item
+	if b.maxBytes > 0 {
+		b.sizes[b.start] = n
+		b.bytes += int64(n)
+	}
 	b.size++
 	return true
 }
@@ -103,3 +371,15 @@ func (b *RingBuffer[T]) Readd(item T) bool {
 func (b *RingBuffer[T]) Len() int {
 	return b.size
 }
+
+// Dropped returns the number of elements lost because the buffer was full or
+// would have exceeded its maximum total size: the overwritten oldest elements,
+// or the discarded new ones.
+//
+// Returns:
+//   - the number of elements lost since the buffer was created.
+func (b *RingBuffer[T]) Dropped() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dropped
+}
@@ -0,0 +1,108 @@
+package gogo
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Schedule yields the run times of a periodic job. gocli.ScheduleSpec, parsed
+// from a duration or a cron expression, implements it.
+type Schedule interface {
+	// Next returns the time of the first run after the given time, or the
+	// zero time if the job must not run again.
+	Next(after time.Time) time.Time
+}
+
+// interval is a Schedule running at a fixed interval.
+type interval time.Duration
+
+// Next returns after plus the interval.
+func (i interval) Next(
+	after time.Time,
+) time.Time {
+	return after.Add(time.Duration(i))
+}
+
+// Every returns a Schedule running at a fixed interval, measured from the end
+// of the previous run. It panics if d is not positive.
+//
+// Parameters:
+//   - d: the interval.
+//
+// Returns:
+//   - The schedule.
+func Every(
+	d time.Duration,
+) Schedule {
+	if d <= 0 {
+		panic("gogo: non-positive interval for Every")
+	}
+	return interval(d)
+}
+
+// RunPeriodically runs a job at the times of a schedule until ctx is done or
+// the schedule has no next run. Runs never overlap: the next run is computed
+// once the previous one returns, so runs missed while a job was running are
+// skipped rather than queued.
+//
+// Parameters:
+//   - ctx: stops the runs once done; it is passed to the job, which should
+//     return promptly when it is done.
+//   - schedule: the run times, e.g., a gocli.ScheduleSpec or Every(time.Minute).
+//   - job: the function run at each time.
+//
+// Example:
+//
+//	cleanup := gocli.Schedule(fs, "cleanup-schedule", "0 3 * * *", "Cleanup `schedule`")
+//	...
+//	gogo.RunPeriodically(ctx, *cleanup, func(ctx context.Context) {
+//	    removeExpiredFiles(ctx)
+//	})
+func RunPeriodically(
+	ctx context.Context,
+	schedule Schedule,
+	job func(ctx context.Context),
+) {
+	for {
+		next := schedule.Next(time.Now())
+		if next.IsZero() {
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		job(ctx)
+	}
+}
+
+// GoPeriodically runs RunPeriodically in a new goroutine tied to wg, as
+// SafeGo does.
+//
+// Parameters:
+//   - wg: a pointer to a sync.WaitGroup that tracks concurrent tasks.
+//   - ctx: stops the runs once done.
+//   - schedule: the run times.
+//   - job: the function run at each time.
+//
+// Example:
+//
+//	var wg sync.WaitGroup
+//	GoPeriodically(&wg, ctx, *reportInterval, sendReport)
+//	<-ctx.Done()
+//	wg.Wait() // wait for the running report, if any, to finish
+func GoPeriodically(
+	wg *sync.WaitGroup,
+	ctx context.Context,
+	schedule Schedule,
+	job func(ctx context.Context),
+) {
+	SafeGo(wg, func() {
+		RunPeriodically(ctx, schedule, job)
+	})
+}
@@ -1,9 +1,18 @@
 package gogrpc
 
 import (
+	"crypto/tls"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/kubensage/common/cli"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
 )
 
 // InsecureGrpcConnection establishes a gRPC client connection to the specified target
@@ -35,3 +44,113 @@ func InsecureGrpcConnection(
 	}
 	return connection
 }
+
+// GrpcConnection creates a gRPC client connection configured by cfg, typically
+// obtained from gocli.RegisterGrpcClientFlags.
+//
+// The connection uses TLS when cfg.GrpcTLS is set or a CA bundle or client
+// certificate is configured, and plaintext otherwise (e.g., for Unix domain
+// sockets). Calls failing with UNAVAILABLE are retried according to the retry
+// settings, unless opts install another service config. Like grpc.NewClient,
+// it does not wait for the connection to be established.
+//
+// Parameters:
+//   - cfg: the client configuration.
+//   - opts: additional dial options, applied after those derived from cfg.
+//
+// Returns:
+//   - A pointer to a grpc.ClientConn that can be used to create service clients.
+//   - An error if a certificate file cannot be loaded or the target is invalid.
+//
+// Example:
+//
+//	conn, err := GrpcConnection(grpcConfig)
+//	if err != nil {
+//		logger.Fatal("failed to create gRPC connection", zap.Error(err))
+//	}
+//	client := mypb.NewMyServiceClient(conn)
+func GrpcConnection(
+	cfg *gocli.GrpcClientConfig,
+	opts ...grpc.DialOption,
+) (*grpc.ClientConn, error) {
+	var dialOpts []grpc.DialOption
+
+	if cfg.GrpcTLS || cfg.GrpcTLSCAFile != "" || cfg.GrpcTLSCertFile != "" {
+		tlsConfig, err := clientTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+
+	if cfg.GrpcConnectTimeout > 0 {
+		dialOpts = append(dialOpts, grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoff.DefaultConfig,
+			MinConnectTimeout: cfg.GrpcConnectTimeout,
+		}))
+	}
+
+	if cfg.GrpcKeepaliveTime > 0 {
+		dialOpts = append(dialOpts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                cfg.GrpcKeepaliveTime,
+			Timeout:             cfg.GrpcKeepaliveTimeout,
+			PermitWithoutStream: true,
+		}))
+	}
+
+	if cfg.GrpcMaxMsgSize > 0 {
+		size := int(cfg.GrpcMaxMsgSize)
+		dialOpts = append(dialOpts, grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(size),
+			grpc.MaxCallSendMsgSize(size),
+		))
+	}
+
+	if cfg.GrpcRetryMaxAttempts > 1 {
+		dialOpts = append(dialOpts, grpc.WithDefaultServiceConfig(retryServiceConfig(cfg)))
+	}
+
+	connection, err := grpc.NewClient(cfg.GrpcTarget, append(dialOpts, opts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC client for %s: %w", cfg.GrpcTarget, err)
+	}
+	return connection, nil
+}
+
+// clientTLSConfig builds the TLS settings of a client connection.
+func clientTLSConfig(
+	cfg *gocli.GrpcClientConfig,
+) (*tls.Config, error) {
+	tlsConfig, err := gocli.ClientTLSConfig(&gocli.TLSConfig{
+		TLSCertFile: cfg.GrpcTLSCertFile,
+		TLSKeyFile:  cfg.GrpcTLSKeyFile,
+		TLSCAFile:   cfg.GrpcTLSCAFile,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gRPC client: %w", err)
+	}
+	tlsConfig.ServerName = cfg.GrpcTLSServerName
+	return tlsConfig, nil
+}
+
+// retryServiceConfig returns a service config retrying the calls of every
+// method failing with UNAVAILABLE.
+func retryServiceConfig(
+	cfg *gocli.GrpcClientConfig,
+) string {
+	initial := cfg.GrpcRetryInitialBackoff
+	if initial <= 0 {
+		initial = 100 * time.Millisecond
+	}
+	maxBackoff := max(cfg.GrpcRetryMaxBackoff, initial)
+
+	seconds := func(d time.Duration) string {
+		return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+	}
+	return fmt.Sprintf(`{"methodConfig":[{"name":[{}],"retryPolicy":{`+
+		`"maxAttempts":%d,"initialBackoff":%q,"maxBackoff":%q,"backoffMultiplier":2,`+
+		`"retryableStatusCodes":["UNAVAILABLE"]}}]}`,
+		min(cfg.GrpcRetryMaxAttempts, 5), seconds(initial), seconds(maxBackoff))
+}
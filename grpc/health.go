@@ -0,0 +1,46 @@
+package gogrpc
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// ConnectionChecker returns a checker of the state of a client connection,
+// for the readiness endpoint of gometrics.HealthServer (see
+// gometrics.Checker): it fails while the connection is in TRANSIENT_FAILURE
+// or shut down. An idle connection counts as healthy and is asked to
+// connect, and a connecting one is given until the end of the probe to
+// settle.
+//
+// Parameters:
+//   - conn: the client connection (e.g., from GrpcConnection).
+//
+// Returns:
+//   - The checker.
+//
+// Example:
+//
+//	health.AddReadinessCheck("relay", gogrpc.ConnectionChecker(conn))
+func ConnectionChecker(
+	conn *grpc.ClientConn,
+) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		state := conn.GetState()
+		if state == connectivity.Connecting && conn.WaitForStateChange(ctx, state) {
+			state = conn.GetState()
+		}
+
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Idle:
+			conn.Connect()
+			return nil
+		default:
+			return fmt.Errorf("connection to %s is %s", conn.Target(), state)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package gogrpc
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kubensage/common/cli"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// GrpcServer is a gRPC server bound to the address of its configuration.
+// Services are registered on the embedded grpc.Server before calling Serve.
+type GrpcServer struct {
+	*grpc.Server
+	listener        net.Listener
+	shutdownTimeout time.Duration
+}
+
+// NewGrpcServer creates a gRPC server configured by cfg, typically obtained
+// from gocli.RegisterGrpcServerFlags, and binds its listen address so that
+// address errors are reported before serving.
+//
+// A unix:///path address listens on a Unix domain socket: a stale socket file
+// left by a previous run is removed, and the file is removed again when the
+// server stops. The server uses TLS when a certificate is configured, requiring
+// client certificates signed by the client CA bundle if one is set. When
+// allowed networks are configured, TCP connections from other addresses are
+// closed as soon as they are accepted.
+//
+// Parameters:
+//   - cfg: the server configuration.
+//   - opts: additional server options, applied after those derived from cfg.
+//
+// Returns:
+//   - The server, listening but not yet serving.
+//   - An error if a certificate file cannot be loaded or the address cannot be bound.
+//
+// Example:
+//
+//	server, err := NewGrpcServer(grpcConfig)
+//	if err != nil {
+//		logger.Fatal("failed to create gRPC server", zap.Error(err))
+//	}
+//	mypb.RegisterMyServiceServer(server, service)
+//	go func() {
+//		<-ctx.Done()
+//		server.Shutdown()
+//	}()
+//	if err := server.Serve(); err != nil {
+//		logger.Fatal("gRPC server failed", zap.Error(err))
+//	}
+func NewGrpcServer(
+	cfg *gocli.GrpcServerConfig,
+	opts ...grpc.ServerOption,
+) (*GrpcServer, error) {
+	var serverOpts []grpc.ServerOption
+
+	if cfg.GrpcServerTLSCertFile != "" || cfg.GrpcServerTLSKeyFile != "" || cfg.GrpcServerTLSClientCAFile != "" {
+		tlsConfig, err := serverTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	if cfg.GrpcServerMaxConcurrentStreams > 0 {
+		serverOpts = append(serverOpts, grpc.MaxConcurrentStreams(uint32(cfg.GrpcServerMaxConcurrentStreams)))
+	}
+
+	if cfg.GrpcServerMaxMsgSize > 0 {
+		size := int(cfg.GrpcServerMaxMsgSize)
+		serverOpts = append(serverOpts, grpc.MaxRecvMsgSize(size), grpc.MaxSendMsgSize(size))
+	}
+
+	listener, err := listen(cfg.GrpcListen)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.GrpcServerAllowedCIDRs) > 0 {
+		listener = &allowListener{Listener: listener, allowed: cfg.GrpcServerAllowedCIDRs}
+	}
+
+	return &GrpcServer{
+		Server:          grpc.NewServer(append(serverOpts, opts...)...),
+		listener:        listener,
+		shutdownTimeout: cfg.GrpcServerShutdownTimeout,
+	}, nil
+}
+
+// Addr returns the address the server listens on, e.g., to find the port
+// chosen for ":0".
+func (s *GrpcServer) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Serve accepts connections until Shutdown is called. It shadows the Serve
+// method of grpc.Server, serving on the listener bound by NewGrpcServer.
+//
+// Returns:
+//   - nil after Shutdown, else the error that stopped the server.
+func (s *GrpcServer) Serve() error {
+	return s.Server.Serve(s.listener)
+}
+
+// Shutdown stops accepting connections and waits for the running calls to end,
+// for at most the shutdown timeout of the configuration. Calls still running
+// then are cancelled.
+func (s *GrpcServer) Shutdown() {
+	if s.shutdownTimeout <= 0 {
+		s.Stop()
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+
+	timer := time.NewTimer(s.shutdownTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		s.Stop()
+		<-done
+	}
+}
+
+// listen binds a host:port or unix:///path address.
+func listen(
+	address string,
+) (net.Listener, error) {
+	path, ok := strings.CutPrefix(address, "unix://")
+	if !ok {
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on %s: %w", address, err)
+		}
+		return listener, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		// A socket left by a previous run: remove it if nothing answers on it.
+		if conn, err := net.Dial("unix", path); err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("socket %s is in use by another process", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+	return listener, nil
+}
+
+// allowListener is a net.Listener closing the TCP connections whose remote
+// address is outside the allowed networks.
+type allowListener struct {
+	net.Listener
+	allowed []netip.Prefix
+}
+
+// Accept waits for the next connection from an allowed address.
+func (l *allowListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		addr, ok := conn.RemoteAddr().(*net.TCPAddr)
+		if !ok {
+			return conn, nil // not TCP, e.g., a Unix domain socket
+		}
+		ip := addr.AddrPort().Addr().Unmap()
+		for _, prefix := range l.allowed {
+			if prefix.Contains(ip) {
+				return conn, nil
+			}
+		}
+		_ = conn.Close()
+	}
+}
+
+// serverTLSConfig builds the TLS settings of a server.
+func serverTLSConfig(
+	cfg *gocli.GrpcServerConfig,
+) (*tls.Config, error) {
+	tlsConfig, err := gocli.ServerTLSConfig(&gocli.TLSConfig{
+		TLSCertFile: cfg.GrpcServerTLSCertFile,
+		TLSKeyFile:  cfg.GrpcServerTLSKeyFile,
+		TLSCAFile:   cfg.GrpcServerTLSClientCAFile,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gRPC server: %w", err)
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,342 @@
+package golog
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubensage/common/cli"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// auditSeqKey holds the position of a record in the audit log, starting at 1.
+	auditSeqKey = "seq"
+
+	// auditPrevHashKey holds the hash of the previous record.
+	auditPrevHashKey = "prev_hash"
+
+	// auditHashKey holds the hash of the record itself; it is always the last key.
+	auditHashKey = "hash"
+)
+
+// auditGenesisHash is the prev_hash of the first record of an audit log.
+var auditGenesisHash = strings.Repeat("0", sha256.Size*2)
+
+// auditHashSuffixLen is the length of the `,"hash":"<hex>"}` record suffix.
+var auditHashSuffixLen = len(`,"`+auditHashKey+`":""}`) + sha256.Size*2
+
+// SetupAuditLogger creates a logger for security-relevant events (e.g.,
+// configuration changes, authentications), written to their own append-only
+// file separately from the application logs.
+//
+// Every record is written and synced to disk before the call returns, and is
+// never sampled, deduplicated or rotated. Records are hash-chained: each one
+// carries a sequence number, the SHA-256 hash of the previous record and its
+// own hash, so that modifying, removing or reordering records is detected by
+// VerifyAuditLog. Restarting the application continues the existing chain.
+//
+// Parameters:
+//   - cfg: the audit log configuration.
+//
+// Returns:
+//   - *zap.Logger writing to the audit file; events are expected at info level and above.
+//   - *Handle whose Close releases the file.
+//
+// Panics if the logger cannot be created. Use NewAuditLogger to handle the error instead.
+//
+// Example:
+//
+//	audit, auditHandle := golog.SetupAuditLogger(auditCfg())
+//	defer auditHandle.Close()
+//	audit.Info("peer authenticated", zap.String("peer", addr))
+func SetupAuditLogger(
+	cfg *gocli.LogAuditConfig,
+) (*zap.Logger, *Handle) {
+	logger, handle, err := NewAuditLogger(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create audit logger: %v", err)
+	}
+	return logger, handle
+}
+
+// NewAuditLogger is like SetupAuditLogger but returns an error instead of
+// terminating the application.
+func NewAuditLogger(
+	cfg *gocli.LogAuditConfig,
+) (*zap.Logger, *Handle, error) {
+	if cfg.AuditFile == "" {
+		return nil, nil, errors.New("audit log file is required")
+	}
+
+	dirMode, err := parseDirMode(cfg.AuditDirMode)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := ensureLogDir(cfg.AuditFile, dirMode); err != nil {
+		return nil, nil, err
+	}
+
+	encodeTime, err := newTimeEncoder(TimeFormatRFC3339Nano, time.UTC)
+	if err != nil {
+		return nil, nil, err
+	}
+	enc, err := newEncoder(EncoderJSON, false, encodeTime)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	chain, err := openAuditChain(cfg.AuditFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	level := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	handle := newHandle(level)
+	handle.onClose(chain.close)
+
+	logger := zap.New(&auditCore{LevelEnabler: level, enc: enc, chain: chain})
+	handle.registry = NewRegistry(logger, nil)
+	return logger, handle, nil
+}
+
+// VerifyAuditLog checks the hash chain of an audit log written by the audit
+// logger.
+//
+// A valid chain proves that no record was modified, inserted, removed or
+// reordered, except for records removed from the end of the log; compare the
+// returned count with an externally kept record count to detect truncation.
+//
+// Parameters:
+//   - r: the audit log content.
+//
+// Returns:
+//   - the number of valid records read.
+//   - error describing the first invalid record, if any.
+func VerifyAuditLog(
+	r io.Reader,
+) (uint64, error) {
+	reader := bufio.NewReader(r)
+	prevHash := auditGenesisHash
+	var count uint64
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) == 0 && err == io.EOF {
+			return count, nil
+		}
+		if err != nil && err != io.EOF {
+			return count, err
+		}
+		if err == io.EOF {
+			return count, fmt.Errorf("audit record %d: incomplete record", count+1)
+		}
+
+		record, err := parseAuditRecord(line)
+		if err != nil {
+			return count, fmt.Errorf("audit record %d: %w", count+1, err)
+		}
+		if record.Seq != count+1 {
+			return count, fmt.Errorf("audit record %d: unexpected sequence number %d", count+1, record.Seq)
+		}
+		if record.PrevHash != prevHash {
+			return count, fmt.Errorf("audit record %d: broken hash chain", count+1)
+		}
+		prevHash = record.Hash
+		count++
+	}
+}
+
+// auditRecord holds the chaining fields of an audit record.
+type auditRecord struct {
+	Seq      uint64 `json:"seq"`
+	PrevHash string `json:"prev_hash"`
+	Hash     string `json:"-"`
+}
+
+// parseAuditRecord parses an encoded audit record and checks its own hash.
+//
+// Parameters:
+//   - line: the record, with or without its trailing newline.
+//
+// Returns:
+//   - the chaining fields of the record.
+//   - error if the record is malformed or its hash does not match its content.
+func parseAuditRecord(
+	line []byte,
+) (auditRecord, error) {
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	if len(line) < auditHashSuffixLen {
+		return auditRecord{}, errors.New("malformed record")
+	}
+
+	split := len(line) - auditHashSuffixLen
+	if !bytes.HasPrefix(line[split:], []byte(`,"`+auditHashKey+`":"`)) {
+		return auditRecord{}, errors.New("malformed record: missing hash")
+	}
+	hash := line[split+len(`,"`+auditHashKey+`":"`) : len(line)-len(`"}`)]
+
+	content := append(line[:split:split], '}')
+	sum := sha256.Sum256(content)
+	if hex.EncodeToString(sum[:]) != string(hash) {
+		return auditRecord{}, errors.New("hash mismatch")
+	}
+
+	var record auditRecord
+	if err := json.Unmarshal(content, &record); err != nil {
+		return auditRecord{}, fmt.Errorf("malformed record: %w", err)
+	}
+	record.Hash = string(hash)
+	return record, nil
+}
+
+// auditChain is the audit file and the state of its hash chain, shared by the
+// audit core and its children.
+type auditChain struct {
+	mu       sync.Mutex
+	file     *os.File
+	seq      uint64 // sequence number of the last record
+	prevHash string // hash of the last record
+}
+
+// openAuditChain opens an audit file for appending, resuming the chain from
+// its last record.
+//
+// Parameters:
+//   - path: the audit file path; created with mode 0600 if missing.
+//
+// Returns:
+//   - *auditChain positioned after the last record.
+//   - error if the file cannot be opened or its last record is invalid.
+func openAuditChain(
+	path string,
+) (*auditChain, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	chain := &auditChain{file: file, prevHash: auditGenesisHash}
+
+	reader := bufio.NewReader(file)
+	var last []byte
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			last = line
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to read audit log: %w", err)
+		}
+	}
+
+	if last != nil {
+		record, err := parseAuditRecord(last)
+		if err == nil && !bytes.HasSuffix(last, []byte("\n")) {
+			err = errors.New("incomplete record")
+		}
+		if err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("cannot resume audit log %s: last record: %w", path, err)
+		}
+		chain.seq, chain.prevHash = record.Seq, record.Hash
+	}
+	return chain, nil
+}
+
+// close closes the audit file.
+func (c *auditChain) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_ = c.file.Close()
+}
+
+// auditCore is a zapcore.Core appending hash-chained records to the audit file.
+type auditCore struct {
+	zapcore.LevelEnabler
+	enc   zapcore.Encoder
+	chain *auditChain
+}
+
+// With returns a copy of the core with the given fields added to every record.
+func (c *auditCore) With(
+	fields []zapcore.Field,
+) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+	return &auditCore{LevelEnabler: c.LevelEnabler, enc: enc, chain: c.chain}
+}
+
+// Check adds the core to the checked entry if the level is enabled.
+func (c *auditCore) Check(
+	ent zapcore.Entry,
+	ce *zapcore.CheckedEntry,
+) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write appends the record, chained to the previous one, and syncs the file.
+func (c *auditCore) Write(
+	ent zapcore.Entry,
+	fields []zapcore.Field,
+) error {
+	c.chain.mu.Lock()
+	defer c.chain.mu.Unlock()
+
+	seq := c.chain.seq + 1
+	fields = append(fields[:len(fields):len(fields)],
+		zap.Uint64(auditSeqKey, seq),
+		zap.String(auditPrevHashKey, c.chain.prevHash),
+	)
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+
+	content := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+
+	line := make([]byte, 0, len(content)+auditHashSuffixLen+1)
+	line = append(line, content[:len(content)-1]...)
+	line = append(line, `,"`+auditHashKey+`":"`...)
+	line = append(line, hash...)
+	line = append(line, "\"}\n"...)
+
+	if _, err := c.chain.file.Write(line); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	if err := c.chain.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync audit log: %w", err)
+	}
+	c.chain.seq, c.chain.prevHash = seq, hash
+	return nil
+}
+
+// Sync flushes the audit file to disk.
+func (c *auditCore) Sync() error {
+	c.chain.mu.Lock()
+	defer c.chain.mu.Unlock()
+	return c.chain.file.Sync()
+}
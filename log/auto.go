@@ -0,0 +1,98 @@
+package golog
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/kubensage/common/cli"
+	"go.uber.org/zap"
+)
+
+// EnvVar is the environment variable read by SetupAutoLogger to choose between
+// the development and production loggers.
+const EnvVar = "KUBENSAGE_ENV"
+
+const (
+	// EnvDevelopment selects the development logger ("dev" is also accepted).
+	EnvDevelopment = "development"
+
+	// EnvProduction selects the production logger ("prod" is also accepted).
+	EnvProduction = "production"
+)
+
+// SetupAutoLogger creates a standard output logger suited to where the process
+// runs, so that main functions need not pick one themselves:
+//
+//   - development (see SetupDevLogger) if EnvVar is "development" or "dev";
+//   - production, JSON-encoded, if EnvVar is "production" or "prod";
+//   - otherwise, development if standard output is a terminal and production
+//     if it is not (e.g., in a container or under systemd).
+//
+// The remaining settings, including the level, come from cfg.
+//
+// Parameters:
+//   - cfg: the logging configuration (standard output only). It is not modified.
+//
+// Returns:
+//   - *zap.Logger configured for the detected environment.
+//   - *Handle to adjust the logger at runtime (e.g., its level).
+//
+// Panics if the logger cannot be created or EnvVar holds an unknown value. Use
+// NewAutoLogger to handle the error instead.
+func SetupAutoLogger(
+	cfg *gocli.LogStdConfig,
+) (*zap.Logger, *Handle) {
+	logger, handle, err := NewAutoLogger(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create logger: %v", err)
+	}
+	return logger, handle
+}
+
+// NewAutoLogger is like SetupAutoLogger but returns an error instead of
+// terminating the application.
+func NewAutoLogger(
+	cfg *gocli.LogStdConfig,
+) (*zap.Logger, *Handle, error) {
+	dev, err := detectDevelopment()
+	if err != nil {
+		return nil, nil, err
+	}
+	if dev {
+		return NewDevLogger(cfg)
+	}
+
+	prodCfg := *cfg
+	prodCfg.LogDev = false
+	prodCfg.LogEncoder = EncoderJSON
+	return NewStdLogger(&prodCfg)
+}
+
+// detectDevelopment reports whether the process runs in development, from
+// EnvVar or, when it is not set, from whether standard output is a terminal.
+//
+// Returns:
+//   - true for development, false for production.
+//   - error if EnvVar holds an unknown value.
+func detectDevelopment() (bool, error) {
+	switch env := strings.ToLower(strings.TrimSpace(os.Getenv(EnvVar))); env {
+	case EnvDevelopment, "dev":
+		return true, nil
+	case EnvProduction, "prod":
+		return false, nil
+	case "":
+		return isTerminal(os.Stdout), nil
+	default:
+		return false, fmt.Errorf("invalid %s %q (expected %q or %q)", EnvVar, env, EnvDevelopment, EnvProduction)
+	}
+}
+
+// isTerminal reports whether f is a character device, such as a terminal.
+func isTerminal(
+	f *os.File,
+) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,216 @@
+package golog
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// exportMaxRetries is the number of additional attempts made for a failed export.
+	exportMaxRetries = 3
+
+	// exportInitialBackoff is the delay before the first retry; it doubles on every attempt.
+	exportInitialBackoff = time.Second
+)
+
+// batcher queues records of type T and hands them to a send function in batches.
+// It is the shared machinery behind the network sinks (OTLP, Loki, ...).
+//
+// Records are queued without blocking the caller; when the queue is full they
+// are dropped and counted, so a slow or unreachable backend never stalls the
+// application. Batches are sent when they reach batchSize, when the flush
+// interval elapses, or when flush is called explicitly.
+type batcher[T any] struct {
+	name      string             // sink name used in error reports
+	batchSize int                // maximum records per send
+	send      func([]T) error    // delivers one batch
+	queue     chan T             // pending records
+	flushCh   chan chan struct{} // explicit flush requests
+	done      chan struct{}      // closed by stop
+	stopOnce  sync.Once          // guards done
+	wg        sync.WaitGroup     // tracks the run goroutine
+	dropped   atomic.Uint64      // records dropped because the queue was full
+	inflight  atomic.Int64       // records collected by run but not sent yet
+	stats     sinkStats          // send failures, see Handle.Health
+}
+
+// liveBatchers tracks the running batchers, so that FlushOnShutdown can report
+// records that are still buffered.
+var liveBatchers sync.Map // map[interface{ pending() int }]struct{}
+
+// newBatcher creates a batcher and starts its background goroutine.
+//
+// Parameters:
+//   - name: sink name used in error reports.
+//   - batchSize: maximum number of records per batch (default 512 when not positive).
+//   - interval: maximum time a record waits before being sent (default 5s when not positive).
+//   - send: function delivering a batch; the slice is reused after it returns.
+//
+// Returns:
+//   - *batcher[T] ready to accept records.
+func newBatcher[T any](
+	name string,
+	batchSize int,
+	interval time.Duration,
+	send func([]T) error,
+) *batcher[T] {
+	if batchSize <= 0 {
+		batchSize = 512
+	}
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	b := &batcher[T]{
+		name:      name,
+		batchSize: batchSize,
+		send:      send,
+		queue:     make(chan T, batchSize*4),
+		flushCh:   make(chan chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	liveBatchers.Store(b, struct{}{})
+	b.wg.Add(1)
+	go b.run(interval)
+	return b
+}
+
+// enqueue adds a record to the send queue.
+//
+// Returns:
+//   - error if the queue is full and the record was dropped.
+func (b *batcher[T]) enqueue(
+	record T,
+) error {
+	select {
+	case b.queue <- record:
+		return nil
+	default:
+		b.dropped.Add(1)
+		return fmt.Errorf("%s queue full, log record dropped", b.name)
+	}
+}
+
+// flush blocks until every record queued so far has been sent (or has failed).
+func (b *batcher[T]) flush() {
+	ack := make(chan struct{})
+	select {
+	case b.flushCh <- ack:
+		<-ack
+	case <-b.done:
+	}
+}
+
+// stop sends pending records and terminates the background goroutine.
+func (b *batcher[T]) stop() {
+	b.stopOnce.Do(func() {
+		close(b.done)
+	})
+	b.wg.Wait()
+	liveBatchers.Delete(b)
+}
+
+// pending returns the number of records queued or collected but not sent yet.
+func (b *batcher[T]) pending() int {
+	return len(b.queue) + int(b.inflight.Load())
+}
+
+// Dropped returns the number of records dropped because the queue was full.
+func (b *batcher[T]) Dropped() uint64 {
+	return b.dropped.Load()
+}
+
+// sendStats returns the send failures of the batcher.
+func (b *batcher[T]) sendStats() *sinkStats {
+	return &b.stats
+}
+
+// run is the loop collecting records into batches.
+func (b *batcher[T]) run(
+	interval time.Duration,
+) {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	batch := make([]T, 0, b.batchSize)
+	sendBatch := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := b.send(batch); err != nil {
+			b.stats.fail(err.Error())
+			fmt.Fprintf(os.Stderr, "golog: %s: failed to send %d log records: %v\n", b.name, len(batch), err)
+		}
+		batch = batch[:0]
+		b.inflight.Store(0)
+	}
+	add := func(record T) {
+		batch = append(batch, record)
+		b.inflight.Store(int64(len(batch)))
+		if len(batch) >= b.batchSize {
+			sendBatch()
+		}
+	}
+	drain := func() {
+		for {
+			select {
+			case record := <-b.queue:
+				add(record)
+			default:
+				sendBatch()
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case record := <-b.queue:
+			add(record)
+		case <-ticker.C:
+			sendBatch()
+		case ack := <-b.flushCh:
+			drain()
+			close(ack)
+		case <-b.done:
+			drain()
+			return
+		}
+	}
+}
+
+// retryWithBackoff runs attempt until it succeeds, reports a non-retryable error,
+// or exportMaxRetries retries have been made. The delay between attempts starts
+// at exportInitialBackoff and doubles every time.
+//
+// Parameters:
+//   - attempt: performs one try and reports whether a failure may be retried.
+//
+// Returns:
+//   - the error of the last attempt, nil on success.
+func retryWithBackoff(
+	attempt func() (bool, error),
+) error {
+	backoff := exportInitialBackoff
+	for i := 0; ; i++ {
+		retry, err := attempt()
+		if err == nil || !retry || i == exportMaxRetries {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// retryableStatus reports whether an HTTP status code indicates a transient failure.
+func retryableStatus(
+	code int,
+) bool {
+	return code == 429 || code >= 500
+}
@@ -0,0 +1,126 @@
+package golog
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/exp/zapslog"
+	"go.uber.org/zap/zapcore"
+)
+
+// stdLogCallerSkip is the number of frames between the caller of a standard
+// library log function (e.g., log.Printf) and stdLogWriter.Write.
+const stdLogCallerSkip = 3
+
+// stdLogLevelPrefixes maps the level markers commonly found at the start of
+// standard library log messages to zap levels.
+var stdLogLevelPrefixes = []struct {
+	prefix string
+	level  zapcore.Level
+}{
+	{"debug", zapcore.DebugLevel},
+	{"info", zapcore.InfoLevel},
+	{"warning", zapcore.WarnLevel},
+	{"warn", zapcore.WarnLevel},
+	{"error", zapcore.ErrorLevel},
+	{"err", zapcore.ErrorLevel},
+	{"fatal", zapcore.ErrorLevel},
+	{"panic", zapcore.ErrorLevel},
+}
+
+// RedirectStdLog sends the output of the standard library log package to
+// logger, so that third-party libraries using it end up in the same pipeline.
+//
+// Messages are logged at info level unless they start with a level marker such
+// as "[ERROR]", "WARN:" or "debug:" (case-insensitive), which is stripped and
+// used as the entry level. Fatal and panic markers are logged at error level:
+// the process exit or panic is left to the log package.
+//
+// Parameters:
+//   - logger: the logger receiving the messages.
+//
+// Returns:
+//   - a function restoring the previous output, prefix and flags of the log package.
+//
+// Example:
+//
+//	defer golog.RedirectStdLog(logger)()
+func RedirectStdLog(
+	logger *zap.Logger,
+) func() {
+	flags, prefix, writer := log.Flags(), log.Prefix(), log.Writer()
+
+	log.SetFlags(0)
+	log.SetPrefix("")
+	log.SetOutput(&stdLogWriter{logger: logger.WithOptions(zap.AddCallerSkip(stdLogCallerSkip))})
+
+	return func() {
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+		log.SetOutput(writer)
+	}
+}
+
+// stdLogWriter is the io.Writer installed by RedirectStdLog.
+type stdLogWriter struct {
+	logger *zap.Logger
+}
+
+// Write logs one standard library log message.
+func (w *stdLogWriter) Write(
+	p []byte,
+) (int, error) {
+	msg := string(bytes.TrimSuffix(p, []byte("\n")))
+	level, msg := stdLogLevel(msg)
+	if ce := w.logger.Check(level, msg); ce != nil {
+		ce.Write()
+	}
+	return len(p), nil
+}
+
+// stdLogLevel extracts a leading level marker ("[WARN] ...", "error: ...") from
+// a message.
+//
+// Parameters:
+//   - msg: the message as written by the log package.
+//
+// Returns:
+//   - the level of the marker, info if there is none.
+//   - the message without the marker.
+func stdLogLevel(
+	msg string,
+) (zapcore.Level, string) {
+	for _, p := range stdLogLevelPrefixes {
+		n := len(p.prefix)
+		switch {
+		case len(msg) >= n+2 && msg[0] == '[' && msg[n+1] == ']' && strings.EqualFold(msg[1:n+1], p.prefix):
+			return p.level, strings.TrimSpace(msg[n+2:])
+		case len(msg) >= n+1 && msg[n] == ':' && strings.EqualFold(msg[:n], p.prefix):
+			return p.level, strings.TrimSpace(msg[n+1:])
+		}
+	}
+	return zapcore.InfoLevel, msg
+}
+
+// NewSlogHandler returns a log/slog handler writing to logger, so that libraries
+// using slog end up in the same pipeline. slog levels are mapped to the nearest
+// zap level (e.g., slog.LevelWarn to warn), and attribute groups become nested
+// objects.
+//
+// Parameters:
+//   - logger: the logger receiving the records; its name and level apply.
+//
+// Returns:
+//   - slog.Handler to pass to slog.New.
+//
+// Example:
+//
+//	slog.SetDefault(slog.New(golog.NewSlogHandler(logger)))
+func NewSlogHandler(
+	logger *zap.Logger,
+) slog.Handler {
+	return zapslog.NewHandler(logger.Core(), zapslog.WithName(logger.Name()))
+}
@@ -0,0 +1,30 @@
+package golog
+
+import (
+	"testing"
+)
+
+func BenchmarkStdLogLevel(b *testing.B) {
+	messages := []string{
+		"[WARN] connection reset by peer",
+		"error: dial tcp 10.0.0.1:443: i/o timeout",
+		"http: TLS handshake error from 10.0.0.2:51234: EOF",
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, msg := range messages {
+			stdLogLevel(msg)
+		}
+	}
+}
+
+func BenchmarkStdLogWriter(b *testing.B) {
+	w := &stdLogWriter{logger: newDiscardLogger()}
+	line := []byte("[WARN] connection reset by peer\n")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = w.Write(line)
+	}
+}
@@ -0,0 +1,34 @@
+package golog
+
+import (
+	"runtime/debug"
+
+	"go.uber.org/zap"
+)
+
+// buildInfoFields returns the build metadata embedded in the binary by the Go
+// toolchain, so that log lines can be correlated with the exact build:
+// the main module version, the VCS revision, commit time and dirty flag, and
+// the build tags. Settings the toolchain did not record (e.g., VCS data for
+// builds outside a repository) are omitted.
+func buildInfoFields() []zap.Field {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+
+	fields := []zap.Field{zap.String("module_version", info.Main.Version)}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			fields = append(fields, zap.String("vcs_revision", setting.Value))
+		case "vcs.time":
+			fields = append(fields, zap.String("vcs_time", setting.Value))
+		case "vcs.modified":
+			fields = append(fields, zap.Bool("vcs_dirty", setting.Value == "true"))
+		case "-tags":
+			fields = append(fields, zap.String("build_tags", setting.Value))
+		}
+	}
+	return fields
+}
@@ -0,0 +1,41 @@
+package golog
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// callerOptions returns the zap.Options annotating entries with their source location.
+//
+// Parameters:
+//   - addCaller: whether entries include the calling file and line.
+//   - callerSkip: extra stack frames to skip, for applications wrapping the logger.
+//   - stacktraceLevel: minimum level at which a stack trace is attached; empty disables stack traces.
+//
+// Returns:
+//   - []zap.Option to pass to zap.New or Logger.WithOptions.
+//   - error if stacktraceLevel is not a valid level.
+func callerOptions(
+	addCaller bool,
+	callerSkip int,
+	stacktraceLevel string,
+) ([]zap.Option, error) {
+	var opts []zap.Option
+	if addCaller {
+		opts = append(opts, zap.AddCaller())
+		if callerSkip > 0 {
+			opts = append(opts, zap.AddCallerSkip(callerSkip))
+		}
+	}
+
+	if stacktraceLevel != "" {
+		level, err := zapcore.ParseLevel(stacktraceLevel)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stacktrace level: %w", err)
+		}
+		opts = append(opts, zap.AddStacktrace(level))
+	}
+	return opts, nil
+}
@@ -0,0 +1,91 @@
+package golog
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// FieldRequestID is the field name under which WithRequestID stores the request ID.
+	FieldRequestID = "request_id"
+
+	// FieldTraceID is the field name under which WithTraceID stores the trace ID.
+	FieldTraceID = "trace_id"
+)
+
+// loggerKey is the context key under which the logger is stored.
+type loggerKey struct{}
+
+// WithContext returns a copy of ctx carrying logger, extended with the given
+// fields. Handlers can then retrieve it with FromContext anywhere down the call
+// stack instead of threading a logger parameter through every function.
+//
+// Parameters:
+//   - ctx: the parent context.
+//   - logger: the logger to store.
+//   - fields: optional fields attached to every entry of the stored logger.
+//
+// Returns:
+//   - context.Context carrying the logger.
+func WithContext(
+	ctx context.Context,
+	logger *zap.Logger,
+	fields ...zap.Field,
+) context.Context {
+	if len(fields) > 0 {
+		logger = logger.With(fields...)
+	}
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger stored in ctx by WithContext.
+//
+// Parameters:
+//   - ctx: the context to look up.
+//
+// Returns:
+//   - *zap.Logger stored in ctx, or the global zap.L() if there is none.
+func FromContext(
+	ctx context.Context,
+) *zap.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerKey{}).(*zap.Logger); ok {
+			return logger
+		}
+	}
+	return zap.L()
+}
+
+// WithFields returns a copy of ctx whose logger carries the given additional fields.
+//
+// Parameters:
+//   - ctx: the parent context, usually already populated by WithContext.
+//   - fields: fields attached to every entry of the logger.
+//
+// Returns:
+//   - context.Context carrying the extended logger.
+func WithFields(
+	ctx context.Context,
+	fields ...zap.Field,
+) context.Context {
+	return WithContext(ctx, FromContext(ctx), fields...)
+}
+
+// WithRequestID returns a copy of ctx whose logger tags every entry with the
+// given request ID under FieldRequestID.
+func WithRequestID(
+	ctx context.Context,
+	requestID string,
+) context.Context {
+	return WithFields(ctx, zap.String(FieldRequestID, requestID))
+}
+
+// WithTraceID returns a copy of ctx whose logger tags every entry with the
+// given trace ID under FieldTraceID.
+func WithTraceID(
+	ctx context.Context,
+	traceID string,
+) context.Context {
+	return WithFields(ctx, zap.String(FieldTraceID, traceID))
+}
@@ -0,0 +1,165 @@
+package golog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// deduper suppresses identical consecutive entries, in the spirit of syslogd:
+// when an entry repeats the previous one (same level, logger name and message),
+// it is dropped and counted, and a "last message repeated N times" summary is
+// written when a different entry arrives or when the flush interval elapses.
+// The summary carries the repeated message, the number of repetitions and the
+// time of the first and last ones.
+//
+// Fields are not compared, so entries differing only in their fields count as
+// repetitions. Entries above error level (panic, fatal) are never suppressed.
+type deduper struct {
+	mu       sync.Mutex
+	last     zapcore.Entry  // last entry written
+	lastCore zapcore.Core   // core the last entry was written to, receives the summary
+	repeated int            // repetitions of last suppressed since the previous summary
+	first    time.Time      // time of the first suppressed repetition
+	latest   time.Time      // time of the last suppressed repetition
+	done     chan struct{}  // closed by stop
+	stopOnce sync.Once      // guards done
+	wg       sync.WaitGroup // tracks the flush goroutine
+}
+
+// newDeduper creates a deduper and starts its flush goroutine.
+//
+// Parameters:
+//   - interval: maximum time a pending summary waits before being written.
+//
+// Returns:
+//   - *deduper whose option() is attached to a logger.
+func newDeduper(
+	interval time.Duration,
+) *deduper {
+	d := &deduper{done: make(chan struct{})}
+	d.wg.Add(1)
+	go d.run(interval)
+	return d
+}
+
+// option returns a zap.Option wrapping the logger core with deduplication.
+func (d *deduper) option() zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &dedupCore{Core: core, deduper: d}
+	})
+}
+
+// stop writes the pending summary and terminates the flush goroutine.
+func (d *deduper) stop() {
+	d.stopOnce.Do(func() {
+		close(d.done)
+	})
+	d.wg.Wait()
+}
+
+// run periodically writes the pending summary.
+func (d *deduper) run(
+	interval time.Duration,
+) {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.mu.Lock()
+			summary := d.takeSummary()
+			d.mu.Unlock()
+			summary()
+		case <-d.done:
+			d.mu.Lock()
+			summary := d.takeSummary()
+			d.mu.Unlock()
+			summary()
+			return
+		}
+	}
+}
+
+// takeSummary resets the repetition counter and returns a function writing the
+// corresponding summary, to be called without holding the lock. The caller must
+// hold d.mu.
+func (d *deduper) takeSummary() func() {
+	if d.repeated == 0 {
+		return func() {}
+	}
+
+	core, last, repeated, first, latest := d.lastCore, d.last, d.repeated, d.first, d.latest
+	d.repeated = 0
+
+	return func() {
+		ent := zapcore.Entry{
+			Level:      last.Level,
+			Time:       time.Now(),
+			LoggerName: last.LoggerName,
+			Message:    fmt.Sprintf("last message repeated %d times", repeated),
+		}
+		if ce := core.Check(ent, nil); ce != nil {
+			ce.Write(
+				zap.String("repeated_message", last.Message),
+				zap.Int("repeated", repeated),
+				zap.Time("first_repeated", first),
+				zap.Time("last_repeated", latest),
+			)
+		}
+	}
+}
+
+// dedupCore is the zapcore.Core suppressing repetitions through a shared deduper.
+type dedupCore struct {
+	zapcore.Core
+	deduper *deduper
+}
+
+// With returns a copy of the core with the given fields added to every entry.
+func (c *dedupCore) With(
+	fields []zapcore.Field,
+) zapcore.Core {
+	return &dedupCore{Core: c.Core.With(fields), deduper: c.deduper}
+}
+
+// Check suppresses the entry if it repeats the previous one, otherwise writes
+// the pending summary and delegates to the wrapped core.
+func (c *dedupCore) Check(
+	ent zapcore.Entry,
+	ce *zapcore.CheckedEntry,
+) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+	if ent.Level > zapcore.ErrorLevel {
+		return c.Core.Check(ent, ce)
+	}
+
+	d := c.deduper
+	d.mu.Lock()
+	if d.lastCore != nil &&
+		ent.Level == d.last.Level &&
+		ent.LoggerName == d.last.LoggerName &&
+		ent.Message == d.last.Message {
+		if d.repeated == 0 {
+			d.first = ent.Time
+		}
+		d.repeated++
+		d.latest = ent.Time
+		d.mu.Unlock()
+		return ce
+	}
+	summary := d.takeSummary()
+	d.last, d.lastCore = ent, c.Core
+	d.mu.Unlock()
+
+	summary()
+	return c.Core.Check(ent, ce)
+}
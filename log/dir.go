@@ -0,0 +1,165 @@
+package golog
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// defaultDirMode is the permission of log directories created by golog when
+// no mode is configured.
+const defaultDirMode os.FileMode = 0o755
+
+// defaultFileMode is the permission of log files when no mode is configured. It
+// matches the mode lumberjack creates files with.
+const defaultFileMode os.FileMode = 0o600
+
+// parseDirMode parses an octal permission string such as "0750".
+//
+// Parameters:
+//   - s: the octal mode; an empty string selects defaultDirMode.
+//
+// Returns:
+//   - os.FileMode holding the permission bits.
+//   - error if s is not a valid octal permission.
+func parseDirMode(
+	s string,
+) (os.FileMode, error) {
+	if s == "" {
+		return defaultDirMode, nil
+	}
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("invalid log directory mode %q (expected octal permissions, e.g. 0755)", s)
+	}
+	return os.FileMode(mode), nil
+}
+
+// parseFileMode parses an octal permission string such as "0640".
+//
+// Parameters:
+//   - s: the octal mode; an empty string selects defaultFileMode.
+//
+// Returns:
+//   - os.FileMode holding the permission bits.
+//   - error if s is not a valid octal permission.
+func parseFileMode(
+	s string,
+) (os.FileMode, error) {
+	if s == "" {
+		return defaultFileMode, nil
+	}
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("invalid log file mode %q (expected octal permissions, e.g. 0640)", s)
+	}
+	return os.FileMode(mode), nil
+}
+
+// parseFileOwner resolves an owner specification of the form "user",
+// "user:group" or ":group", where user and group are names or numeric IDs.
+//
+// Parameters:
+//   - s: the owner specification; an empty string leaves ownership unchanged.
+//
+// Returns:
+//   - the user and group IDs; -1 for the parts left unchanged.
+//   - error if a user or group cannot be found.
+func parseFileOwner(
+	s string,
+) (int, int, error) {
+	uid, gid := -1, -1
+	if s == "" {
+		return uid, gid, nil
+	}
+
+	userName, groupName, _ := strings.Cut(s, ":")
+	if userName != "" {
+		id, err := strconv.Atoi(userName)
+		if err != nil {
+			u, lookupErr := user.Lookup(userName)
+			if lookupErr != nil {
+				return 0, 0, fmt.Errorf("invalid log file owner %q: %w", s, lookupErr)
+			}
+			if id, err = strconv.Atoi(u.Uid); err != nil {
+				return 0, 0, fmt.Errorf("invalid log file owner %q: non-numeric uid %s", s, u.Uid)
+			}
+		}
+		uid = id
+	}
+	if groupName != "" {
+		id, err := strconv.Atoi(groupName)
+		if err != nil {
+			g, lookupErr := user.LookupGroup(groupName)
+			if lookupErr != nil {
+				return 0, 0, fmt.Errorf("invalid log file owner %q: %w", s, lookupErr)
+			}
+			if id, err = strconv.Atoi(g.Gid); err != nil {
+				return 0, 0, fmt.Errorf("invalid log file owner %q: non-numeric gid %s", s, g.Gid)
+			}
+		}
+		gid = id
+	}
+	return uid, gid, nil
+}
+
+// ensureLogDir creates the parent directory of a log file, and any missing
+// ancestors, with the given permissions (subject to the process umask).
+// Creating it up front surfaces permission problems at startup rather than on
+// the first write.
+//
+// Parameters:
+//   - path: the log file path.
+//   - mode: permission of the created directories; existing ones are left untouched.
+//
+// Returns:
+//   - error if the directory cannot be created.
+func ensureLogDir(
+	path string,
+	mode os.FileMode,
+) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, mode); err != nil {
+		return fmt.Errorf("failed to create log directory %s: %w", dir, err)
+	}
+	return nil
+}
+
+// prepareLogFile creates a log file if missing and applies its permissions and
+// ownership before lumberjack opens it. lumberjack gives the files it creates on
+// rotation the mode of the file they replace (and, on Linux, its owner), so the
+// settings persist across rotations.
+//
+// Parameters:
+//   - path: the log file path; its directory must exist.
+//   - mode: permission of the file, also applied to an existing file.
+//   - uid: owner user ID, or -1 to leave it unchanged.
+//   - gid: owner group ID, or -1 to leave it unchanged.
+//
+// Returns:
+//   - error if the file cannot be created, or its mode or owner cannot be set.
+func prepareLogFile(
+	path string,
+	mode os.FileMode,
+	uid int,
+	gid int,
+) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, mode)
+	if err != nil {
+		return fmt.Errorf("failed to create log file %s: %w", path, err)
+	}
+	_ = f.Close()
+
+	if err := os.Chmod(path, mode); err != nil {
+		return fmt.Errorf("failed to set mode of log file %s: %w", path, err)
+	}
+	if uid >= 0 || gid >= 0 {
+		if err := os.Chown(path, uid, gid); err != nil {
+			return fmt.Errorf("failed to set owner of log file %s: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/kubensage/common/cli"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// elasticRequestTimeout bounds a single bulk request.
+const elasticRequestTimeout = 30 * time.Second
+
+// elasticDoc is a single encoded log entry waiting to be indexed.
+type elasticDoc struct {
+	index string // destination index, derived from the entry date
+	body  []byte // JSON-encoded entry
+}
+
+// elasticIndexer batches documents and writes them through the Elasticsearch
+// bulk API (POST <url>/_bulk).
+type elasticIndexer struct {
+	*batcher[elasticDoc]
+	url      string
+	username string
+	password string
+	client   *http.Client
+}
+
+// WithElastic tees an Elasticsearch sink into the given logger. Entries are
+// written through the bulk API into one index per day, named
+// "<cfg.ElasticIndexPrefix>-YYYY.MM.DD", so that index templates and ILM
+// policies matching "<prefix>-*" apply to them.
+//
+// The sink's level follows the handle's shared level, and its drop counter and
+// shutdown are registered on the handle (see Handle.Dropped and Handle.Close).
+//
+// Parameters:
+//   - logger: the logger to extend.
+//   - handle: the handle returned alongside the logger.
+//   - cfg: the Elasticsearch configuration.
+//
+// Returns:
+//   - *zap.Logger writing to its previous cores and to Elasticsearch.
+//   - error if the configuration is incomplete.
+func WithElastic(
+	logger *zap.Logger,
+	handle *Handle,
+	cfg *gocli.LogElasticConfig,
+) (*zap.Logger, error) {
+	if cfg.ElasticURL == "" {
+		return nil, fmt.Errorf("elasticsearch url is required")
+	}
+	if cfg.ElasticIndexPrefix == "" {
+		return nil, fmt.Errorf("elasticsearch index prefix is required")
+	}
+
+	indexer := &elasticIndexer{
+		url:      strings.TrimSuffix(cfg.ElasticURL, "/") + "/_bulk",
+		username: cfg.ElasticUsername,
+		password: cfg.ElasticPassword.Value(),
+		client:   &http.Client{Timeout: elasticRequestTimeout},
+	}
+	indexer.batcher = newBatcher(SinkElastic, cfg.ElasticBatchSize, cfg.ElasticFlushInterval, indexer.bulk)
+
+	handle.onClose(indexer.stop)
+	handle.trackDropped(SinkElastic, indexer.Dropped)
+	handle.trackErrors(SinkElastic, indexer.sendStats())
+
+	core := handle.instrument(SinkElastic, newElasticCore(indexer, cfg.ElasticIndexPrefix, handle.sinkEnabler(SinkElastic)))
+	logger = logger.WithOptions(teeOption(core))
+	handle.registry = handle.registry.withBase(logger)
+	return logger, nil
+}
+
+// bulk sends one batch, retrying on network errors, 429 and 5xx responses.
+// Per-document failures reported by Elasticsearch are returned without retry.
+func (i *elasticIndexer) bulk(
+	batch []elasticDoc,
+) error {
+	var body bytes.Buffer
+	for _, doc := range batch {
+		fmt.Fprintf(&body, `{"index":{"_index":%q}}`+"\n", doc.index)
+		body.Write(doc.body)
+		body.WriteByte('\n')
+	}
+	payload := body.Bytes()
+
+	return retryWithBackoff(func() (bool, error) {
+		req, err := http.NewRequest(http.MethodPost, i.url, bytes.NewReader(payload))
+		if err != nil {
+			return false, err
+		}
+		req.Header.Set("Content-Type", "application/x-ndjson")
+		if i.username != "" {
+			req.SetBasicAuth(i.username, i.password)
+		}
+
+		resp, err := i.client.Do(req)
+		if err != nil {
+			return true, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return retryableStatus(resp.StatusCode), fmt.Errorf("elasticsearch returned %s", resp.Status)
+		}
+
+		var result struct {
+			Errors bool `json:"errors"`
+		}
+		if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err == nil && result.Errors {
+			return false, fmt.Errorf("elasticsearch rejected some documents of the bulk request")
+		}
+		return false, nil
+	})
+}
+
+// dailyIndex names the daily indices of an elasticCore (<prefix>-2006.01.02),
+// remembering the name of the last day so that it is not rebuilt for every entry.
+type dailyIndex struct {
+	prefix string
+	last   atomic.Pointer[dailyIndexName]
+}
+
+// dailyIndexName is the index name of a UTC day.
+type dailyIndexName struct {
+	year  int
+	month time.Month
+	day   int
+	name  string
+}
+
+// name returns the index of entries written at t.
+func (d *dailyIndex) name(
+	t time.Time,
+) string {
+	year, month, day := t.UTC().Date()
+	if last := d.last.Load(); last != nil && last.year == year && last.month == month && last.day == day {
+		return last.name
+	}
+	name := &dailyIndexName{year: year, month: month, day: day, name: d.prefix + "-" + t.UTC().Format("2006.01.02")}
+	d.last.Store(name)
+	return name.name
+}
+
+// elasticCore is a zapcore.Core encoding entries as Elasticsearch documents.
+type elasticCore struct {
+	zapcore.LevelEnabler
+	encoder zapcore.Encoder
+	indexer *elasticIndexer
+	index   *dailyIndex
+}
+
+// newElasticCore returns a core indexing entries through the given indexer.
+func newElasticCore(
+	indexer *elasticIndexer,
+	indexPrefix string,
+	level zapcore.LevelEnabler,
+) zapcore.Core {
+	encoderCfg := zap.NewProductionEncoderConfig()
+	encoderCfg.TimeKey = "@timestamp"
+	encoderCfg.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+
+	return &elasticCore{
+		LevelEnabler: level,
+		encoder:      zapcore.NewJSONEncoder(encoderCfg),
+		indexer:      indexer,
+		index:        &dailyIndex{prefix: indexPrefix},
+	}
+}
+
+// With returns a copy of the core with the given fields added to every entry.
+func (c *elasticCore) With(
+	fields []zapcore.Field,
+) zapcore.Core {
+	clone := *c
+	clone.encoder = c.encoder.Clone()
+	for i := range fields {
+		fields[i].AddTo(clone.encoder)
+	}
+	return &clone
+}
+
+// Check adds the core to the checked entry if the entry level is enabled.
+func (c *elasticCore) Check(
+	ent zapcore.Entry,
+	ce *zapcore.CheckedEntry,
+) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write encodes the entry and queues it for the daily index. Entries above error
+// level (panic, fatal) are flushed synchronously since the process may exit.
+func (c *elasticCore) Write(
+	ent zapcore.Entry,
+	fields []zapcore.Field,
+) error {
+	buf, err := c.encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	body := bytes.Clone(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	buf.Free()
+
+	err = c.indexer.enqueue(elasticDoc{
+		index: c.index.name(ent.Time),
+		body:  body,
+	})
+
+	if ent.Level > zapcore.ErrorLevel {
+		c.indexer.flush()
+	}
+	return err
+}
+
+// Sync blocks until all queued documents have been sent.
+func (c *elasticCore) Sync() error {
+	c.indexer.flush()
+	return nil
+}
@@ -0,0 +1,32 @@
+package golog
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func BenchmarkElasticCoreWrite(b *testing.B) {
+	indexer := &elasticIndexer{}
+	indexer.batcher = newBatcher(SinkElastic, 512, time.Second, func([]elasticDoc) error { return nil })
+	defer indexer.stop()
+
+	core := newElasticCore(indexer, "kubensage", zapcore.DebugLevel)
+	ent := zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Now(), Message: "metrics sent"}
+	fields := []zapcore.Field{zap.String("node", "worker-1"), zap.Int("samples", 120)}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := core.Write(ent, fields); err != nil {
+			b.Fatal(err)
+		}
+		if i%512 == 511 { // keep the queue (4 batches) from filling up
+			b.StopTimer()
+			indexer.flush()
+			b.StartTimer()
+		}
+	}
+}
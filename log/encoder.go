@@ -0,0 +1,214 @@
+package golog
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kubensage/common/cli"
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// EncoderJSON produces one JSON object per log entry. This is the default
+	// and the format expected by log collectors in production.
+	EncoderJSON = "json"
+
+	// EncoderConsole produces human-readable, tab-separated output intended
+	// for local development.
+	EncoderConsole = "console"
+
+	// EncoderECS produces JSON with keys following the Elastic Common Schema
+	// (e.g., "@timestamp", "log.level", "message"), so that entries shipped to
+	// Elasticsearch fit existing ECS dashboards without an ingest pipeline.
+	EncoderECS = "ecs"
+)
+
+// ecsVersion is the Elastic Common Schema version reported by EncoderECS.
+const ecsVersion = "8.11.0"
+
+const (
+	// TimeFormatISO8601 encodes timestamps as ISO8601 strings with millisecond
+	// precision (e.g., "2024-05-01T12:00:00.000Z"). This is the default.
+	TimeFormatISO8601 = "iso8601"
+
+	// TimeFormatRFC3339 encodes timestamps as RFC3339 strings with second precision.
+	TimeFormatRFC3339 = "rfc3339"
+
+	// TimeFormatRFC3339Nano encodes timestamps as RFC3339 strings with nanosecond precision.
+	TimeFormatRFC3339Nano = "rfc3339nano"
+
+	// TimeFormatEpochMillis encodes timestamps as floating-point milliseconds since the Unix epoch.
+	TimeFormatEpochMillis = "epochmillis"
+)
+
+// newEncoder builds the zapcore.Encoder matching the requested encoding mode.
+//
+// Parameters:
+//   - encoding: EncoderJSON, EncoderConsole or EncoderECS. An empty string selects JSON.
+//   - color: whether levels should be colorized (only honored by the console encoder).
+//   - encodeTime: timestamp encoder, usually from newTimeEncoder; nil selects ISO8601 local time.
+//
+// Returns:
+//   - zapcore.Encoder for the given mode.
+//   - error if the encoding mode is unknown.
+func newEncoder(
+	encoding string,
+	color bool,
+	encodeTime zapcore.TimeEncoder,
+) (zapcore.Encoder, error) {
+	if encodeTime == nil {
+		encodeTime = zapcore.ISO8601TimeEncoder
+	}
+
+	encoderCfg := zap.NewProductionEncoderConfig()
+	encoderCfg.TimeKey = "timestamp"
+	encoderCfg.EncodeTime = encodeTime
+
+	switch encoding {
+	case "", EncoderJSON:
+		return zapcore.NewJSONEncoder(encoderCfg), nil
+	case EncoderConsole:
+		if color {
+			encoderCfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		} else {
+			encoderCfg.EncodeLevel = zapcore.CapitalLevelEncoder
+		}
+		return zapcore.NewConsoleEncoder(encoderCfg), nil
+	case EncoderECS:
+		return newECSEncoder(encodeTime), nil
+	default:
+		return nil, fmt.Errorf("invalid log encoder %q (expected %q, %q or %q)", encoding, EncoderJSON, EncoderConsole, EncoderECS)
+	}
+}
+
+// newECSEncoder builds a JSON encoder using Elastic Common Schema field names.
+// Every entry carries the ecs.version field.
+//
+// Parameters:
+//   - encodeTime: timestamp encoder of the @timestamp field.
+//
+// Returns:
+//   - zapcore.Encoder producing ECS documents.
+func newECSEncoder(
+	encodeTime zapcore.TimeEncoder,
+) zapcore.Encoder {
+	encoderCfg := zap.NewProductionEncoderConfig()
+	encoderCfg.TimeKey = "@timestamp"
+	encoderCfg.LevelKey = "log.level"
+	encoderCfg.NameKey = "log.logger"
+	encoderCfg.CallerKey = "log.origin.file.name"
+	encoderCfg.FunctionKey = zapcore.OmitKey
+	encoderCfg.MessageKey = "message"
+	encoderCfg.StacktraceKey = "error.stack_trace"
+	encoderCfg.EncodeTime = encodeTime
+
+	enc := &ecsEncoder{Encoder: zapcore.NewJSONEncoder(encoderCfg)}
+	enc.AddString("ecs.version", ecsVersion)
+	return enc
+}
+
+// ecsEncoder renames the "error" field added by zap.Error to "error.message",
+// since ECS defines error as an object (error.message, error.stack_trace) and
+// Elasticsearch rejects documents mixing both.
+type ecsEncoder struct {
+	zapcore.Encoder
+}
+
+// Clone copies the encoder.
+func (e *ecsEncoder) Clone() zapcore.Encoder {
+	return &ecsEncoder{Encoder: e.Encoder.Clone()}
+}
+
+// AddString adds a string field, renaming the error key.
+func (e *ecsEncoder) AddString(
+	key string,
+	value string,
+) {
+	e.Encoder.AddString(ecsKey(key), value)
+}
+
+// EncodeEntry encodes an entry, renaming the error key of its fields.
+func (e *ecsEncoder) EncodeEntry(
+	ent zapcore.Entry,
+	fields []zapcore.Field,
+) (*buffer.Buffer, error) {
+	for i, field := range fields {
+		if key := ecsKey(field.Key); key != field.Key {
+			fields = append([]zapcore.Field(nil), fields...)
+			fields[i].Key = key
+		}
+	}
+	return e.Encoder.EncodeEntry(ent, fields)
+}
+
+// ecsKey maps zap's conventional error key to its ECS name.
+func ecsKey(
+	key string,
+) string {
+	if key == "error" {
+		return "error.message"
+	}
+	return key
+}
+
+// newTimeEncoder builds the timestamp encoder for the given format and time zone.
+//
+// Parameters:
+//   - format: TimeFormatISO8601, TimeFormatRFC3339, TimeFormatRFC3339Nano,
+//     TimeFormatEpochMillis, or a Go layout (see gocli.ParseTimeFormat).
+//     An empty string selects ISO8601.
+//   - loc: the time zone timestamps are converted to, or nil for the local
+//     time zone. Ignored by TimeFormatEpochMillis, which does not carry a zone.
+//
+// Returns:
+//   - zapcore.TimeEncoder for the given format.
+//   - error if the format is unknown.
+func newTimeEncoder(
+	format string,
+	loc *time.Location,
+) (zapcore.TimeEncoder, error) {
+	if format == "" {
+		format = TimeFormatISO8601
+	}
+	layout, err := gocli.ParseTimeFormat(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log time format %q (expected %q, %q, %q, %q or a Go layout)",
+			format, TimeFormatISO8601, TimeFormatRFC3339, TimeFormatRFC3339Nano, TimeFormatEpochMillis)
+	}
+
+	var encode zapcore.TimeEncoder
+	switch layout {
+	case TimeFormatISO8601:
+		encode = zapcore.ISO8601TimeEncoder
+	case TimeFormatRFC3339:
+		encode = zapcore.RFC3339TimeEncoder
+	case TimeFormatRFC3339Nano:
+		encode = zapcore.RFC3339NanoTimeEncoder
+	case TimeFormatEpochMillis:
+		return zapcore.EpochMillisTimeEncoder, nil
+	default:
+		encode = zapcore.TimeEncoderOfLayout(layout)
+	}
+
+	if loc == nil {
+		return encode, nil
+	}
+	return func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+		encode(t.In(loc), enc)
+	}, nil
+}
+
+// devTimeLayout is the short timestamp layout of the development encoder.
+const devTimeLayout = "15:04:05.000"
+
+// newDevEncoder builds the encoder used in development mode: zap's development
+// console output with colored levels, short timestamps and short caller paths.
+func newDevEncoder() zapcore.Encoder {
+	encoderCfg := zap.NewDevelopmentEncoderConfig()
+	encoderCfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	encoderCfg.EncodeTime = zapcore.TimeEncoderOfLayout(devTimeLayout)
+	encoderCfg.EncodeCaller = zapcore.ShortCallerEncoder
+	return zapcore.NewConsoleEncoder(encoderCfg)
+}
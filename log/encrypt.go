@@ -0,0 +1,414 @@
+package golog
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	// encryptedSuffix is appended to the name of encrypted log archives.
+	encryptedSuffix = ".enc"
+
+	// encryptMagic identifies the encrypted archive format (version 1).
+	encryptMagic = "KSLOGv1\x00"
+
+	// encryptChunkSize is the plaintext size of an encrypted chunk.
+	encryptChunkSize = 64 * 1024
+
+	// encryptScanInterval is how often rotated files are looked for, in addition
+	// to the scans triggered by rotations.
+	encryptScanInterval = time.Minute
+
+	// lumberjackTimeFormat is the timestamp layout lumberjack uses in backup names.
+	lumberjackTimeFormat = "2006-01-02T15-04-05.000"
+)
+
+// loadEncryptionKey reads the 32-byte AES-256 key used to encrypt rotated logs.
+//
+// The key may be given raw (32 bytes, files only), hex-encoded (64 characters)
+// or base64-encoded. Surrounding whitespace is ignored.
+//
+// Parameters:
+//   - keyFile: path of a file holding the key; takes precedence when set.
+//   - keyEnv: name of an environment variable holding the key.
+//
+// Returns:
+//   - the decoded key.
+//   - error if the key is missing or is not 32 bytes long once decoded.
+func loadEncryptionKey(
+	keyFile string,
+	keyEnv string,
+) ([]byte, error) {
+	var raw []byte
+	switch {
+	case keyFile != "":
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read log encryption key: %w", err)
+		}
+		raw = data
+	case keyEnv != "":
+		value, ok := os.LookupEnv(keyEnv)
+		if !ok {
+			return nil, fmt.Errorf("log encryption key variable %s is not set", keyEnv)
+		}
+		raw = []byte(value)
+	default:
+		return nil, errors.New("no log encryption key configured")
+	}
+
+	if len(raw) == 32 {
+		return raw, nil
+	}
+	text := strings.TrimSpace(string(raw))
+	if key, err := hex.DecodeString(text); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(text); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	return nil, errors.New("log encryption key must be 32 bytes, raw, hex or base64 encoded")
+}
+
+// archiveEncryptor encrypts the files rotated by lumberjack with AES-256-GCM and
+// removes their plaintext, so that node-local log archives are encrypted at
+// rest. The active log file is left in clear.
+//
+// Archives are named after the rotated file with encryptedSuffix appended
+// (e.g., app-2024-05-01T00-00-00.000.log.gz.enc) and can be decrypted with
+// DecryptLogArchive. Since lumberjack does not recognize them, MaxBackups and
+// MaxAge are applied to encrypted archives by the encryptor itself.
+type archiveEncryptor struct {
+	key      []byte
+	rotators []*lumberjack.Logger
+	trigger  chan struct{}  // requests a scan
+	done     chan struct{}  // closed by stop
+	stopOnce sync.Once      // guards done
+	wg       sync.WaitGroup // tracks the scan goroutine
+}
+
+// newArchiveEncryptor creates an encryptor for the files of the given rotators
+// and starts its background goroutine.
+func newArchiveEncryptor(
+	key []byte,
+	rotators []*lumberjack.Logger,
+) *archiveEncryptor {
+	e := &archiveEncryptor{
+		key:      key,
+		rotators: rotators,
+		trigger:  make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	e.wg.Add(1)
+	go e.run()
+	return e
+}
+
+// notify requests a scan without blocking.
+func (e *archiveEncryptor) notify() {
+	select {
+	case e.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// stop encrypts the remaining rotated files and terminates the goroutine.
+func (e *archiveEncryptor) stop() {
+	e.stopOnce.Do(func() {
+		close(e.done)
+	})
+	e.wg.Wait()
+}
+
+// run scans for rotated files periodically and on request.
+func (e *archiveEncryptor) run() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(encryptScanInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			e.scan()
+		case <-e.trigger:
+			e.scan()
+		case <-e.done:
+			e.scan()
+			return
+		}
+	}
+}
+
+// scan encrypts the rotated files of every rotator and prunes old archives.
+func (e *archiveEncryptor) scan() {
+	for _, rotator := range e.rotators {
+		if err := e.scanRotator(rotator); err != nil {
+			log.Printf("Failed to encrypt rotated log files of %s: %v", rotator.Filename, err)
+		}
+	}
+}
+
+// scanRotator encrypts the rotated files of one rotator and prunes its archives.
+func (e *archiveEncryptor) scanRotator(
+	rotator *lumberjack.Logger,
+) error {
+	dir := filepath.Dir(rotator.Filename)
+	base := filepath.Base(rotator.Filename)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	names := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		names[entry.Name()] = true
+	}
+
+	type archive struct {
+		path string
+		ts   time.Time
+	}
+	var archives []archive
+	var errs []error
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == base || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		encrypted := strings.HasSuffix(name, encryptedSuffix)
+		plain := strings.TrimSuffix(name, encryptedSuffix)
+		compressed := strings.HasSuffix(plain, ext+".gz")
+		stamp := strings.TrimSuffix(strings.TrimSuffix(plain, ".gz"), ext)
+		if !strings.HasSuffix(strings.TrimSuffix(plain, ".gz"), ext) {
+			continue
+		}
+		ts, err := time.Parse(lumberjackTimeFormat, strings.TrimPrefix(stamp, prefix))
+		if err != nil {
+			continue // not a lumberjack backup
+		}
+
+		path := filepath.Join(dir, name)
+		switch {
+		case encrypted:
+			archives = append(archives, archive{path: path, ts: ts})
+			continue
+		case compressed && names[strings.TrimSuffix(name, ".gz")]:
+			continue // lumberjack is still compressing it
+		case !compressed && rotator.Compress:
+			continue // lumberjack will compress it first
+		}
+
+		if err := e.encryptFile(path); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		archives = append(archives, archive{path: path + encryptedSuffix, ts: ts})
+	}
+
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].ts.After(archives[j].ts)
+	})
+	cutoff := time.Now().Add(-time.Duration(rotator.MaxAge) * 24 * time.Hour)
+	for i, a := range archives {
+		tooMany := rotator.MaxBackups > 0 && i >= rotator.MaxBackups
+		tooOld := rotator.MaxAge > 0 && a.ts.Before(cutoff)
+		if tooMany || tooOld {
+			if err := os.Remove(a.path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// encryptFile encrypts path into path+encryptedSuffix and removes path.
+func (e *archiveEncryptor) encryptFile(
+	path string,
+) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp := path + encryptedSuffix + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	err = encryptStream(dst, src, e.key)
+	if err == nil {
+		err = dst.Sync()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path+encryptedSuffix)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to encrypt %s: %w", path, err)
+	}
+	return os.Remove(path)
+}
+
+// encryptStream writes src to dst in the encrypted archive format:
+//
+//	magic (8 bytes) | salt (32 bytes) | chunk...
+//	chunk = final flag (1 byte) | ciphertext length (4 bytes, big endian) | ciphertext
+//
+// Every file uses its own key, derived from the master key and a random salt
+// with HMAC-SHA256, and chunks are sealed with AES-256-GCM using their index as
+// nonce and the final flag as additional data, which detects reordering and
+// truncation.
+func encryptStream(
+	dst io.Writer,
+	src io.Reader,
+	masterKey []byte,
+) error {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	aead, err := archiveAEAD(masterKey, salt)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(dst)
+	if _, err := w.WriteString(encryptMagic); err != nil {
+		return err
+	}
+	if _, err := w.Write(salt); err != nil {
+		return err
+	}
+
+	buf := make([]byte, encryptChunkSize)
+	nonce := make([]byte, aead.NonceSize())
+	for index := uint64(0); ; index++ {
+		n, readErr := io.ReadFull(src, buf)
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			return readErr
+		}
+		final := readErr != nil
+
+		flag := []byte{0}
+		if final {
+			flag[0] = 1
+		}
+		binary.BigEndian.PutUint64(nonce[len(nonce)-8:], index)
+		sealed := aead.Seal(nil, nonce, buf[:n], flag)
+
+		var header [5]byte
+		header[0] = flag[0]
+		binary.BigEndian.PutUint32(header[1:], uint32(len(sealed)))
+		if _, err := w.Write(header[:]); err != nil {
+			return err
+		}
+		if _, err := w.Write(sealed); err != nil {
+			return err
+		}
+		if final {
+			return w.Flush()
+		}
+	}
+}
+
+// DecryptLogArchive decrypts a log archive produced by the at-rest encryption
+// of rotated files (see LogStdAndFileConfig.LogEncryptKeyFile).
+//
+// Parameters:
+//   - dst: receives the plaintext (gzip-compressed if the archive name ends in .gz.enc).
+//   - src: the encrypted archive.
+//   - key: the 32-byte master key the archive was encrypted with.
+//
+// Returns:
+//   - error if the archive is malformed, truncated, or the key is wrong.
+func DecryptLogArchive(
+	dst io.Writer,
+	src io.Reader,
+	key []byte,
+) error {
+	r := bufio.NewReader(src)
+
+	header := make([]byte, len(encryptMagic)+32)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return fmt.Errorf("invalid log archive: %w", err)
+	}
+	if string(header[:len(encryptMagic)]) != encryptMagic {
+		return errors.New("invalid log archive: unknown format")
+	}
+	aead, err := archiveAEAD(key, header[len(encryptMagic):])
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	for index := uint64(0); ; index++ {
+		var chunkHeader [5]byte
+		if _, err := io.ReadFull(r, chunkHeader[:]); err != nil {
+			return fmt.Errorf("invalid log archive: truncated: %w", err)
+		}
+		size := binary.BigEndian.Uint32(chunkHeader[1:])
+		if size > encryptChunkSize+uint32(aead.Overhead()) {
+			return errors.New("invalid log archive: chunk too large")
+		}
+		sealed := make([]byte, size)
+		if _, err := io.ReadFull(r, sealed); err != nil {
+			return fmt.Errorf("invalid log archive: truncated: %w", err)
+		}
+
+		binary.BigEndian.PutUint64(nonce[len(nonce)-8:], index)
+		plain, err := aead.Open(nil, nonce, sealed, chunkHeader[:1])
+		if err != nil {
+			return errors.New("invalid log archive: authentication failed (wrong key or corrupted data)")
+		}
+		if _, err := dst.Write(plain); err != nil {
+			return err
+		}
+		if chunkHeader[0] == 1 {
+			return nil
+		}
+	}
+}
+
+// archiveAEAD derives the per-file key from the master key and salt and returns
+// the AES-256-GCM cipher using it.
+func archiveAEAD(
+	masterKey []byte,
+	salt []byte,
+) (cipher.AEAD, error) {
+	mac := hmac.New(sha256.New, masterKey)
+	mac.Write(salt)
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
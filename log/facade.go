@@ -0,0 +1,76 @@
+package golog
+
+import (
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// defaultSugar backs the package-level Debugw, Infow, Warnw, Errorw and Fatalw
+// functions. It skips one extra frame so that entries report their caller.
+var defaultSugar atomic.Pointer[zap.SugaredLogger]
+
+func init() {
+	defaultSugar.Store(zap.NewNop().Sugar())
+}
+
+// ReplaceLogger installs logger as the logger used by the package-level
+// Debugw, Infow, Warnw, Errorw and Fatalw functions, which discard everything
+// until it is called. The reported caller of their entries is the code calling
+// them, not this package, so they can back a thin logging facade.
+//
+// Example:
+//
+//	logger, handle := golog.SetupStdAndFileLogger(cfg)
+//	golog.ReplaceLogger(logger)
+//	golog.Infow("relay started", "port", port)
+func ReplaceLogger(
+	logger *zap.Logger,
+) {
+	defaultSugar.Store(logger.WithOptions(zap.AddCallerSkip(1)).Sugar())
+}
+
+// Debugw logs a message at debug level with alternating keys and values
+// (e.g., "peer", addr, "attempt", 3) on the logger installed by ReplaceLogger.
+func Debugw(
+	msg string,
+	keysAndValues ...any,
+) {
+	defaultSugar.Load().Debugw(msg, keysAndValues...)
+}
+
+// Infow logs a message at info level with alternating keys and values on the
+// logger installed by ReplaceLogger.
+func Infow(
+	msg string,
+	keysAndValues ...any,
+) {
+	defaultSugar.Load().Infow(msg, keysAndValues...)
+}
+
+// Warnw logs a message at warn level with alternating keys and values on the
+// logger installed by ReplaceLogger.
+func Warnw(
+	msg string,
+	keysAndValues ...any,
+) {
+	defaultSugar.Load().Warnw(msg, keysAndValues...)
+}
+
+// Errorw logs a message at error level with alternating keys and values on the
+// logger installed by ReplaceLogger.
+func Errorw(
+	msg string,
+	keysAndValues ...any,
+) {
+	defaultSugar.Load().Errorw(msg, keysAndValues...)
+}
+
+// Fatalw logs a message at fatal level with alternating keys and values on the
+// logger installed by ReplaceLogger, then calls os.Exit(1).
+func Fatalw(
+	msg string,
+	keysAndValues ...any,
+) {
+	defaultSugar.Load().Fatalw(msg, keysAndValues...)
+}
@@ -0,0 +1,146 @@
+package golog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// FileErrorDrop drops entries the log file cannot be written to (default),
+	// so that a full disk never holds up the application.
+	FileErrorDrop = "drop"
+
+	// FileErrorBlock retries failed writes until they succeed, holding up the
+	// logging goroutine, for setups where losing entries is not acceptable.
+	FileErrorBlock = "block"
+
+	// FileErrorStdout writes entries the log file cannot be written to on
+	// standard output instead.
+	FileErrorStdout = "stdout"
+)
+
+const (
+	// blockRetryMin is the initial delay between retries of FileErrorBlock.
+	blockRetryMin = 100 * time.Millisecond
+
+	// blockRetryMax caps the delay between retries of FileErrorBlock.
+	blockRetryMax = 5 * time.Second
+)
+
+// failsafeWriter applies a write error policy to a log file writer. Failures
+// and recoveries are reported once on stderr rather than for every entry; not
+// through the standard logger, which RedirectStdLog may send back to the file.
+type failsafeWriter struct {
+	w       io.Writer
+	name    string     // file name, for reports
+	stats   *sinkStats // records every failure, see Handle.Health
+	policy  string     // FileErrorDrop, FileErrorBlock or FileErrorStdout
+	failing atomic.Bool
+	dropped atomic.Uint64 // entries not written to the file
+	done    chan struct{} // closed by stop, aborts blocked writes
+	once    sync.Once     // guards done
+}
+
+// parseFileErrorPolicy validates a write error policy.
+//
+// Parameters:
+//   - policy: FileErrorDrop, FileErrorBlock or FileErrorStdout; empty selects FileErrorDrop.
+//
+// Returns:
+//   - the policy.
+//   - error if the policy is unknown.
+func parseFileErrorPolicy(
+	policy string,
+) (string, error) {
+	switch policy {
+	case "":
+		return FileErrorDrop, nil
+	case FileErrorDrop, FileErrorBlock, FileErrorStdout:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid log file error policy %q (expected %q, %q or %q)",
+			policy, FileErrorDrop, FileErrorBlock, FileErrorStdout)
+	}
+}
+
+// newFailsafeWriter wraps w with a policy returned by parseFileErrorPolicy,
+// recording failures in stats.
+func newFailsafeWriter(
+	w io.Writer,
+	name string,
+	policy string,
+	stats *sinkStats,
+) *failsafeWriter {
+	return &failsafeWriter{w: w, name: name, stats: stats, policy: policy, done: make(chan struct{})}
+}
+
+// Write writes p to the file, applying the policy on failure. It only returns
+// an error when a blocked write is aborted by stop.
+func (f *failsafeWriter) Write(
+	p []byte,
+) (int, error) {
+	_, err := f.w.Write(p)
+	if err == nil {
+		f.recovered()
+		return len(p), nil
+	}
+	f.failed(err)
+
+	switch f.policy {
+	case FileErrorBlock:
+		delay := blockRetryMin
+		for {
+			select {
+			case <-f.done:
+				f.dropped.Add(1)
+				return 0, err
+			case <-time.After(delay):
+			}
+			if _, err = f.w.Write(p); err == nil {
+				f.recovered()
+				return len(p), nil
+			}
+			f.stats.fail(err.Error())
+			delay = min(delay*2, blockRetryMax)
+		}
+	case FileErrorStdout:
+		f.dropped.Add(1)
+		_, _ = os.Stdout.Write(p)
+	default:
+		f.dropped.Add(1)
+	}
+	return len(p), nil
+}
+
+// Dropped returns the number of entries that could not be written to the file.
+func (f *failsafeWriter) Dropped() uint64 {
+	return f.dropped.Load()
+}
+
+// stop aborts the writes blocked by FileErrorBlock.
+func (f *failsafeWriter) stop() {
+	f.once.Do(func() {
+		close(f.done)
+	})
+}
+
+// failed records a failure and reports the first one of a series.
+func (f *failsafeWriter) failed(
+	err error,
+) {
+	f.stats.fail(err.Error())
+	if f.failing.CompareAndSwap(false, true) {
+		fmt.Fprintf(os.Stderr, "Failed to write log file %s, applying policy %q: %v\n", f.name, f.policy, err)
+	}
+}
+
+// recovered reports the end of a series of failures.
+func (f *failsafeWriter) recovered() {
+	if f.failing.CompareAndSwap(true, false) {
+		fmt.Fprintf(os.Stderr, "Log file %s is writable again (%d entries lost so far)\n", f.name, f.dropped.Load())
+	}
+}
@@ -0,0 +1,62 @@
+package golog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// FieldFingerprint is the field name under which Fingerprint stores the error fingerprint.
+const FieldFingerprint = "error_fingerprint"
+
+// Fingerprint returns a field identifying a class of errors, so that identical
+// failures reported by many nodes can be grouped downstream (e.g., counted per
+// fingerprint in a dashboard) even though their details differ.
+//
+// The fingerprint is a short hash of the message template and the type of the
+// root cause of err (the innermost error of its Unwrap chain). The template
+// must be the constant message passed to the logger, not a formatted string,
+// otherwise every occurrence gets its own fingerprint.
+//
+// Parameters:
+//   - msg: the constant log message.
+//   - err: the error being logged; nil fingerprints the message alone.
+//
+// Returns:
+//   - zap.Field named FieldFingerprint holding 16 hexadecimal characters.
+//
+// Example:
+//
+//	logger.Error("failed to push metrics", zap.Error(err), golog.Fingerprint("failed to push metrics", err))
+func Fingerprint(
+	msg string,
+	err error,
+) zap.Field {
+	sum := sha256.Sum256([]byte(msg + "\x00" + rootErrorType(err)))
+	return zap.String(FieldFingerprint, hex.EncodeToString(sum[:8]))
+}
+
+// rootErrorType returns the type name of the innermost error wrapped by err.
+// For errors joining several others, the first one is followed.
+func rootErrorType(
+	err error,
+) string {
+	if err == nil {
+		return ""
+	}
+	for {
+		next := errors.Unwrap(err)
+		if next == nil {
+			if joined, ok := err.(interface{ Unwrap() []error }); ok && len(joined.Unwrap()) > 0 {
+				next = joined.Unwrap()[0]
+			}
+		}
+		if next == nil {
+			return fmt.Sprintf("%T", err)
+		}
+		err = next
+	}
+}
@@ -0,0 +1,86 @@
+package golog
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// FlushOnShutdown flushes the logger with a deadline, so that the last lines
+// before exit are not lost while a slow sink cannot hold up the shutdown.
+// It is meant to be called last on the shutdown path, before Handle.Close.
+//
+// Errors caused by syncing terminals and pipes (stdout, stderr), which do not
+// support it, are ignored.
+//
+// Parameters:
+//   - logger: the logger to flush.
+//   - timeout: maximum time to wait for the flush.
+//
+// Returns:
+//   - error if the flush failed or timed out; on timeout it reports how many
+//     entries were still buffered by the network sinks.
+//
+// Example:
+//
+//	defer handle.Close()
+//	defer func() {
+//	    if err := golog.FlushOnShutdown(logger, 5*time.Second); err != nil {
+//	        fmt.Fprintln(os.Stderr, err)
+//	    }
+//	}()
+func FlushOnShutdown(
+	logger *zap.Logger,
+	timeout time.Duration,
+) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- logger.Sync()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return ignoreSyncUnsupported(err)
+	case <-timer.C:
+		return fmt.Errorf("log flush timed out after %s with %d entries still buffered", timeout, bufferedEntries())
+	}
+}
+
+// bufferedEntries returns the number of entries queued by all running batchers.
+func bufferedEntries() int {
+	total := 0
+	liveBatchers.Range(func(key, _ any) bool {
+		total += key.(interface{ pending() int }).pending()
+		return true
+	})
+	return total
+}
+
+// ignoreSyncUnsupported drops the EINVAL and ENOTTY errors returned when syncing
+// a terminal or a pipe, keeping every other error.
+func ignoreSyncUnsupported(
+	err error,
+) error {
+	if err == nil {
+		return nil
+	}
+
+	errs := []error{err}
+	if multi, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = multi.Unwrap()
+	}
+
+	var kept []error
+	for _, e := range errs {
+		if isSyncUnsupported(e) {
+			continue
+		}
+		kept = append(kept, e)
+	}
+	return errors.Join(kept...)
+}
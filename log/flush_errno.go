@@ -0,0 +1,16 @@
+//go:build !plan9
+
+package golog
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isSyncUnsupported reports whether err is the EINVAL or ENOTTY error returned
+// when syncing a terminal or a pipe.
+func isSyncUnsupported(
+	err error,
+) bool {
+	return errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTTY)
+}
@@ -0,0 +1,94 @@
+// Package gologtest provides a logger for tests recording its entries so that
+// they can be asserted on, kept apart from golog, like zap's zaptest, so that
+// binaries importing golog do not link the testing package.
+package gologtest
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// Logger is a logger for tests that records every entry in memory so that
+// it can be asserted on. Entries are also written to the test output, where they
+// are shown for failing tests or with `go test -v`.
+//
+// Example:
+//
+//	logger := gologtest.NewLogger(t)
+//	doWork(logger.Logger)
+//	logger.AssertMessage("work done")
+//	logger.AssertCount(zapcore.ErrorLevel, 0)
+type Logger struct {
+	*zap.Logger
+	t    testing.TB
+	logs *observer.ObservedLogs
+}
+
+// NewLogger creates a Logger recording entries at debug level and above.
+//
+// Parameters:
+//   - t: the running test or benchmark.
+//
+// Returns:
+//   - *Logger whose Logger field can be passed to the code under test.
+func NewLogger(
+	t testing.TB,
+) *Logger {
+	core, logs := observer.New(zapcore.DebugLevel)
+	testCore := zaptest.NewLogger(t, zaptest.Level(zapcore.DebugLevel)).Core()
+
+	return &Logger{
+		Logger: zap.New(zapcore.NewTee(core, testCore)),
+		t:      t,
+		logs:   logs,
+	}
+}
+
+// Entries returns every entry recorded so far, in order.
+func (l *Logger) Entries() []observer.LoggedEntry {
+	return l.logs.All()
+}
+
+// Reset discards the recorded entries.
+func (l *Logger) Reset() {
+	l.logs.TakeAll()
+}
+
+// ContainsMessage reports whether an entry with exactly the given message was recorded.
+func (l *Logger) ContainsMessage(
+	msg string,
+) bool {
+	return l.logs.FilterMessage(msg).Len() > 0
+}
+
+// CountByLevel returns the number of recorded entries at the given level.
+func (l *Logger) CountByLevel(
+	level zapcore.Level,
+) int {
+	return l.logs.FilterLevelExact(level).Len()
+}
+
+// AssertMessage fails the test if no entry with exactly the given message was recorded.
+func (l *Logger) AssertMessage(
+	msg string,
+) {
+	l.t.Helper()
+	if !l.ContainsMessage(msg) {
+		l.t.Errorf("expected a log entry with message %q, got %d entries", msg, l.logs.Len())
+	}
+}
+
+// AssertCount fails the test if the number of entries at the given level differs from want.
+func (l *Logger) AssertCount(
+	level zapcore.Level,
+	want int,
+) {
+	l.t.Helper()
+	if got := l.CountByLevel(level); got != want {
+		l.t.Errorf("expected %d %s log entries, got %d", want, level, got)
+	}
+}
@@ -0,0 +1,48 @@
+package golog
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapgrpc"
+	"google.golang.org/grpc/grpclog"
+)
+
+// SetGrpcLogger routes gRPC's internal logging (connection churn, resolver and
+// balancer errors, ...) to logger, so that it appears in the structured log
+// instead of being printed raw to stderr or discarded.
+//
+// gRPC severities map to zap levels (info, warn, error, fatal), filtered by
+// the level of logger. Verbose messages, which gRPC guards with V(n), are
+// logged only when n is at most verbosity: 0 keeps regular messages only, and
+// 2 (gRPC's highest) logs everything. Passing golog.Named("grpc") as logger
+// lets the gRPC level be adjusted on its own.
+//
+// It must be called before any gRPC function, as grpclog.SetLoggerV2 is not
+// safe for concurrent use.
+//
+// Parameters:
+//   - logger: the logger receiving gRPC messages.
+//   - verbosity: maximum gRPC verbosity level to log.
+//
+// Example:
+//
+//	golog.SetGrpcLogger(golog.Named("grpc"), 0)
+func SetGrpcLogger(
+	logger *zap.Logger,
+	verbosity int,
+) {
+	grpclog.SetLoggerV2(&grpcLogger{Logger: zapgrpc.NewLogger(logger), verbosity: verbosity})
+}
+
+// grpcLogger is a zapgrpc.Logger whose V method follows gRPC's verbosity
+// semantics instead of mapping verbosity levels to severities.
+type grpcLogger struct {
+	*zapgrpc.Logger
+	verbosity int
+}
+
+// V reports whether messages of the given verbosity level are logged.
+func (l *grpcLogger) V(
+	level int,
+) bool {
+	return level <= l.verbosity
+}
@@ -0,0 +1,312 @@
+package golog
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Handle exposes runtime controls for a logger built by the golog setup functions.
+//
+// It is returned alongside the *zap.Logger so that a running process can adjust
+// logging behavior (e.g., switch from info to debug) without being restarted.
+type Handle struct {
+	level       zap.AtomicLevel                 // shared level enabler used by cores without an override
+	sinkLevels  map[string]zap.AtomicLevel      // per-sink level overrides (e.g., SinkFile)
+	rotators    []*rotationTracker              // file writers, empty for stdout-only loggers
+	hooksMu     sync.RWMutex                    // guards rotateHooks
+	rotateHooks []func(oldName, newName string) // run after every rotation (e.g., archive encryption)
+	dropped     map[string]func() uint64        // per-sink counters of entries dropped under backpressure
+	stats       map[string]*sinkStats           // per-sink write counters, see Health
+	sendStats   map[string][]*sinkStats         // per-sink send failures of network sinks
+	closers     []func()                        // release background resources (signal handlers, exporters)
+	closeOnce   sync.Once                       // guards closers
+	registry    *Registry                       // named loggers with per-subsystem levels
+}
+
+const (
+	// SinkStdout identifies the standard output core of a logger.
+	SinkStdout = "stdout"
+
+	// SinkFile identifies the rotating file core of a logger.
+	SinkFile = "file"
+
+	// SinkOTLP identifies the OpenTelemetry exporter core of a logger.
+	SinkOTLP = "otlp"
+
+	// SinkLoki identifies the Grafana Loki push core of a logger.
+	SinkLoki = "loki"
+
+	// SinkKafka identifies a Kafka writer.
+	SinkKafka = "kafka"
+
+	// SinkElastic identifies the Elasticsearch bulk core of a logger.
+	SinkElastic = "elastic"
+
+	// SinkSyslog identifies the syslog core of a logger.
+	SinkSyslog = "syslog"
+
+	// SinkJournald identifies the journald core of a logger.
+	SinkJournald = "journald"
+)
+
+// newHandle creates a Handle bound to the given atomic level.
+func newHandle(
+	level zap.AtomicLevel,
+) *Handle {
+	return &Handle{level: level}
+}
+
+// Level returns the zap.AtomicLevel backing the logger.
+//
+// Returns:
+//   - the atomic level; changes to it take effect immediately on all cores.
+func (h *Handle) Level() zap.AtomicLevel {
+	return h.level
+}
+
+// SetLevel changes the shared log level. Sinks configured with their own level
+// (see SetSinkLevel) are not affected.
+//
+// Parameters:
+//   - level: textual level such as "debug", "info", "warn" or "error".
+//
+// Returns:
+//   - error if the level cannot be parsed; the current level is left unchanged.
+func (h *Handle) SetLevel(
+	level string,
+) error {
+	if err := h.level.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid log level: %w", err)
+	}
+	return nil
+}
+
+// SinkLevel returns the level override of the given sink.
+//
+// Parameters:
+//   - sink: sink name, such as SinkStdout or SinkFile.
+//
+// Returns:
+//   - the sink's atomic level.
+//   - false if the sink follows the shared level.
+func (h *Handle) SinkLevel(
+	sink string,
+) (zap.AtomicLevel, bool) {
+	level, ok := h.sinkLevels[sink]
+	return level, ok
+}
+
+// SetSinkLevel changes the level of a sink that was configured with its own level.
+//
+// Parameters:
+//   - sink: sink name, such as SinkStdout or SinkFile.
+//   - level: textual level such as "debug", "info", "warn" or "error".
+//
+// Returns:
+//   - error if the sink has no level override or the level cannot be parsed.
+func (h *Handle) SetSinkLevel(
+	sink string,
+	level string,
+) error {
+	sinkLevel, ok := h.sinkLevels[sink]
+	if !ok {
+		return fmt.Errorf("sink %q has no level override", sink)
+	}
+	if err := sinkLevel.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid log level: %w", err)
+	}
+	return nil
+}
+
+// overrideSinkLevel gives a sink its own level when logLevel is not empty.
+//
+// Parameters:
+//   - sink: sink name, such as SinkStdout or SinkFile.
+//   - logLevel: textual level; empty means the sink follows the shared level.
+//
+// Returns:
+//   - error if the level cannot be parsed.
+func (h *Handle) overrideSinkLevel(
+	sink string,
+	logLevel string,
+) error {
+	if logLevel == "" {
+		return nil
+	}
+	level, err := parseLevel(logLevel)
+	if err != nil {
+		return fmt.Errorf("%s: %w", sink, err)
+	}
+	if h.sinkLevels == nil {
+		h.sinkLevels = make(map[string]zap.AtomicLevel)
+	}
+	h.sinkLevels[sink] = level
+	return nil
+}
+
+// levelFor returns the level a sink applies: its override when one exists,
+// the shared level otherwise.
+func (h *Handle) levelFor(
+	sink string,
+) zap.AtomicLevel {
+	if level, ok := h.sinkLevels[sink]; ok {
+		return level
+	}
+	return h.level
+}
+
+// sinkEnabler returns the enabler the core of a sink is built with: its override
+// when one exists, every level otherwise. Sinks following the shared level are
+// filtered by the statsCore wrapping them (see instrument), which applies the
+// subsystem levels as well.
+func (h *Handle) sinkEnabler(
+	sink string,
+) zapcore.LevelEnabler {
+	if level, ok := h.sinkLevels[sink]; ok {
+		return level
+	}
+	return zapcore.DebugLevel
+}
+
+// Registry returns the registry of named loggers derived from the logger, with
+// the per-subsystem levels configured through --log-level-overrides.
+//
+// Returns:
+//   - *Registry to hand out named loggers, or to install with ReplaceRegistry.
+func (h *Handle) Registry() *Registry {
+	return h.registry
+}
+
+// ServeLevelHTTP is an http.HandlerFunc that reports the current level on GET
+// and changes it on PUT, using zap's JSON ({"level":"debug"}) or form
+// (level=debug) payloads.
+//
+// Example:
+//
+//	logger, handle := golog.SetupStdLogger(cfg)
+//	http.HandleFunc("/log/level", handle.ServeLevelHTTP)
+func (h *Handle) ServeLevelHTTP(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	h.level.ServeHTTP(w, r)
+}
+
+// Rotate closes the current log files (the combined file and, if configured,
+// the error file), renames them with a timestamp suffix and opens fresh files
+// at the configured paths.
+//
+// Returns:
+//   - error if a rotation fails. Loggers without a file sink return nil.
+func (h *Handle) Rotate() error {
+	var errs []error
+	for _, rotator := range h.rotators {
+		if err := rotator.rotate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// OnRotate registers a function called after each rotation of a log file,
+// whether caused by its size, by Rotate, by SIGHUP or by the rotation interval,
+// e.g. to upload the completed segment or to point a current.log symlink at
+// the new file. It has no effect on loggers without a file sink.
+//
+// The function runs on the goroutine that triggered the rotation, often the one
+// writing a log entry, so it should return quickly and hand slow work (such as
+// uploads) to another goroutine. With compression enabled, the rotated file is
+// replaced by its .gz version shortly after; with encryption, by its .enc
+// archive.
+//
+// Parameters:
+//   - fn: receives the path of the rotated file (e.g., app-2024-05-01T00-00-00.000.log)
+//     and the path of the file now being written (e.g., app.log).
+//
+// Example:
+//
+//	handle.OnRotate(func(oldName, newName string) {
+//		go uploadSegment(oldName)
+//	})
+func (h *Handle) OnRotate(
+	fn func(oldName, newName string),
+) {
+	h.hooksMu.Lock()
+	defer h.hooksMu.Unlock()
+	h.rotateHooks = append(h.rotateHooks, fn)
+}
+
+// rotated runs the rotation hooks.
+func (h *Handle) rotated(
+	oldName string,
+	newName string,
+) {
+	h.hooksMu.RLock()
+	hooks := h.rotateHooks
+	h.hooksMu.RUnlock()
+
+	for _, hook := range hooks {
+		hook(oldName, newName)
+	}
+}
+
+// rotateAndReport rotates the log files and reports failures on the standard
+// logger, for rotations triggered in the background (signals, schedules).
+func (h *Handle) rotateAndReport() {
+	if err := h.Rotate(); err != nil {
+		log.Print(err)
+	}
+}
+
+// Close releases resources held by the handle, such as the SIGHUP handler or
+// background exporters. Exporters flush their pending entries before stopping.
+// Call logger.Sync() first to flush the remaining sinks.
+func (h *Handle) Close() {
+	h.closeOnce.Do(func() {
+		for _, closer := range h.closers {
+			closer()
+		}
+	})
+}
+
+// Dropped returns the number of entries a sink has dropped because its send
+// queue was full (e.g., the backend was slow or unreachable) or, for SinkFile,
+// because the log files could not be written to (see WithFileErrorPolicy).
+//
+// Parameters:
+//   - sink: sink name, such as SinkOTLP, SinkLoki or SinkFile.
+//
+// Returns:
+//   - the drop count; 0 for sinks that never drop entries or are not configured.
+func (h *Handle) Dropped(
+	sink string,
+) uint64 {
+	if counter, ok := h.dropped[sink]; ok {
+		return counter()
+	}
+	return 0
+}
+
+// trackDropped registers the drop counter of a sink.
+func (h *Handle) trackDropped(
+	sink string,
+	counter func() uint64,
+) {
+	if h.dropped == nil {
+		h.dropped = make(map[string]func() uint64)
+	}
+	h.dropped[sink] = counter
+}
+
+// onClose registers a function to be called by Close.
+func (h *Handle) onClose(
+	closer func(),
+) {
+	h.closers = append(h.closers, closer)
+}
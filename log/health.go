@@ -0,0 +1,239 @@
+package golog
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// SinkHealth reports the activity of a sink since its logger was built, so that
+// an application can expose the health of its logging pipeline (e.g., in its
+// own metrics endpoint).
+type SinkHealth struct {
+	Written       uint64    // entries written, or queued for network sinks
+	Errors        uint64    // failed writes, and failed sends for network sinks
+	Dropped       uint64    // entries lost under backpressure or file errors, see Handle.Dropped
+	LastError     string    // message of the most recent failure; empty if none
+	LastErrorTime time.Time // time of the most recent failure; zero if none
+}
+
+// Health returns the activity counters of every sink of the logger, keyed by
+// sink name (e.g., SinkStdout, SinkFile, SinkLoki).
+//
+// Returns:
+//   - a snapshot of the counters; later activity does not change it.
+//
+// Example:
+//
+//	for sink, health := range handle.Health() {
+//		loggingErrors.WithLabelValues(sink).Set(float64(health.Errors))
+//	}
+func (h *Handle) Health() map[string]SinkHealth {
+	sinks := make(map[string]struct{})
+	for sink := range h.stats {
+		sinks[sink] = struct{}{}
+	}
+	for sink := range h.sendStats {
+		sinks[sink] = struct{}{}
+	}
+	for sink := range h.dropped {
+		sinks[sink] = struct{}{}
+	}
+
+	health := make(map[string]SinkHealth, len(sinks))
+	for sink := range sinks {
+		var s SinkHealth
+		s.Dropped = h.Dropped(sink)
+
+		all := h.sendStats[sink]
+		if stats, ok := h.stats[sink]; ok {
+			s.Written = stats.written.Load()
+			all = append([]*sinkStats{stats}, all...)
+		}
+		for _, stats := range all {
+			errors, lastError, lastTime := stats.failures()
+			s.Errors += errors
+			if lastTime.After(s.LastErrorTime) {
+				s.LastError, s.LastErrorTime = lastError, lastTime
+			}
+		}
+		health[sink] = s
+	}
+	return health
+}
+
+// instrument wraps the core of a sink so that its writes and write errors are
+// reported by Health, and so that it applies its level (see levelFor) and those
+// of the named loggers (see Registry).
+func (h *Handle) instrument(
+	sink string,
+	core zapcore.Core,
+) zapcore.Core {
+	_, own := h.sinkLevels[sink]
+	return &statsCore{Core: core, stats: h.sinkStats(sink), level: h.levelFor(sink), shared: !own}
+}
+
+// sinkStats returns the write counters of a sink, creating them if needed.
+func (h *Handle) sinkStats(
+	sink string,
+) *sinkStats {
+	if h.stats == nil {
+		h.stats = make(map[string]*sinkStats)
+	}
+	stats, ok := h.stats[sink]
+	if !ok {
+		stats = &sinkStats{}
+		h.stats[sink] = stats
+	}
+	return stats
+}
+
+// trackErrors registers the send failures of a network sink, recorded by its
+// batcher.
+func (h *Handle) trackErrors(
+	sink string,
+	stats *sinkStats,
+) {
+	if h.sendStats == nil {
+		h.sendStats = make(map[string][]*sinkStats)
+	}
+	h.sendStats[sink] = append(h.sendStats[sink], stats)
+}
+
+// sinkStats counts the writes and failures of a sink.
+type sinkStats struct {
+	written   atomic.Uint64
+	errors    atomic.Uint64
+	mu        sync.Mutex // guards lastError and lastTime
+	lastError string
+	lastTime  time.Time
+}
+
+// fail records a failure.
+func (s *sinkStats) fail(
+	message string,
+) {
+	s.errors.Add(1)
+	s.mu.Lock()
+	s.lastError, s.lastTime = message, time.Now()
+	s.mu.Unlock()
+}
+
+// failures returns the failure count and the most recent failure.
+func (s *sinkStats) failures() (uint64, string, time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.errors.Load(), s.lastError, s.lastTime
+}
+
+// statsCore counts the entries written by the wrapped core and its write errors.
+// It checks the wrapped core again when writing, so that cores combining several
+// level-filtered outputs (e.g., the stdout/stderr split) keep their routing.
+//
+// It also applies the level of the sink: the wrapped core is built with the
+// sink override, if any, or enables every level (see Handle.sinkEnabler). In the
+// cores of a named logger, the subsystem level replaces the shared level, and
+// the sink override still applies on top of it.
+type statsCore struct {
+	zapcore.Core
+	stats     *sinkStats
+	level     zapcore.LevelEnabler // sink override, or the shared level
+	shared    bool                 // level is the shared level
+	subsystem *subsystemLevel      // level of the named logger the core belongs to, if any
+}
+
+// Enabled reports whether the sink accepts the level.
+func (c *statsCore) Enabled(
+	lvl zapcore.Level,
+) bool {
+	if c.subsystem == nil || !c.subsystem.set.Load() {
+		return c.level.Enabled(lvl)
+	}
+	return c.subsystem.level.Enabled(lvl) && (c.shared || c.level.Enabled(lvl))
+}
+
+// With returns a copy of the core with the given fields added to every entry.
+// A subsystem field (see subsystemField) binds the copy to the subsystem level.
+func (c *statsCore) With(
+	fields []zapcore.Field,
+) zapcore.Core {
+	clone := *c
+	clone.Core = c.Core.With(fields)
+	for _, f := range fields {
+		if level, ok := f.Interface.(*subsystemLevel); ok && f.Type == zapcore.SkipType {
+			clone.subsystem = level
+		}
+	}
+	return &clone
+}
+
+// Check adds the core to the checked entry if the sink accepts the level.
+func (c *statsCore) Check(
+	ent zapcore.Entry,
+	ce *zapcore.CheckedEntry,
+) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes the entry to the outputs of the wrapped core accepting it,
+// counting it as written or failed. Failures are also reported on stderr, as
+// zap does by default.
+func (c *statsCore) Write(
+	ent zapcore.Entry,
+	fields []zapcore.Field,
+) error {
+	inner := c.Core.Check(ent, nil)
+	if inner == nil {
+		return nil
+	}
+
+	recorder := writeErrorRecorderPool.Get().(*writeErrorRecorder)
+	recorder.failed = false
+	inner.ErrorOutput = recorder
+	inner.Write(fields...)
+
+	if recorder.failed {
+		c.stats.fail(recorder.message)
+	} else {
+		c.stats.written.Add(1)
+	}
+	recorder.message = ""
+	writeErrorRecorderPool.Put(recorder)
+	return nil
+}
+
+// writeErrorRecorderPool recycles the error outputs of statsCore writes.
+var writeErrorRecorderPool = sync.Pool{
+	New: func() any { return &writeErrorRecorder{} },
+}
+
+// writeErrorRecorder is the zapcore.WriteSyncer receiving the write errors of
+// a checked entry ("<time> write error: <error>").
+type writeErrorRecorder struct {
+	failed  bool
+	message string
+}
+
+// Write records the error and forwards it to stderr.
+func (r *writeErrorRecorder) Write(
+	p []byte,
+) (int, error) {
+	line := strings.TrimSuffix(string(p), "\n")
+	if _, message, ok := strings.Cut(line, " write error: "); ok {
+		line = message
+	}
+	r.failed, r.message = true, line
+	return os.Stderr.Write(p)
+}
+
+// Sync is a no-op.
+func (r *writeErrorRecorder) Sync() error {
+	return nil
+}
@@ -0,0 +1,200 @@
+//go:build linux
+
+package golog
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// journalSocket is the well-known datagram socket of systemd-journald.
+const journalSocket = "/run/systemd/journal/socket"
+
+// journalBufferPool recycles the buffers journal records are serialized into.
+var journalBufferPool = buffer.NewPool()
+
+// newJournaldCore connects to the journald native socket and returns a core that
+// sends every entry as a structured journal record.
+//
+// Parameters:
+//   - level: level enabler for the core.
+//
+// Returns:
+//   - zapcore.Core writing to journald.
+//   - error if the journal socket is not available.
+func newJournaldCore(
+	level zapcore.LevelEnabler,
+) (zapcore.Core, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: journalSocket, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to journald: %w", err)
+	}
+
+	return &journaldCore{
+		LevelEnabler: level,
+		conn:         conn,
+		identifier:   filepath.Base(os.Args[0]),
+	}, nil
+}
+
+// journaldCore is a zapcore.Core that writes entries to journald using the native
+// protocol. zap fields become journal fields (upper-cased, e.g. "pod_name" -> POD_NAME).
+type journaldCore struct {
+	zapcore.LevelEnabler
+	conn       *net.UnixConn
+	identifier string
+	context    []zapcore.Field // fields added through With
+}
+
+// With returns a copy of the core with the given fields added to every entry.
+func (c *journaldCore) With(
+	fields []zapcore.Field,
+) zapcore.Core {
+	clone := *c
+	clone.context = append(append([]zapcore.Field{}, c.context...), fields...)
+	return &clone
+}
+
+// Check adds the core to the checked entry if the entry level is enabled.
+func (c *journaldCore) Check(
+	ent zapcore.Entry,
+	ce *zapcore.CheckedEntry,
+) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write serializes the entry into a journal record and sends it to journald.
+func (c *journaldCore) Write(
+	ent zapcore.Entry,
+	fields []zapcore.Field,
+) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.context {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	buf := journalBufferPool.Get()
+	defer buf.Free()
+
+	writeJournalField(buf, "MESSAGE", ent.Message)
+	writeJournalField(buf, "PRIORITY", journalPriority(ent.Level))
+	writeJournalField(buf, "SYSLOG_IDENTIFIER", c.identifier)
+	if ent.LoggerName != "" {
+		writeJournalField(buf, "LOGGER", ent.LoggerName)
+	}
+	if ent.Caller.Defined {
+		writeJournalField(buf, "CODE_FILE", ent.Caller.File)
+		writeJournalField(buf, "CODE_LINE", strconv.Itoa(ent.Caller.Line))
+		writeJournalField(buf, "CODE_FUNC", ent.Caller.Function)
+	}
+	if ent.Stack != "" {
+		writeJournalField(buf, "STACKTRACE", ent.Stack)
+	}
+	for k, v := range enc.Fields {
+		writeJournalField(buf, journalFieldName(k), journalFieldValue(v))
+	}
+
+	_, err := c.conn.Write(buf.Bytes())
+	return err
+}
+
+// Sync is a no-op: records are sent as individual datagrams.
+func (c *journaldCore) Sync() error {
+	return nil
+}
+
+// journalPriority maps a zap level to the syslog-style numeric priority used by journald.
+func journalPriority(
+	level zapcore.Level,
+) string {
+	switch level {
+	case zapcore.DebugLevel:
+		return "7"
+	case zapcore.InfoLevel:
+		return "6"
+	case zapcore.WarnLevel:
+		return "4"
+	case zapcore.ErrorLevel:
+		return "3"
+	case zapcore.DPanicLevel, zapcore.PanicLevel:
+		return "2"
+	case zapcore.FatalLevel:
+		return "0"
+	default:
+		return "6"
+	}
+}
+
+// writeJournalField appends a field in journald native format. Values containing a
+// newline use the binary form (name, newline, little-endian length, value).
+func writeJournalField(
+	buf *buffer.Buffer,
+	name string,
+	value string,
+) {
+	buf.AppendString(name)
+	if strings.IndexByte(value, '\n') >= 0 {
+		var size [8]byte
+		binary.LittleEndian.PutUint64(size[:], uint64(len(value)))
+		buf.AppendByte('\n')
+		_, _ = buf.Write(size[:])
+	} else {
+		buf.AppendByte('=')
+	}
+	buf.AppendString(value)
+	buf.AppendByte('\n')
+}
+
+// journalFieldName converts a zap key into a valid journal field name: upper-case
+// letters, digits and underscores, not starting with an underscore or digit.
+func journalFieldName(
+	key string,
+) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+	if name == "" || name[0] == '_' || (name[0] >= '0' && name[0] <= '9') {
+		name = "F" + name
+	}
+	return name
+}
+
+// journalFieldValue renders a field value as a string, using JSON for composite values.
+func journalFieldValue(
+	v any,
+) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case fmt.Stringer:
+		return val.String()
+	case error:
+		return val.Error()
+	}
+	if b, err := json.Marshal(v); err == nil {
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,49 @@
+//go:build linux
+
+package golog
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func BenchmarkJournaldCoreWrite(b *testing.B) {
+	addr := &net.UnixAddr{Name: filepath.Join(b.TempDir(), "journal.sock"), Net: "unixgram"}
+	journal, err := net.ListenUnixgram("unixgram", addr)
+	if err != nil {
+		b.Skipf("unix datagram sockets unavailable: %v", err)
+	}
+	defer journal.Close()
+	go func() {
+		buf := make([]byte, 64<<10)
+		for {
+			if _, err := journal.Read(buf); err != nil {
+				return
+			}
+		}
+	}()
+
+	conn, err := net.DialUnix("unixgram", nil, addr)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer conn.Close()
+
+	core := (&journaldCore{LevelEnabler: zapcore.DebugLevel, conn: conn, identifier: "bench"}).
+		With([]zapcore.Field{zap.String("node", "worker-1")})
+	ent := zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Now(), LoggerName: "grpc", Message: "stream opened"}
+	fields := []zapcore.Field{zap.String("pod_name", "relay-0"), zap.Int("attempt", 3)}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := core.Write(ent, fields); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
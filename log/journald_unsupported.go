@@ -0,0 +1,16 @@
+//go:build !linux
+
+package golog
+
+import (
+	"errors"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// newJournaldCore always fails: journald is only available on Linux.
+func newJournaldCore(
+	level zapcore.LevelEnabler,
+) (zapcore.Core, error) {
+	return nil, errors.New("journald is not supported on this platform")
+}
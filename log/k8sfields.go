@@ -0,0 +1,81 @@
+package golog
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// FieldPod is the field name under which PodField stores a pod.
+	FieldPod = "k8s_pod"
+
+	// FieldContainer is the field name under which ContainerField stores a container.
+	FieldContainer = "k8s_container"
+
+	// FieldNode is the field name under which NodeField stores a node.
+	FieldNode = "k8s_node"
+)
+
+// PodField returns a field describing the pod an entry is about, nested as
+// {"namespace", "name", "uid"} under FieldPod so that agent and relay entries
+// can be queried with the same keys (e.g., k8s_pod.uid). Empty values are
+// omitted.
+//
+// These fields describe the objects being observed; the node, pod and namespace
+// the logging process runs in are attached separately by WithKubernetesMetadata.
+//
+// Parameters:
+//   - namespace: the pod namespace.
+//   - name: the pod name.
+//   - uid: the pod UID.
+//
+// Example:
+//
+//	logger.Info("pod sandbox created", golog.PodField(pod.Namespace, pod.Name, string(pod.UID)))
+func PodField(
+	namespace string,
+	name string,
+	uid string,
+) zap.Field {
+	return zap.Object(FieldPod, stringsObject{"namespace", namespace, "name", name, "uid", uid})
+}
+
+// ContainerField returns a field describing a container, nested as {"id",
+// "image"} under FieldContainer. Empty values are omitted.
+//
+// Parameters:
+//   - id: the container ID reported by the CRI runtime.
+//   - image: the container image reference.
+func ContainerField(
+	id string,
+	image string,
+) zap.Field {
+	return zap.Object(FieldContainer, stringsObject{"id", id, "image", image})
+}
+
+// NodeField returns a field describing a node, nested as {"name"} under
+// FieldNode. An empty name yields an empty object.
+//
+// Parameters:
+//   - name: the node name.
+func NodeField(
+	name string,
+) zap.Field {
+	return zap.Object(FieldNode, stringsObject{"name", name})
+}
+
+// stringsObject is a zapcore.ObjectMarshaler encoding alternating keys and
+// values, skipping empty values.
+type stringsObject []string
+
+// MarshalLogObject adds the non-empty values to enc.
+func (o stringsObject) MarshalLogObject(
+	enc zapcore.ObjectEncoder,
+) error {
+	for i := 0; i+1 < len(o); i += 2 {
+		if o[i+1] != "" {
+			enc.AddString(o[i], o[i+1])
+		}
+	}
+	return nil
+}
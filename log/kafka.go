@@ -0,0 +1,124 @@
+package golog
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// kafkaProduceTimeout bounds a single batch publication.
+const kafkaProduceTimeout = 10 * time.Second
+
+// KafkaMessage is a single record to be published to Kafka.
+type KafkaMessage struct {
+	Key   []byte // partitioning key; nil lets the producer choose the partition
+	Value []byte // JSON-encoded log entry
+}
+
+// KafkaProducer publishes a batch of messages to a Kafka topic.
+//
+// golog does not bundle a Kafka client: implementations wrap the client already
+// used by the application (e.g., segmentio/kafka-go, IBM/sarama or franz-go),
+// so that connection, TLS and SASL settings stay in one place.
+type KafkaProducer interface {
+	// Produce publishes msgs to topic, returning once they are acknowledged.
+	Produce(ctx context.Context, topic string, msgs []KafkaMessage) error
+}
+
+// KafkaWriter is a zapcore.WriteSyncer that publishes every written log line as
+// a Kafka message. Lines are batched asynchronously: Write never blocks on the
+// network, and lines are dropped (and counted) when the send queue is full.
+//
+// Example:
+//
+//	w := golog.NewKafkaWriter(producer, "kubensage-logs", 500, time.Second)
+//	defer w.Close()
+//	logger = logger.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+//	    return zapcore.NewTee(c, golog.NewKafkaCore(w, zapcore.InfoLevel))
+//	}))
+type KafkaWriter struct {
+	*batcher[KafkaMessage]
+	producer KafkaProducer
+	topic    string
+}
+
+// NewKafkaWriter creates a KafkaWriter and starts its background goroutine.
+//
+// Parameters:
+//   - producer: the client used to publish batches.
+//   - topic: destination Kafka topic.
+//   - batchSize: maximum number of messages per batch.
+//   - interval: maximum time a message waits before being published.
+//
+// Returns:
+//   - *KafkaWriter ready to accept log lines.
+func NewKafkaWriter(
+	producer KafkaProducer,
+	topic string,
+	batchSize int,
+	interval time.Duration,
+) *KafkaWriter {
+	w := &KafkaWriter{producer: producer, topic: topic}
+	w.batcher = newBatcher(SinkKafka, batchSize, interval, w.produce)
+	return w
+}
+
+// Write queues one log line. The slice is copied, since zap reuses its buffers.
+//
+// Returns:
+//   - len(p) and nil on success.
+//   - 0 and an error if the queue is full and the line was dropped.
+func (w *KafkaWriter) Write(
+	p []byte,
+) (int, error) {
+	value := make([]byte, len(p))
+	copy(value, p)
+	if err := w.enqueue(KafkaMessage{Value: value}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sync blocks until every queued line has been published (or has failed).
+func (w *KafkaWriter) Sync() error {
+	w.flush()
+	return nil
+}
+
+// Close publishes the pending lines and stops the background goroutine.
+func (w *KafkaWriter) Close() error {
+	w.stop()
+	return nil
+}
+
+// produce publishes one batch, retrying with backoff on failure.
+func (w *KafkaWriter) produce(
+	batch []KafkaMessage,
+) error {
+	msgs := append([]KafkaMessage(nil), batch...)
+	return retryWithBackoff(func() (bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), kafkaProduceTimeout)
+		defer cancel()
+		return true, w.producer.Produce(ctx, w.topic, msgs)
+	})
+}
+
+// NewKafkaCore returns a core writing JSON-encoded entries to w.
+//
+// Parameters:
+//   - w: the KafkaWriter publishing the lines.
+//   - level: level enabler for the core.
+//
+// Returns:
+//   - zapcore.Core to tee with the logger's existing cores.
+func NewKafkaCore(
+	w *KafkaWriter,
+	level zapcore.LevelEnabler,
+) zapcore.Core {
+	encoder, _ := newEncoder(EncoderJSON, false, nil)
+	return zapcore.NewCore(encoder, w, level)
+}
+
+// compile-time check that KafkaWriter can back a zapcore.Core.
+var _ zapcore.WriteSyncer = (*KafkaWriter)(nil)
@@ -4,19 +4,24 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"reflect"
 	"runtime"
 	"time"
 
 	"github.com/kubensage/common/cli"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 // LogStartupInfo logs standard metadata at startup, including Go version,
-// executable path, and current time. Optionally, any configuration structs passed
-// are logged under their type name after sanitization.
+// executable path, current time and build information (module version, VCS
+// revision and dirty flag, build tags). Optionally, any configuration structs
+// passed are logged under their type name after sanitization.
+//
+// For configurations returned by the gocli registrars, the flags whose value
+// does not come from its default are logged under the type name followed by
+// "Sources", with where the value came from (e.g., "log-level": "env"; see
+// gocli.Source). The migrations applied to the configuration files are logged
+// as warnings (see LogConfigMigrations).
 //
 // Parameters:
 //   - logger: the zap.Logger to use for output.
@@ -41,196 +46,338 @@ func LogStartupInfo(
 		zap.String("executable", exePath),
 		zap.Time("start_time", time.Now()),
 	}
+	fields = append(fields, buildInfoFields()...)
 
 	// Sanitize and log each config struct under its type name
 	for _, cfg := range configs {
 		fields = append(fields, zap.Any(getTypeName(cfg), sanitizeConfig(cfg)))
+		if sources := configSources(cfg); len(sources) > 0 {
+			fields = append(fields, zap.Any(getTypeName(cfg)+"Sources", sources))
+		}
 	}
 
 	logger.Info(appName+" started", fields...)
+	LogConfigMigrations(logger)
+}
+
+// LogConfigMigrations logs a warning for every migration applied to the
+// configuration files when they were last loaded (see
+// gocli.RegisterConfigMigration), so that operators update the files written
+// for an older release. LogStartupInfo calls it; it may be called again after
+// a reload (see gocli.Watch).
+//
+// Parameters:
+//   - logger: the zap.Logger to use for output.
+func LogConfigMigrations(
+	logger *zap.Logger,
+) {
+	for _, m := range gocli.AppliedConfigMigrations() {
+		logger.Warn("Configuration file migrated from an older version",
+			zap.String("source", m.Source),
+			zap.Int("from_version", m.From),
+			zap.Int("to_version", m.To),
+			zap.String("migration", m.Description),
+		)
+	}
+}
+
+// configSources returns where the values of a configuration that do not come
+// from their default came from, by flag name.
+//
+// Parameters:
+//   - cfg: a configuration returned by a gocli registrar.
+//
+// Returns:
+//   - map[string]string: the source of each flag set (e.g., "env"), or nil if
+//     cfg was not returned by a registrar.
+func configSources(
+	cfg any,
+) map[string]string {
+	var sources map[string]string
+	for name, source := range gocli.Sources(cfg) {
+		if source == gocli.SourceDefault {
+			continue
+		}
+		if sources == nil {
+			sources = make(map[string]string)
+		}
+		sources[name] = source.String()
+	}
+	return sources
 }
 
 // SetupStdLogger creates and returns a zap.Logger that writes logs to standard output.
-// The logger format is JSON and the log level is determined by the given configuration.
+// The log level and output encoding (JSON or console) are determined by the given configuration.
 //
 // Parameters:
 //   - cfg: the logging configuration (standard output only).
 //
 // Returns:
 //   - *zap.Logger configured for stdout.
+//   - *Handle to adjust the logger at runtime (e.g., its level).
 //
-// Panics if the logger cannot be created.
+// Panics if the logger cannot be created. Use NewStdLogger to handle the error instead.
 func SetupStdLogger(
 	cfg *gocli.LogStdConfig,
-) *zap.Logger {
-	logger, err := newStdLogger(cfg.LogLevel)
+) (*zap.Logger, *Handle) {
+	logger, handle, err := NewStdLogger(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create logger: %v", err)
+	}
+	return logger, handle
+}
+
+// NewStdLogger is like SetupStdLogger but returns an error instead of terminating
+// the application, which makes it suitable for libraries and tests.
+//
+// Parameters:
+//   - cfg: the logging configuration (standard output only).
+//
+// Returns:
+//   - *zap.Logger configured for stdout.
+//   - *Handle to adjust the logger at runtime (e.g., its level).
+//   - error if the level or encoding is invalid.
+func NewStdLogger(
+	cfg *gocli.LogStdConfig,
+) (*zap.Logger, *Handle, error) {
+	return New(
+		WithLevel(cfg.LogLevel),
+		WithLevelOverrides(cfg.LogLevelOverrides),
+		WithEncoding(cfg.LogEncoder),
+		WithDevelopment(cfg.LogDev),
+		WithTimeFormat(cfg.LogTimeFormat, cfg.LogTimeUTC),
+		WithTimeZone(cfg.LogTimeZone),
+		WithSplitStderr(cfg.LogSplitStderr),
+		WithSampling(cfg.LogSamplingInitial, cfg.LogSamplingThereafter),
+		WithThrottle(cfg.LogThrottleRate, cfg.LogThrottleBurst),
+		WithDedup(cfg.LogDedupInterval),
+		WithCaller(cfg.LogCaller),
+		WithCallerSkip(cfg.LogCallerSkip),
+		WithStacktrace(cfg.LogStacktraceLevel),
+		WithMetrics(cfg.LogMetrics),
+		metadataFromConfig(cfg.LogMetadata, cfg.AppName, cfg.AppVersion),
+		WithKubernetesMetadata(cfg.LogK8sMetadata),
+	)
+}
+
+// SetupDevLogger creates and returns a zap.Logger for local development. It is
+// SetupStdLogger with cfg.LogDev forced on: entries are written to standard
+// output in zap's development format, with colored levels, short timestamps and
+// caller information, and DPanic entries panic. The level still comes from cfg.
+//
+// Parameters:
+//   - cfg: the logging configuration (standard output only). It is not modified.
+//
+// Returns:
+//   - *zap.Logger configured for development.
+//   - *Handle to adjust the logger at runtime (e.g., its level).
+//
+// Panics if the logger cannot be created. Use NewDevLogger to handle the error instead.
+func SetupDevLogger(
+	cfg *gocli.LogStdConfig,
+) (*zap.Logger, *Handle) {
+	logger, handle, err := NewDevLogger(cfg)
 	if err != nil {
 		log.Fatalf("Failed to create logger: %v", err)
 	}
-	return logger
+	return logger, handle
 }
 
+// NewDevLogger is like SetupDevLogger but returns an error instead of terminating
+// the application.
+func NewDevLogger(
+	cfg *gocli.LogStdConfig,
+) (*zap.Logger, *Handle, error) {
+	devCfg := *cfg
+	devCfg.LogDev = true
+	return NewStdLogger(&devCfg)
+}
+
+const (
+	// OutputFile writes logs to standard output and a rotating file (default).
+	OutputFile = "file"
+
+	// OutputJournald writes logs exclusively to the systemd journal, for processes
+	// running as systemd units where stdout would be captured a second time.
+	OutputJournald = "journald"
+)
+
 // SetupStdAndFileLogger creates and returns a zap.Logger that writes logs to both
 // standard output and a rotating file. File rotation settings are derived from the config.
+// When cfg.LogErrorFile is set, warn and above are also written to that file, rotated
+// with the same policy. Missing log directories are created up front with
+// cfg.LogDirMode permissions. When cfg.LogRotateOnSIGHUP is set, a SIGHUP handler
+// is installed that rotates the files, and when cfg.LogRotateInterval is set they
+// are also rotated on a schedule (e.g., daily at midnight UTC).
+//
+// If cfg.LogOutput is OutputJournald, entries are sent to journald instead and the
+// file settings are ignored. If cfg.LogOTLPEndpoint is set, entries are additionally
+// exported to an OpenTelemetry collector over OTLP/HTTP, and if cfg.LogLokiURL is
+// set they are pushed to Grafana Loki. When cfg.LogMetadata is set, hostname, PID,
+// application name and version are attached to every entry.
 //
 // Parameters:
 //   - cfg: the logging configuration, including file path and rotation policy.
 //
 // Returns:
 //   - *zap.Logger configured for dual output.
+//   - *Handle to adjust the logger at runtime (e.g., its level).
 //
-// Panics if the logger cannot be created.
+// Panics if the logger cannot be created. Use NewStdAndFileLogger to handle the error instead.
 func SetupStdAndFileLogger(
 	cfg *gocli.LogStdAndFileConfig,
-) *zap.Logger {
-	logger, err := newStdAndFileLogger(
-		&cfg.LogLevel,
-		&cfg.LogFile,
-		&cfg.LogMaxSize,
-		&cfg.LogMaxBackups,
-		&cfg.LogMaxAge,
-		&cfg.LogCompress,
-	)
+) (*zap.Logger, *Handle) {
+	logger, handle, err := NewStdAndFileLogger(cfg)
 	if err != nil {
 		log.Fatalf("Failed to create logger: %v", err)
 	}
-	return logger
+	return logger, handle
 }
 
-// newStdAndFileLogger builds a zap.Logger that writes to both stdout and a file with log rotation.
+// NewStdAndFileLogger is like SetupStdAndFileLogger but returns an error instead of
+// terminating the application, which makes it suitable for libraries and tests.
 //
 // Parameters:
-//   - logLevel: log verbosity level (e.g., "info", "debug").
-//   - file: path to the log file.
-//   - size: max size in MB before log rotation.
-//   - backups: number of old logs to retain.
-//   - age: max age in days for old logs.
-//   - compress: whether to compress old logs.
+//   - cfg: the logging configuration, including file path and rotation policy.
 //
 // Returns:
-//   - *zap.Logger configured with dual cores (file + stdout).
-//   - error if log level is invalid.
-func newStdAndFileLogger(
-	logLevel *string,
-	file *string,
-	size *int,
-	backups *int,
-	age *int,
-	compress *bool,
-) (*zap.Logger, error) {
-	level := zapcore.InfoLevel
-	if err := (&level).UnmarshalText([]byte(*logLevel)); err != nil {
-		return nil, fmt.Errorf("invalid log level: %w", err)
+//   - *zap.Logger configured for dual output.
+//   - *Handle to adjust the logger at runtime (e.g., its level).
+//   - error if the configuration is invalid or the output cannot be opened.
+func NewStdAndFileLogger(
+	cfg *gocli.LogStdAndFileConfig,
+) (*zap.Logger, *Handle, error) {
+	labels, err := ParseLokiLabels(cfg.LogLokiLabels)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	encoderCfg := zap.NewProductionEncoderConfig()
-	encoderCfg.TimeKey = "timestamp"
-	encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
-	encoder := zapcore.NewJSONEncoder(encoderCfg)
-
-	fileWriter := zapcore.AddSync(&lumberjack.Logger{
-		Filename:   *file,
-		MaxSize:    *size,
-		MaxBackups: *backups,
-		MaxAge:     *age,
-		Compress:   *compress,
-	})
-
-	stdoutWriter := zapcore.AddSync(os.Stdout)
-
-	fileCore := zapcore.NewCore(encoder, fileWriter, level)
-	stdoutCore := zapcore.NewCore(encoder, stdoutWriter, level)
+	opts := []Option{
+		WithLevel(cfg.LogLevel),
+		WithLevelOverrides(cfg.LogLevelOverrides),
+		WithSinkLevel(SinkStdout, cfg.LogStdoutLevel),
+		WithSinkLevel(SinkFile, cfg.LogFileLevel),
+		WithEncoding(cfg.LogEncoder),
+		WithTimeFormat(cfg.LogTimeFormat, cfg.LogTimeUTC),
+		WithTimeZone(cfg.LogTimeZone),
+		WithSplitStderr(cfg.LogSplitStderr),
+		WithSampling(cfg.LogSamplingInitial, cfg.LogSamplingThereafter),
+		WithThrottle(cfg.LogThrottleRate, cfg.LogThrottleBurst),
+		WithDedup(cfg.LogDedupInterval),
+		WithCaller(cfg.LogCaller),
+		WithCallerSkip(cfg.LogCallerSkip),
+		WithStacktrace(cfg.LogStacktraceLevel),
+		WithMetrics(cfg.LogMetrics),
+		metadataFromConfig(cfg.LogMetadata, cfg.AppName, cfg.AppVersion),
+		WithKubernetesMetadata(cfg.LogK8sMetadata),
+		WithOTLP(cfg.LogOTLPEndpoint, cfg.LogOTLPBatchSize, cfg.LogOTLPFlushInterval),
+		WithLoki(cfg.LogLokiURL, labels, cfg.LogLokiBatchSize, cfg.LogLokiFlushInterval),
+	}
 
-	core := zapcore.NewTee(fileCore, stdoutCore)
+	switch cfg.LogOutput {
+	case "", OutputFile:
+		opts = append(opts,
+			WithFile(cfg.LogFile),
+			WithErrorFile(cfg.LogErrorFile),
+			WithDirMode(cfg.LogDirMode),
+			WithFileMode(cfg.LogFileMode),
+			WithFileOwner(cfg.LogFileOwner),
+			WithFileErrorPolicy(cfg.LogFileErrorPolicy),
+			WithRotation(cfg.LogMaxSize, cfg.LogMaxBackups, cfg.LogMaxAge, cfg.LogCompress),
+			WithRotateOnSIGHUP(cfg.LogRotateOnSIGHUP),
+			WithRotateInterval(cfg.LogRotateInterval),
+		)
+		if cfg.LogEncryptKeyFile != "" || cfg.LogEncryptKeyEnv != "" {
+			key, err := loadEncryptionKey(cfg.LogEncryptKeyFile, cfg.LogEncryptKeyEnv)
+			if err != nil {
+				return nil, nil, err
+			}
+			opts = append(opts, WithEncryptionKey(key))
+		}
+	case OutputJournald:
+		opts = append(opts, WithJournald())
+	default:
+		return nil, nil, fmt.Errorf("invalid log output %q", cfg.LogOutput)
+	}
 
-	return zap.New(core), nil
+	return New(opts...)
 }
 
-// newStdLogger builds a zap.Logger that logs exclusively to stdout using the provided log level.
-//
-// Parameters:
-//   - logLevel: string representation of the desired log level.
-//
-// Returns:
-//   - *zap.Logger for stdout.
-//   - error if the log level is invalid.
-func newStdLogger(
-	logLevel string,
-) (*zap.Logger, error) {
-	level := zapcore.InfoLevel
-	if err := (&level).UnmarshalText([]byte(logLevel)); err != nil {
-		return nil, fmt.Errorf("invalid log level: %w", err)
+// metadataFromConfig returns WithMetadata when the metadata flag of a
+// configuration is set, and a no-op option otherwise.
+func metadataFromConfig(
+	enabled bool,
+	appName string,
+	appVersion string,
+) Option {
+	if !enabled {
+		return func(*options) {}
 	}
-
-	encoderCfg := zap.NewProductionEncoderConfig()
-	encoderCfg.TimeKey = "timestamp"
-	encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
-	encoder := zapcore.NewJSONEncoder(encoderCfg)
-
-	stdoutWriter := zapcore.AddSync(os.Stdout)
-	stdoutCore := zapcore.NewCore(encoder, stdoutWriter, level)
-
-	return zap.New(stdoutCore), nil
+	return WithMetadata(appName, appVersion)
 }
 
-// sanitizeConfig converts a struct to a map of field names to values,
-// intended for structured logging. Fields of type time.Duration are
-// converted to their string representation for readability.
+// newStdCore builds the core writing to the standard streams.
+//
+// When splitStderr is false, every enabled entry goes to stdout. Otherwise the
+// output is split in two level-filtered cores: debug/info to stdout and
+// warn and above to stderr, so that container log collectors relying on the
+// stream can classify severity.
 //
 // Parameters:
-//   - cfg: any struct (pointer or value) to sanitize.
+//   - encoder: encoder shared by both streams.
+//   - level: level enabler applied before the stream split.
+//   - splitStderr: whether warn and above go to stderr.
 //
 // Returns:
-//   - map[string]any: a sanitized representation of the struct.
-func sanitizeConfig(
-	cfg any,
-) any {
-	val := reflect.ValueOf(cfg)
-	if val.Kind() == reflect.Ptr {
-		val = val.Elem()
+//   - zapcore.Core writing to stdout and, optionally, stderr.
+func newStdCore(
+	encoder zapcore.Encoder,
+	level zapcore.LevelEnabler,
+	splitStderr bool,
+) zapcore.Core {
+	stdoutWriter := zapcore.AddSync(os.Stdout)
+	if !splitStderr {
+		return zapcore.NewCore(encoder, stdoutWriter, level)
 	}
 
-	out := make(map[string]any)
-	typ := val.Type()
-
-	for i := 0; i < val.NumField(); i++ {
-		field := val.Field(i)
-		fieldType := typ.Field(i)
-
-		var value any
-
-		// Convert time.Duration fields to their string representation (e.g., "5s")
-		switch field.Kind() {
-		case reflect.Int64:
-			if field.Type().PkgPath() == "time" && field.Type().Name() == "Duration" {
-				value = field.Interface().(time.Duration).String()
-			} else {
-				value = field.Interface()
-			}
-		default:
-			value = field.Interface()
-		}
+	stderrWriter := zapcore.AddSync(os.Stderr)
+	lowLevel := zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return l < zapcore.WarnLevel && level.Enabled(l)
+	})
+	highLevel := zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return l >= zapcore.WarnLevel && level.Enabled(l)
+	})
 
-		out[fieldType.Name] = value
-	}
+	return zapcore.NewTee(
+		zapcore.NewCore(encoder, stdoutWriter, lowLevel),
+		zapcore.NewCore(encoder.Clone(), stderrWriter, highLevel),
+	)
+}
 
-	return out
+// teeOption returns a zap.Option that duplicates every entry to the given core
+// in addition to the logger's existing cores.
+func teeOption(
+	extra zapcore.Core,
+) zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, extra)
+	})
 }
 
-// getTypeName returns the name of the type of the given value,
-// automatically dereferencing pointers.
+// parseLevel converts a textual log level into a zap.AtomicLevel.
 //
 // Parameters:
-//   - v: any value.
+//   - logLevel: string representation of the desired log level.
 //
 // Returns:
-//   - string: the underlying type name.
-func getTypeName(
-	v any,
-) string {
-	t := reflect.TypeOf(v)
-	if t.Kind() == reflect.Ptr {
-		t = t.Elem()
+//   - zap.AtomicLevel initialized to the parsed level.
+//   - error if the log level is invalid.
+func parseLevel(
+	logLevel string,
+) (zap.AtomicLevel, error) {
+	level, err := zap.ParseAtomicLevel(logLevel)
+	if err != nil {
+		return level, fmt.Errorf("invalid log level: %w", err)
 	}
-	return t.Name()
+	return level, nil
 }
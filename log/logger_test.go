@@ -0,0 +1,28 @@
+package golog
+
+import (
+	"io"
+	"testing"
+
+	gocli "github.com/kubensage/common/cli"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// newDiscardLogger returns a JSON logger writing to io.Discard, so that
+// benchmarks measure the encoding but not the output.
+func newDiscardLogger() *zap.Logger {
+	encoder := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	return zap.New(zapcore.NewCore(encoder, zapcore.AddSync(io.Discard), zapcore.DebugLevel))
+}
+
+func BenchmarkLogStartupInfo(b *testing.B) {
+	logger := newDiscardLogger()
+	logCfg := &gocli.LogStdAndFileConfig{LogLevel: "info", LogFile: "/var/log/app.log", LogMaxSize: 10, LogMaxBackups: 3}
+	syslogCfg := &gocli.LogSyslogConfig{LogLevel: "warn", SyslogNetwork: "udp", SyslogAddress: "10.0.0.1:514"}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		LogStartupInfo(logger, "bench", logCfg, syslogCfg)
+	}
+}
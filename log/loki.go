@@ -0,0 +1,219 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// lokiRequestTimeout bounds a single push request.
+const lokiRequestTimeout = 10 * time.Second
+
+// lokiEntry is a single encoded log line waiting to be pushed.
+type lokiEntry struct {
+	level string // entry level, added as the "level" stream label
+	ts    string // timestamp in Unix nanoseconds
+	line  string // JSON-encoded entry
+}
+
+// lokiPusher batches log lines and sends them to the Loki push API
+// (POST <url>/loki/api/v1/push). Every line carries the configured static labels
+// plus a "level" label.
+type lokiPusher struct {
+	*batcher[lokiEntry]
+	url    string
+	labels map[string]string
+	client *http.Client
+}
+
+// newLokiPusher creates a pusher and starts its background goroutine.
+//
+// Parameters:
+//   - url: base URL of the Loki server (e.g., "http://loki:3100").
+//   - labels: static stream labels (e.g., app, node, namespace).
+//   - batchSize: maximum number of lines per push.
+//   - interval: maximum time a line waits before being pushed.
+//
+// Returns:
+//   - *lokiPusher ready to accept entries.
+func newLokiPusher(
+	url string,
+	labels map[string]string,
+	batchSize int,
+	interval time.Duration,
+) *lokiPusher {
+	p := &lokiPusher{
+		url:    strings.TrimSuffix(url, "/") + "/loki/api/v1/push",
+		labels: labels,
+		client: &http.Client{Timeout: lokiRequestTimeout},
+	}
+	p.batcher = newBatcher(SinkLoki, batchSize, interval, p.push)
+	return p
+}
+
+// push groups a batch into one stream per level and sends it, retrying on
+// network errors, 429 and 5xx responses.
+func (p *lokiPusher) push(
+	batch []lokiEntry,
+) error {
+	streams := make(map[string]*lokiStream)
+	for _, e := range batch {
+		stream, ok := streams[e.level]
+		if !ok {
+			labels := make(map[string]string, len(p.labels)+1)
+			for k, v := range p.labels {
+				labels[k] = v
+			}
+			labels["level"] = e.level
+			stream = &lokiStream{Stream: labels}
+			streams[e.level] = stream
+		}
+		stream.Values = append(stream.Values, [2]string{e.ts, e.line})
+	}
+
+	req := lokiPushRequest{Streams: make([]*lokiStream, 0, len(streams))}
+	for _, stream := range streams {
+		req.Streams = append(req.Streams, stream)
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	return retryWithBackoff(func() (bool, error) {
+		resp, err := p.client.Post(p.url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return true, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return false, nil
+		}
+		return retryableStatus(resp.StatusCode), fmt.Errorf("loki returned %s", resp.Status)
+	})
+}
+
+// lokiCore is a zapcore.Core encoding entries as JSON lines for a lokiPusher.
+type lokiCore struct {
+	zapcore.LevelEnabler
+	encoder zapcore.Encoder
+	pusher  *lokiPusher
+}
+
+// newLokiCore returns a core pushing to the given pusher.
+func newLokiCore(
+	pusher *lokiPusher,
+	level zapcore.LevelEnabler,
+) zapcore.Core {
+	encoderCfg := zap.NewProductionEncoderConfig()
+	encoderCfg.TimeKey = "" // Loki stores the timestamp alongside the line
+
+	return &lokiCore{
+		LevelEnabler: level,
+		encoder:      zapcore.NewJSONEncoder(encoderCfg),
+		pusher:       pusher,
+	}
+}
+
+// With returns a copy of the core with the given fields added to every entry.
+func (c *lokiCore) With(
+	fields []zapcore.Field,
+) zapcore.Core {
+	clone := &lokiCore{
+		LevelEnabler: c.LevelEnabler,
+		encoder:      c.encoder.Clone(),
+		pusher:       c.pusher,
+	}
+	for i := range fields {
+		fields[i].AddTo(clone.encoder)
+	}
+	return clone
+}
+
+// Check adds the core to the checked entry if the entry level is enabled.
+func (c *lokiCore) Check(
+	ent zapcore.Entry,
+	ce *zapcore.CheckedEntry,
+) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write encodes the entry and queues it. Entries above error level (panic, fatal)
+// are flushed synchronously since the process may exit.
+func (c *lokiCore) Write(
+	ent zapcore.Entry,
+	fields []zapcore.Field,
+) error {
+	buf, err := c.encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	line := strings.TrimSuffix(buf.String(), "\n")
+	buf.Free()
+
+	err = c.pusher.enqueue(lokiEntry{
+		level: ent.Level.String(),
+		ts:    strconv.FormatInt(ent.Time.UnixNano(), 10),
+		line:  line,
+	})
+
+	if ent.Level > zapcore.ErrorLevel {
+		c.pusher.flush()
+	}
+	return err
+}
+
+// Sync blocks until all queued lines have been pushed.
+func (c *lokiCore) Sync() error {
+	c.pusher.flush()
+	return nil
+}
+
+// ParseLokiLabels parses a comma-separated list of key=value pairs
+// (e.g., "app=agent,node=worker-1") into a label map.
+//
+// Parameters:
+//   - s: the label list; an empty string yields an empty map.
+//
+// Returns:
+//   - map[string]string of labels.
+//   - error if a pair is malformed.
+func ParseLokiLabels(
+	s string,
+) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid loki label %q (expected key=value)", pair)
+		}
+		labels[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return labels, nil
+}
+
+// The types below mirror the Loki push API JSON payload.
+
+type lokiPushRequest struct {
+	Streams []*lokiStream `json:"streams"`
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
@@ -0,0 +1,114 @@
+package golog
+
+import (
+	"os"
+	"path/filepath"
+	"runtime/debug"
+
+	"github.com/kubensage/common/cli"
+	"go.uber.org/zap"
+)
+
+// metadataOption returns a zap.Option that attaches host and process metadata to
+// every entry written by the logger, so that lines aggregated from many nodes can
+// be told apart without relying on the startup line.
+//
+// The attached fields are "hostname", "pid", "app" and, when known, "app_version".
+// An empty appName defaults to the executable name, and an empty appVersion to
+// gocli.Version, injected at link time, else to the main module version recorded
+// in the binary (omitted for "(devel)" builds).
+//
+// Parameters:
+//   - enabled: whether metadata is attached; when false the option is a no-op.
+//   - appName: application name reported in the "app" field.
+//   - appVersion: application version reported in the "app_version" field.
+//
+// Returns:
+//   - zap.Option to pass to zap.New or Logger.WithOptions.
+func metadataOption(
+	enabled bool,
+	appName string,
+	appVersion string,
+) zap.Option {
+	if !enabled {
+		return zap.Fields()
+	}
+	return zap.Fields(metadataFields(appName, appVersion)...)
+}
+
+// metadataFields builds the fields attached by metadataOption.
+func metadataFields(
+	appName string,
+	appVersion string,
+) []zap.Field {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	if appName == "" {
+		appName = filepath.Base(os.Args[0])
+	}
+	if appVersion == "" {
+		appVersion = gocli.Version
+	}
+	if appVersion == "" {
+		if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "(devel)" {
+			appVersion = info.Main.Version
+		}
+	}
+
+	fields := []zap.Field{
+		zap.String("hostname", hostname),
+		zap.Int("pid", os.Getpid()),
+		zap.String("app", appName),
+	}
+	if appVersion != "" {
+		fields = append(fields, zap.String("app_version", appVersion))
+	}
+	return fields
+}
+
+const (
+	// EnvNodeName is the downward API variable holding the Kubernetes node name.
+	EnvNodeName = "NODE_NAME"
+
+	// EnvPodName is the downward API variable holding the Kubernetes pod name.
+	EnvPodName = "POD_NAME"
+
+	// EnvPodNamespace is the downward API variable holding the Kubernetes pod namespace.
+	EnvPodNamespace = "POD_NAMESPACE"
+)
+
+// kubernetesMetadataOption returns a zap.Option that attaches the Kubernetes
+// node, pod and namespace to every entry, so that the relay can attribute agent
+// logs without parsing file paths.
+//
+// The values are read from the environment variables EnvNodeName, EnvPodName
+// and EnvPodNamespace, to be set in the pod spec from the downward API
+// (fieldRef spec.nodeName, metadata.name and metadata.namespace). They are
+// attached as "node", "pod" and "namespace"; unset variables are omitted.
+//
+// Parameters:
+//   - enabled: whether metadata is attached; when false the option is a no-op.
+//
+// Returns:
+//   - zap.Option to pass to zap.New or Logger.WithOptions.
+func kubernetesMetadataOption(
+	enabled bool,
+) zap.Option {
+	if !enabled {
+		return zap.Fields()
+	}
+
+	var fields []zap.Field
+	for _, v := range []struct{ key, env string }{
+		{"node", EnvNodeName},
+		{"pod", EnvPodName},
+		{"namespace", EnvPodNamespace},
+	} {
+		if value := os.Getenv(v.env); value != "" {
+			fields = append(fields, zap.String(v.key, value))
+		}
+	}
+	return zap.Fields(fields...)
+}
@@ -0,0 +1,91 @@
+package golog
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LogMetrics counts written log entries by level and logger name and exposes
+// them as the Prometheus counter kubensage_log_entries_total, so that alerts can
+// fire on error-rate spikes of the process itself.
+//
+// Example:
+//
+//	metrics, err := golog.NewLogMetrics(prometheus.DefaultRegisterer)
+//	logger = logger.WithOptions(metrics.Option())
+type LogMetrics struct {
+	entries *prometheus.CounterVec
+}
+
+// NewLogMetrics creates the log counters and registers them with reg. If the
+// counters are already registered (e.g., by another logger of the same process),
+// the existing ones are shared.
+//
+// Parameters:
+//   - reg: the registerer to use, usually prometheus.DefaultRegisterer.
+//
+// Returns:
+//   - *LogMetrics counting the entries of the loggers it is attached to.
+//   - error if a different collector with the same name is already registered.
+func NewLogMetrics(
+	reg prometheus.Registerer,
+) (*LogMetrics, error) {
+	entries := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "kubensage",
+		Subsystem: "log",
+		Name:      "entries_total",
+		Help:      "Number of log entries written, by level and logger name.",
+	}, []string{"level", "logger"})
+
+	if err := reg.Register(entries); err != nil {
+		var already prometheus.AlreadyRegisteredError
+		if !errors.As(err, &already) {
+			return nil, err
+		}
+		existing, ok := already.ExistingCollector.(*prometheus.CounterVec)
+		if !ok {
+			return nil, err
+		}
+		entries = existing
+	}
+	return &LogMetrics{entries: entries}, nil
+}
+
+// Option returns a zap.Option that counts every entry written by the logger.
+// Entries discarded by the level or by sampling are not counted.
+func (m *LogMetrics) Option() zap.Option {
+	return zap.Hooks(m.observe)
+}
+
+// observe is the hook incrementing the counter of an entry.
+func (m *LogMetrics) observe(
+	ent zapcore.Entry,
+) error {
+	m.entries.WithLabelValues(ent.Level.String(), ent.LoggerName).Inc()
+	return nil
+}
+
+// metricsOptions returns the zap.Options counting entries in the default
+// Prometheus registry.
+//
+// Parameters:
+//   - enabled: whether entries are counted; when false no option is returned.
+//
+// Returns:
+//   - []zap.Option to pass to zap.New or Logger.WithOptions.
+//   - error if the counters cannot be registered.
+func metricsOptions(
+	enabled bool,
+) ([]zap.Option, error) {
+	if !enabled {
+		return nil, nil
+	}
+	metrics, err := NewLogMetrics(prometheus.DefaultRegisterer)
+	if err != nil {
+		return nil, err
+	}
+	return []zap.Option{metrics.Option()}, nil
+}
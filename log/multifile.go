@@ -0,0 +1,381 @@
+package golog
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// defaultMaxOpenFiles is the number of routed log files kept open when no limit
+// is configured.
+const defaultMaxOpenFiles = 64
+
+// FileKeyFunc returns the key routing an entry to its log file, given the
+// fields of the entry and of the logger it was written with. An empty key
+// routes the entry to the base log file.
+type FileKeyFunc func(fields []zapcore.Field) string
+
+// FieldKey returns a FileKeyFunc routing entries by the value of the named field
+// (e.g., "cluster" or "namespace"). When the field is set several times, the
+// last value wins.
+func FieldKey(
+	name string,
+) FileKeyFunc {
+	return func(fields []zapcore.Field) string {
+		for i := len(fields) - 1; i >= 0; i-- {
+			if fields[i].Key != name {
+				continue
+			}
+			enc := zapcore.NewMapObjectEncoder()
+			fields[i].AddTo(enc)
+			if value, ok := enc.Fields[name]; ok && value != nil {
+				return fmt.Sprint(value)
+			}
+		}
+		return ""
+	}
+}
+
+// NewMultiFileLogger is like New but writes each entry to a log file chosen by
+// keyFunc, e.g. one file per cluster or per namespace. The file of key k is the
+// file set with WithFile with "-k" inserted before its extension
+// (/var/log/relay/relay.log becomes /var/log/relay/relay-east.log), and entries
+// without a key go to the file itself. Characters other than letters, digits,
+// '.', '_' and '-' are replaced with '_' in keys.
+//
+// Every file is rotated on its own according to WithRotation. At most
+// WithMaxOpenFiles files are kept open; the least recently written one is closed
+// when another must be opened, and reopened on its next entry. WithErrorFile,
+// WithEncryptionKey, WithRotateOnSIGHUP and WithRotateInterval are not
+// supported, and Handle.Rotate does not rotate the routed files.
+//
+// Parameters:
+//   - keyFunc: extracts the routing key of an entry, e.g. FieldKey("cluster").
+//   - opts: the logger options; WithFile is required.
+//
+// Returns:
+//   - *zap.Logger writing to the routed files and to standard output.
+//   - *Handle to adjust the logger at runtime; Handle.Close closes the files.
+//   - error if an option is invalid or unsupported.
+//
+// Example:
+//
+//	logger, handle, err := golog.NewMultiFileLogger(golog.FieldKey("cluster"),
+//		golog.WithFile("/var/log/relay/relay.log"),
+//		golog.WithRotation(100, 5, 30, true),
+//	)
+//	logger.Info("agent connected", zap.String("cluster", "east")) // relay-east.log
+func NewMultiFileLogger(
+	keyFunc FileKeyFunc,
+	opts ...Option,
+) (*zap.Logger, *Handle, error) {
+	if keyFunc == nil {
+		return nil, nil, errors.New("multi-file logger requires a key function")
+	}
+	return New(append(opts, func(o *options) { o.fileKey = keyFunc })...)
+}
+
+// newMultiFileCore builds the core routing entries to per-key rotating files.
+//
+// Parameters:
+//   - o: the logger options holding the file settings and the key function.
+//   - handle: the handle of the logger being built.
+//   - encodeTime: timestamp encoder of the file entries.
+//
+// Returns:
+//   - zapcore.Core writing to the routed files.
+//   - error if the settings are invalid or unsupported.
+func newMultiFileCore(
+	o *options,
+	handle *Handle,
+	encodeTime zapcore.TimeEncoder,
+) (zapcore.Core, error) {
+	switch {
+	case o.errorFile != "":
+		return nil, errors.New("log error file is not supported by the multi-file logger")
+	case o.encryptionKey != nil:
+		return nil, errors.New("log encryption is not supported by the multi-file logger")
+	case o.rotateOnSIGHUP || o.rotateInterval > 0:
+		return nil, errors.New("SIGHUP and interval rotation are not supported by the multi-file logger")
+	}
+
+	dirMode, err := parseDirMode(o.dirMode)
+	if err != nil {
+		return nil, err
+	}
+
+	fileMode, err := parseFileMode(o.fileMode)
+	if err != nil {
+		return nil, err
+	}
+
+	uid, gid, err := parseFileOwner(o.fileOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := parseFileErrorPolicy(o.fileErrorPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	encoder, err := newEncoder(o.encoding, false, encodeTime)
+	if err != nil {
+		return nil, err
+	}
+
+	maxOpen := o.maxOpenFiles
+	if maxOpen <= 0 {
+		maxOpen = defaultMaxOpenFiles
+	}
+
+	stats := handle.sinkStats(SinkFile)
+	router := &fileRouter{
+		open:    make(map[string]*list.Element),
+		lru:     list.New(),
+		maxOpen: maxOpen,
+		writers: make(map[*failsafeWriter]struct{}),
+		openFile: func(key string) (*routedFile, error) {
+			path := routedFileName(o.file, key)
+			if err := ensureLogDir(path, dirMode); err != nil {
+				return nil, err
+			}
+			if err := prepareLogFile(path, fileMode, uid, gid); err != nil {
+				return nil, err
+			}
+			rotator := &lumberjack.Logger{
+				Filename:   path,
+				MaxSize:    o.maxSize,
+				MaxBackups: o.maxBackups,
+				MaxAge:     o.maxAge,
+				Compress:   o.compress,
+			}
+			writer := newFailsafeWriter(newRotationTracker(rotator, handle.rotated), path, policy, stats)
+			return &routedFile{key: key, rotator: rotator, writer: writer}, nil
+		},
+	}
+	for _, fn := range o.onRotate {
+		handle.OnRotate(fn)
+	}
+	handle.onClose(router.close)
+	handle.trackDropped(SinkFile, router.Dropped)
+
+	return &multiFileCore{
+		LevelEnabler: handle.sinkEnabler(SinkFile),
+		encoder:      encoder,
+		keyFunc:      o.fileKey,
+		router:       router,
+	}, nil
+}
+
+// routedFieldsPool recycles the slices joining the context and the fields of
+// an entry for the key function.
+var routedFieldsPool = sync.Pool{
+	New: func() any {
+		fields := make([]zapcore.Field, 0, 16)
+		return &fields
+	},
+}
+
+// multiFileCore is a zapcore.Core writing each entry to the file of its key.
+type multiFileCore struct {
+	zapcore.LevelEnabler
+	encoder zapcore.Encoder
+	context []zapcore.Field // fields added with With, passed to keyFunc
+	keyFunc FileKeyFunc
+	router  *fileRouter
+}
+
+// With returns a copy of the core with the given fields added to every entry.
+func (c *multiFileCore) With(
+	fields []zapcore.Field,
+) zapcore.Core {
+	clone := *c
+	clone.encoder = c.encoder.Clone()
+	clone.context = append(c.context[:len(c.context):len(c.context)], fields...)
+	for i := range fields {
+		fields[i].AddTo(clone.encoder)
+	}
+	return &clone
+}
+
+// Check adds the core to the checked entry if the entry level is enabled.
+func (c *multiFileCore) Check(
+	ent zapcore.Entry,
+	ce *zapcore.CheckedEntry,
+) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write encodes the entry and writes it to the file of its key.
+func (c *multiFileCore) Write(
+	ent zapcore.Entry,
+	fields []zapcore.Field,
+) error {
+	var key string
+	if len(c.context) == 0 {
+		key = c.keyFunc(fields)
+	} else {
+		all := routedFieldsPool.Get().(*[]zapcore.Field)
+		*all = append(append((*all)[:0], c.context...), fields...)
+		key = c.keyFunc(*all)
+		clear(*all)
+		routedFieldsPool.Put(all)
+	}
+
+	buf, err := c.encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+
+	return c.router.write(sanitizeFileKey(key), buf.Bytes())
+}
+
+// Sync is a no-op: entries are written to the files without buffering.
+func (c *multiFileCore) Sync() error {
+	return nil
+}
+
+// sanitizeFileKey replaces the characters of key that are not letters, digits,
+// '.', '_' or '-' with '_'. Clean keys are returned without allocating.
+func sanitizeFileKey(
+	key string,
+) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+}
+
+// routedFileName returns the file of a sanitized key: base with "-key" inserted
+// before its extension, or base itself for an empty key.
+func routedFileName(
+	base string,
+	key string,
+) string {
+	if key == "" {
+		return base
+	}
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "-" + key + ext
+}
+
+// routedFile is an open log file of a fileRouter.
+type routedFile struct {
+	key     string
+	rotator *lumberjack.Logger
+	writer  *failsafeWriter
+}
+
+// fileRouter keeps the most recently written files open, up to maxOpen, and
+// closes the least recently written one when another must be opened. Writes
+// are serialized so that a file is never closed while being written to.
+type fileRouter struct {
+	mu       sync.Mutex
+	open     map[string]*list.Element // by key, values are *routedFile
+	lru      *list.List               // most recently written first
+	maxOpen  int
+	openFile func(key string) (*routedFile, error)
+	closed   bool
+
+	// writersMu guards the writers of the open files and the drop count of the
+	// closed ones, so that close can abort a blocked write while it holds mu.
+	writersMu sync.Mutex
+	writers   map[*failsafeWriter]struct{}
+	dropped   uint64
+}
+
+// write writes p to the file of key, opening it if needed.
+func (r *fileRouter) write(
+	key string,
+	p []byte,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return errors.New("multi-file logger is closed")
+	}
+
+	elem, ok := r.open[key]
+	if ok {
+		r.lru.MoveToFront(elem)
+	} else {
+		file, err := r.openFile(key)
+		if err != nil {
+			return err
+		}
+		elem = r.lru.PushFront(file)
+		r.open[key] = elem
+
+		r.writersMu.Lock()
+		r.writers[file.writer] = struct{}{}
+		r.writersMu.Unlock()
+
+		for r.lru.Len() > r.maxOpen {
+			r.evict(r.lru.Back())
+		}
+	}
+
+	_, err := elem.Value.(*routedFile).writer.Write(p)
+	return err
+}
+
+// evict closes the file of elem and forgets it. The caller must hold r.mu.
+func (r *fileRouter) evict(
+	elem *list.Element,
+) {
+	file := r.lru.Remove(elem).(*routedFile)
+	delete(r.open, file.key)
+	file.writer.stop()
+	_ = file.rotator.Close()
+
+	r.writersMu.Lock()
+	delete(r.writers, file.writer)
+	r.dropped += file.writer.Dropped()
+	r.writersMu.Unlock()
+}
+
+// close closes every open file. Later writes fail.
+func (r *fileRouter) close() {
+	r.writersMu.Lock()
+	for writer := range r.writers {
+		writer.stop()
+	}
+	r.writersMu.Unlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for r.lru.Len() > 0 {
+		r.evict(r.lru.Back())
+	}
+	r.closed = true
+}
+
+// Dropped returns the number of entries that could not be written to the files.
+func (r *fileRouter) Dropped() uint64 {
+	r.writersMu.Lock()
+	defer r.writersMu.Unlock()
+
+	dropped := r.dropped
+	for writer := range r.writers {
+		dropped += writer.Dropped()
+	}
+	return dropped
+}
@@ -0,0 +1,36 @@
+package golog
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func BenchmarkMultiFileCoreWrite(b *testing.B) {
+	o := &options{
+		file:     filepath.Join(b.TempDir(), "relay.log"),
+		fileKey:  FieldKey("cluster"),
+		encoding: "json",
+	}
+	handle := newHandle(zap.NewAtomicLevel())
+	defer handle.Close()
+
+	core, err := newMultiFileCore(o, handle, zapcore.ISO8601TimeEncoder)
+	if err != nil {
+		b.Fatal(err)
+	}
+	core = core.With([]zapcore.Field{zap.String("node", "worker-1")})
+	ent := zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Now(), Message: "metrics relayed"}
+	fields := []zapcore.Field{zap.String("cluster", "east-1"), zap.Int("samples", 120)}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := core.Write(ent, fields); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,626 @@
+package golog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/kubensage/common/cli"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Option configures a logger built by New.
+type Option func(*options)
+
+// options holds the settings collected from the Options passed to New.
+type options struct {
+	level              string
+	levelOverrides     string
+	sinkLevels         []sinkLevel
+	encoding           string
+	timeFormat         string
+	timeUTC            bool
+	timeZone           string
+	splitStderr        bool
+	dev                bool
+	journald           bool
+	file               string
+	errorFile          string
+	dirMode            string
+	fileMode           string
+	fileOwner          string
+	fileErrorPolicy    string
+	fileKey            FileKeyFunc
+	maxOpenFiles       int
+	maxSize            int
+	maxBackups         int
+	maxAge             int
+	compress           bool
+	rotateOnSIGHUP     bool
+	rotateInterval     time.Duration
+	onRotate           []func(oldName, newName string)
+	encryptionKey      []byte
+	samplingInitial    int
+	samplingThereafter int
+	throttleRate       int
+	throttleBurst      int
+	dedupInterval      time.Duration
+	caller             bool
+	callerSkip         int
+	stacktraceLevel    string
+	metrics            bool
+	metadata           bool
+	k8sMetadata        bool
+	appName            string
+	appVersion         string
+	otlpEndpoint       string
+	otlpBatchSize      int
+	otlpFlushInterval  time.Duration
+	lokiURL            string
+	lokiLabels         map[string]string
+	lokiBatchSize      int
+	lokiFlushInterval  time.Duration
+}
+
+// sinkLevel is a level override requested with WithSinkLevel.
+type sinkLevel struct {
+	sink  string
+	level string
+}
+
+// WithLevel sets the shared log level ("debug", "info", "warn", "error", ...).
+// The default is "info".
+func WithLevel(
+	level string,
+) Option {
+	return func(o *options) { o.level = level }
+}
+
+// WithLevelOverrides sets per-subsystem levels as name=level pairs (e.g.,
+// "grpc=debug,buffer=warn"); see ParseLevelOverrides and Handle.Registry.
+func WithLevelOverrides(
+	overrides string,
+) Option {
+	return func(o *options) { o.levelOverrides = overrides }
+}
+
+// WithSinkLevel gives a sink (e.g., SinkStdout, SinkFile, SinkOTLP) its own
+// level instead of the shared one. An empty level keeps the shared level.
+func WithSinkLevel(
+	sink string,
+	level string,
+) Option {
+	return func(o *options) { o.sinkLevels = append(o.sinkLevels, sinkLevel{sink: sink, level: level}) }
+}
+
+// WithEncoding sets the output encoding: EncoderJSON (default), EncoderConsole
+// or EncoderECS.
+func WithEncoding(
+	encoding string,
+) Option {
+	return func(o *options) { o.encoding = encoding }
+}
+
+// WithTimeFormat sets the timestamp format (TimeFormatISO8601 by default) and
+// whether timestamps are written in UTC.
+func WithTimeFormat(
+	format string,
+	utc bool,
+) Option {
+	return func(o *options) { o.timeFormat, o.timeUTC = format, utc }
+}
+
+// WithTimeZone writes timestamps in a time zone of the tz database (e.g.,
+// "Europe/Rome") instead of the local one; see gocli.ParseTimeZone. An empty
+// zone keeps the local one, and the utc setting of WithTimeFormat overrides
+// it.
+func WithTimeZone(
+	zone string,
+) Option {
+	return func(o *options) { o.timeZone = zone }
+}
+
+// WithSplitStderr sends warn and above to stderr instead of stdout.
+func WithSplitStderr(
+	enabled bool,
+) Option {
+	return func(o *options) { o.splitStderr = enabled }
+}
+
+// WithDevelopment writes standard output in zap's development format, with
+// colored levels, short timestamps and caller information, and makes DPanic
+// entries panic. The file, if any, keeps the configured encoding.
+func WithDevelopment(
+	enabled bool,
+) Option {
+	return func(o *options) { o.dev = enabled }
+}
+
+// WithJournald sends entries to the systemd journal instead of standard output
+// and files, for processes running as systemd units.
+func WithJournald() Option {
+	return func(o *options) { o.journald = true }
+}
+
+// WithFile also writes entries to a rotating log file. Missing directories are
+// created at startup (see WithDirMode).
+func WithFile(
+	path string,
+) Option {
+	return func(o *options) { o.file = path }
+}
+
+// WithErrorFile also writes warn and above to a second file, rotated with the
+// same policy as the main one. It requires WithFile.
+func WithErrorFile(
+	path string,
+) Option {
+	return func(o *options) { o.errorFile = path }
+}
+
+// WithDirMode sets the octal permissions (e.g., "0750") of the log directories
+// created at startup. The default is "0755".
+func WithDirMode(
+	mode string,
+) Option {
+	return func(o *options) { o.dirMode = mode }
+}
+
+// WithFileMode sets the octal permissions (e.g., "0640") of the log files. They
+// are applied at startup, also to existing files, and kept across rotations.
+// The default is "0600".
+func WithFileMode(
+	mode string,
+) Option {
+	return func(o *options) { o.fileMode = mode }
+}
+
+// WithFileOwner sets the owner of the log files as "user", "user:group" or
+// ":group", with names or numeric IDs (e.g., "root:adm" to let the adm group
+// read the logs). Ownership is kept across rotations on Linux only. Changing
+// the owner usually requires root.
+func WithFileOwner(
+	owner string,
+) Option {
+	return func(o *options) { o.fileOwner = owner }
+}
+
+// WithFileErrorPolicy sets what happens to entries the log files cannot be
+// written to, for instance because the disk is full: FileErrorDrop (default),
+// FileErrorBlock or FileErrorStdout. Entries that did not reach the files are
+// counted by Handle.Dropped(SinkFile).
+func WithFileErrorPolicy(
+	policy string,
+) Option {
+	return func(o *options) { o.fileErrorPolicy = policy }
+}
+
+// WithMaxOpenFiles sets how many log files NewMultiFileLogger keeps open at once
+// (default 64). Non-positive values select the default.
+func WithMaxOpenFiles(
+	n int,
+) Option {
+	return func(o *options) { o.maxOpenFiles = n }
+}
+
+// WithRotation sets the size-based rotation policy of the log files. Zero values
+// select lumberjack's defaults: 100 MB files, kept forever.
+//
+// Parameters:
+//   - maxSize: maximum size in MB before a file is rotated.
+//   - maxBackups: maximum number of rotated files to retain.
+//   - maxAge: maximum age in days of rotated files.
+//   - compress: whether rotated files are gzip-compressed.
+func WithRotation(
+	maxSize int,
+	maxBackups int,
+	maxAge int,
+	compress bool,
+) Option {
+	return func(o *options) {
+		o.maxSize, o.maxBackups, o.maxAge, o.compress = maxSize, maxBackups, maxAge, compress
+	}
+}
+
+// WithRotateOnSIGHUP rotates the log files when the process receives SIGHUP.
+func WithRotateOnSIGHUP(
+	enabled bool,
+) Option {
+	return func(o *options) { o.rotateOnSIGHUP = enabled }
+}
+
+// WithRotateInterval also rotates the log files at every multiple of interval,
+// aligned on UTC (e.g., 24h rotates at midnight UTC). Zero disables it.
+func WithRotateInterval(
+	interval time.Duration,
+) Option {
+	return func(o *options) { o.rotateInterval = interval }
+}
+
+// WithOnRotate calls fn after each rotation of a log file with the path of the
+// rotated file and of the new one; see Handle.OnRotate. It may be repeated.
+func WithOnRotate(
+	fn func(oldName, newName string),
+) Option {
+	return func(o *options) { o.onRotate = append(o.onRotate, fn) }
+}
+
+// WithEncryptionKey encrypts rotated log files at rest with the given 32-byte
+// AES-256 key; see DecryptLogArchive. A nil key disables encryption.
+func WithEncryptionKey(
+	key []byte,
+) Option {
+	return func(o *options) { o.encryptionKey = key }
+}
+
+// WithSampling logs the first initial identical entries per second, then one
+// out of every thereafter. A non-positive initial disables sampling (default).
+func WithSampling(
+	initial int,
+	thereafter int,
+) Option {
+	return func(o *options) { o.samplingInitial, o.samplingThereafter = initial, thereafter }
+}
+
+// WithThrottle limits debug and info entries to rate per second and per level,
+// with bursts of up to burst entries, dropping the excess during event storms.
+// Warn and above are never throttled. A non-positive rate disables it (default).
+func WithThrottle(
+	rate int,
+	burst int,
+) Option {
+	return func(o *options) { o.throttleRate, o.throttleBurst = rate, burst }
+}
+
+// WithDedup suppresses identical consecutive entries, summarizing them at the
+// given interval. Zero disables it.
+func WithDedup(
+	interval time.Duration,
+) Option {
+	return func(o *options) { o.dedupInterval = interval }
+}
+
+// WithCaller includes the calling file and line in every entry.
+func WithCaller(
+	enabled bool,
+) Option {
+	return func(o *options) { o.caller = enabled }
+}
+
+// WithCallerSkip skips extra stack frames when reporting the caller, for
+// logging wrappers.
+func WithCallerSkip(
+	skip int,
+) Option {
+	return func(o *options) { o.callerSkip = skip }
+}
+
+// WithStacktrace attaches a stack trace to entries at or above level. An empty
+// level disables stack traces (default).
+func WithStacktrace(
+	level string,
+) Option {
+	return func(o *options) { o.stacktraceLevel = level }
+}
+
+// WithMetrics counts entries by level and logger in Prometheus (see LogMetrics).
+func WithMetrics(
+	enabled bool,
+) Option {
+	return func(o *options) { o.metrics = enabled }
+}
+
+// WithMetadata attaches hostname, PID, application name and version to every
+// entry. Empty values default to the executable name and module version.
+func WithMetadata(
+	appName string,
+	appVersion string,
+) Option {
+	return func(o *options) { o.metadata, o.appName, o.appVersion = true, appName, appVersion }
+}
+
+// WithKubernetesMetadata attaches the Kubernetes node, pod and namespace, read
+// from the downward API environment variables (NODE_NAME, POD_NAME and
+// POD_NAMESPACE), to every entry.
+func WithKubernetesMetadata(
+	enabled bool,
+) Option {
+	return func(o *options) { o.k8sMetadata = enabled }
+}
+
+// WithOTLP also exports entries to an OpenTelemetry collector over OTLP/HTTP,
+// with the JSON encoding; OTLP/gRPC is not supported. An empty endpoint
+// disables the export.
+func WithOTLP(
+	endpoint string,
+	batchSize int,
+	flushInterval time.Duration,
+) Option {
+	return func(o *options) {
+		o.otlpEndpoint, o.otlpBatchSize, o.otlpFlushInterval = endpoint, batchSize, flushInterval
+	}
+}
+
+// WithLoki also pushes entries to Grafana Loki with the given stream labels. An
+// empty url disables the push.
+func WithLoki(
+	url string,
+	labels map[string]string,
+	batchSize int,
+	flushInterval time.Duration,
+) Option {
+	return func(o *options) {
+		o.lokiURL, o.lokiLabels, o.lokiBatchSize, o.lokiFlushInterval = url, labels, batchSize, flushInterval
+	}
+}
+
+// New creates a logger from functional options. Without options it writes JSON
+// entries at info level and above to standard output.
+//
+// The Setup* and New*Logger functions build on it from their configuration
+// structs; New is meant for callers assembling the configuration themselves.
+//
+// Parameters:
+//   - opts: the options, applied in order; later options override earlier ones.
+//
+// Returns:
+//   - *zap.Logger writing to the configured outputs.
+//   - *Handle to adjust the logger at runtime and release its resources.
+//   - error if an option is invalid or an output cannot be opened.
+//
+// Example:
+//
+//	logger, handle, err := golog.New(
+//	    golog.WithLevel("debug"),
+//	    golog.WithFile("/var/log/kubensage/agent.log"),
+//	    golog.WithRotation(10, 5, 30, true),
+//	    golog.WithCaller(true),
+//	)
+func New(
+	opts ...Option,
+) (*zap.Logger, *Handle, error) {
+	o := &options{level: "info"}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	level, err := parseLevel(o.level)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	callerOpts, err := callerOptions(o.caller || o.dev, o.callerSkip, o.stacktraceLevel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metricsOpts, err := metricsOptions(o.metrics)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	overrides, err := ParseLevelOverrides(o.levelOverrides)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var loc *time.Location
+	switch {
+	case o.timeUTC:
+		loc = time.UTC
+	case o.timeZone != "":
+		if loc, err = gocli.ParseTimeZone(o.timeZone); err != nil {
+			return nil, nil, err
+		}
+	}
+	encodeTime, err := newTimeEncoder(o.timeFormat, loc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	handle := newHandle(level)
+	for _, sl := range o.sinkLevels {
+		if err := handle.overrideSinkLevel(sl.sink, sl.level); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var core zapcore.Core
+	if o.journald {
+		core, err = newJournaldCore(handle.sinkEnabler(SinkJournald))
+		if err != nil {
+			return nil, nil, err
+		}
+		core = handle.instrument(SinkJournald, core)
+	} else {
+		stdoutEncoder := newDevEncoder()
+		if !o.dev {
+			stdoutEncoder, err = newEncoder(o.encoding, true, encodeTime)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		core = handle.instrument(SinkStdout, newStdCore(stdoutEncoder, handle.sinkEnabler(SinkStdout), o.splitStderr))
+
+		if o.file != "" {
+			newCore := newFileCore
+			if o.fileKey != nil {
+				newCore = newMultiFileCore
+			}
+			fileCore, err := newCore(o, handle, encodeTime)
+			if err != nil {
+				handle.Close()
+				return nil, nil, err
+			}
+			core = zapcore.NewTee(handle.instrument(SinkFile, fileCore), core)
+		} else if o.errorFile != "" {
+			return nil, nil, fmt.Errorf("log error file %s requires a log file", o.errorFile)
+		} else if o.fileKey != nil {
+			return nil, nil, errors.New("multi-file logger requires a log file")
+		}
+	}
+
+	var logger *zap.Logger
+	if o.dev {
+		logger = zap.New(core, zap.Development())
+	} else {
+		logger = zap.New(core)
+	}
+
+	if o.otlpEndpoint != "" {
+		exporter := newOTLPExporter(o.otlpEndpoint, o.otlpBatchSize, o.otlpFlushInterval)
+		handle.onClose(exporter.stop)
+		handle.trackDropped(SinkOTLP, exporter.Dropped)
+		handle.trackErrors(SinkOTLP, exporter.sendStats())
+		logger = logger.WithOptions(teeOption(handle.instrument(SinkOTLP, newOTLPCore(exporter, handle.sinkEnabler(SinkOTLP)))))
+	}
+
+	if o.lokiURL != "" {
+		pusher := newLokiPusher(o.lokiURL, o.lokiLabels, o.lokiBatchSize, o.lokiFlushInterval)
+		handle.onClose(pusher.stop)
+		handle.trackDropped(SinkLoki, pusher.Dropped)
+		handle.trackErrors(SinkLoki, pusher.sendStats())
+		logger = logger.WithOptions(teeOption(handle.instrument(SinkLoki, newLokiCore(pusher, handle.sinkEnabler(SinkLoki)))))
+	}
+
+	zapOpts := append(callerOpts, metricsOpts...)
+	zapOpts = append(zapOpts,
+		samplingOption(o.samplingInitial, o.samplingThereafter),
+		throttleOption(o.throttleRate, o.throttleBurst),
+		metadataOption(o.metadata, o.appName, o.appVersion),
+		kubernetesMetadataOption(o.k8sMetadata),
+	)
+	logger = logger.WithOptions(zapOpts...)
+
+	if o.dedupInterval > 0 {
+		dedup := newDeduper(o.dedupInterval)
+		handle.onClose(dedup.stop)
+		logger = logger.WithOptions(dedup.option())
+	}
+
+	handle.registry = NewRegistry(logger, overrides)
+	return logger, handle, nil
+}
+
+// newFileCore builds the core writing to the rotating log file and, when
+// configured, to the warn+ error file. It registers the rotators on the handle
+// and starts the rotation and encryption goroutines, stopped by Handle.Close.
+//
+// Parameters:
+//   - o: the logger options holding the file settings.
+//   - handle: the handle of the logger being built.
+//   - encodeTime: timestamp encoder of the file entries.
+//
+// Returns:
+//   - zapcore.Core writing to the files.
+//   - error if the settings are invalid or a directory cannot be created.
+func newFileCore(
+	o *options,
+	handle *Handle,
+	encodeTime zapcore.TimeEncoder,
+) (zapcore.Core, error) {
+	dirMode, err := parseDirMode(o.dirMode)
+	if err != nil {
+		return nil, err
+	}
+
+	fileMode, err := parseFileMode(o.fileMode)
+	if err != nil {
+		return nil, err
+	}
+
+	uid, gid, err := parseFileOwner(o.fileOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := parseFileErrorPolicy(o.fileErrorPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	encoder, err := newEncoder(o.encoding, false, encodeTime)
+	if err != nil {
+		return nil, err
+	}
+
+	rotator := &lumberjack.Logger{
+		Filename:   o.file,
+		MaxSize:    o.maxSize,
+		MaxBackups: o.maxBackups,
+		MaxAge:     o.maxAge,
+		Compress:   o.compress,
+	}
+
+	rotators := []*lumberjack.Logger{rotator}
+
+	var errorRotator *lumberjack.Logger
+	if o.errorFile != "" {
+		errorRotator = &lumberjack.Logger{
+			Filename:   o.errorFile,
+			MaxSize:    o.maxSize,
+			MaxBackups: o.maxBackups,
+			MaxAge:     o.maxAge,
+			Compress:   o.compress,
+		}
+		rotators = append(rotators, errorRotator)
+	}
+
+	for _, r := range rotators {
+		if err := ensureLogDir(r.Filename, dirMode); err != nil {
+			return nil, err
+		}
+		if err := prepareLogFile(r.Filename, fileMode, uid, gid); err != nil {
+			return nil, err
+		}
+	}
+
+	writers := make([]io.Writer, len(rotators))
+	for i, r := range rotators {
+		tracker := newRotationTracker(r, handle.rotated)
+		handle.rotators = append(handle.rotators, tracker)
+		writers[i] = tracker
+	}
+	if o.encryptionKey != nil {
+		encryptor := newArchiveEncryptor(o.encryptionKey, rotators)
+		handle.OnRotate(func(string, string) { encryptor.notify() })
+		handle.onClose(encryptor.stop)
+	}
+	for _, fn := range o.onRotate {
+		handle.OnRotate(fn)
+	}
+
+	failsafes := make([]*failsafeWriter, len(rotators))
+	for i, r := range rotators {
+		failsafes[i] = newFailsafeWriter(writers[i], r.Filename, policy, handle.sinkStats(SinkFile))
+		handle.onClose(failsafes[i].stop)
+	}
+	handle.trackDropped(SinkFile, func() uint64 {
+		var dropped uint64
+		for _, f := range failsafes {
+			dropped += f.Dropped()
+		}
+		return dropped
+	})
+
+	fileLevel := handle.sinkEnabler(SinkFile)
+	core := zapcore.NewCore(encoder, zapcore.AddSync(failsafes[0]), fileLevel)
+
+	if errorRotator != nil {
+		errorLevel := zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+			return l >= zapcore.WarnLevel && fileLevel.Enabled(l)
+		})
+		core = zapcore.NewTee(core, zapcore.NewCore(encoder.Clone(), zapcore.AddSync(failsafes[1]), errorLevel))
+	}
+
+	if o.rotateOnSIGHUP {
+		handle.onClose(watchRotateSignal(handle.rotateAndReport))
+	}
+	if o.rotateInterval > 0 {
+		handle.onClose(scheduleRotation(o.rotateInterval, handle.rotateAndReport))
+	}
+	return core, nil
+}
@@ -0,0 +1,370 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// otlpRequestTimeout bounds a single export request.
+const otlpRequestTimeout = 10 * time.Second
+
+// otlpExporter sends log records to an OTLP/HTTP endpoint
+// (e.g., http://otel-collector:4318/v1/logs) using the JSON protobuf encoding.
+// Records are batched by a batcher and failed requests are retried with backoff.
+//
+// OTLP/gRPC is not supported: it would link gRPC into every binary logging with
+// golog, and collectors receive both transports (ports 4317 and 4318).
+type otlpExporter struct {
+	*batcher[otlpLogRecord]
+	endpoint string
+	service  string
+	client   *http.Client
+}
+
+// newOTLPExporter creates an exporter and starts its background goroutine.
+//
+// Parameters:
+//   - endpoint: full URL of the OTLP/HTTP logs endpoint.
+//   - batchSize: maximum number of records per request.
+//   - interval: maximum time a record waits before being sent.
+//
+// Returns:
+//   - *otlpExporter ready to accept records.
+func newOTLPExporter(
+	endpoint string,
+	batchSize int,
+	interval time.Duration,
+) *otlpExporter {
+	e := &otlpExporter{
+		endpoint: endpoint,
+		service:  filepath.Base(os.Args[0]),
+		client:   &http.Client{Timeout: otlpRequestTimeout},
+	}
+	e.batcher = newBatcher(SinkOTLP, batchSize, interval, e.export)
+	return e
+}
+
+// export sends one batch, retrying on network errors, 429 and 5xx responses.
+func (e *otlpExporter) export(
+	batch []otlpLogRecord,
+) error {
+	body, err := json.Marshal(otlpRequest{
+		ResourceLogs: []otlpResourceLogs{{
+			Resource: otlpResource{Attributes: []otlpKeyValue{
+				{Key: "service.name", Value: otlpAnyValue{StringValue: &e.service}},
+			}},
+			ScopeLogs: []otlpScopeLogs{{
+				Scope:      otlpScope{Name: "github.com/kubensage/common/log"},
+				LogRecords: batch,
+			}},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	return retryWithBackoff(func() (bool, error) {
+		return e.post(body)
+	})
+}
+
+// post performs a single export request.
+//
+// Returns:
+//   - bool: whether the request may be retried.
+//   - error: nil on success.
+func (e *otlpExporter) post(
+	body []byte,
+) (bool, error) {
+	resp, err := e.client.Post(e.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	return retryableStatus(resp.StatusCode), fmt.Errorf("otlp endpoint returned %s", resp.Status)
+}
+
+// otlpCore is a zapcore.Core that converts entries to OTLP log records and hands
+// them to an otlpExporter.
+type otlpCore struct {
+	zapcore.LevelEnabler
+	exporter *otlpExporter
+	context  []zapcore.Field // fields added through With
+}
+
+// newOTLPCore returns a core exporting to the given exporter.
+func newOTLPCore(
+	exporter *otlpExporter,
+	level zapcore.LevelEnabler,
+) zapcore.Core {
+	return &otlpCore{LevelEnabler: level, exporter: exporter}
+}
+
+// With returns a copy of the core with the given fields added to every entry.
+func (c *otlpCore) With(
+	fields []zapcore.Field,
+) zapcore.Core {
+	clone := *c
+	clone.context = append(append([]zapcore.Field{}, c.context...), fields...)
+	return &clone
+}
+
+// Check adds the core to the checked entry if the entry level is enabled.
+func (c *otlpCore) Check(
+	ent zapcore.Entry,
+	ce *zapcore.CheckedEntry,
+) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write converts the entry to an OTLP record and queues it. Entries above error
+// level (panic, fatal) are flushed synchronously since the process may exit.
+func (c *otlpCore) Write(
+	ent zapcore.Entry,
+	fields []zapcore.Field,
+) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.context {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	attributes := make([]otlpKeyValue, 0, len(enc.Fields)+2)
+	for k, v := range enc.Fields {
+		attributes = append(attributes, otlpKeyValue{Key: k, Value: toOTLPValue(v)})
+	}
+	if ent.LoggerName != "" {
+		attributes = append(attributes, otlpKeyValue{Key: "logger", Value: toOTLPValue(ent.LoggerName)})
+	}
+	if ent.Caller.Defined {
+		attributes = append(attributes, otlpKeyValue{Key: "code.filepath", Value: toOTLPValue(ent.Caller.File)})
+		attributes = append(attributes, otlpKeyValue{Key: "code.lineno", Value: toOTLPValue(int64(ent.Caller.Line))})
+	}
+	if ent.Stack != "" {
+		attributes = append(attributes, otlpKeyValue{Key: "exception.stacktrace", Value: toOTLPValue(ent.Stack)})
+	}
+
+	ts := strconv.FormatInt(ent.Time.UnixNano(), 10)
+	err := c.exporter.enqueue(otlpLogRecord{
+		TimeUnixNano:         ts,
+		ObservedTimeUnixNano: ts,
+		SeverityNumber:       otlpSeverity(ent.Level),
+		SeverityText:         ent.Level.CapitalString(),
+		Body:                 toOTLPValue(ent.Message),
+		Attributes:           attributes,
+	})
+
+	if ent.Level > zapcore.ErrorLevel {
+		c.exporter.flush()
+	}
+	return err
+}
+
+// Sync blocks until all queued records have been exported.
+func (c *otlpCore) Sync() error {
+	c.exporter.flush()
+	return nil
+}
+
+// otlpSeverity maps a zap level to the OTLP SeverityNumber.
+func otlpSeverity(
+	level zapcore.Level,
+) int {
+	switch level {
+	case zapcore.DebugLevel:
+		return 5
+	case zapcore.InfoLevel:
+		return 9
+	case zapcore.WarnLevel:
+		return 13
+	case zapcore.ErrorLevel:
+		return 17
+	case zapcore.DPanicLevel, zapcore.PanicLevel:
+		return 18
+	case zapcore.FatalLevel:
+		return 21
+	default:
+		return 0
+	}
+}
+
+// toOTLPValue converts a value produced by zapcore.MapObjectEncoder into an OTLP AnyValue.
+func toOTLPValue(
+	v any,
+) otlpAnyValue {
+	switch val := v.(type) {
+	case nil:
+		return otlpAnyValue{}
+	case string:
+		return otlpAnyValue{StringValue: &val}
+	case bool:
+		return otlpAnyValue{BoolValue: &val}
+	case int, int8, int16, int32, int64, uint8, uint16, uint32:
+		i := strconv.FormatInt(reflectInt(val), 10)
+		return otlpAnyValue{IntValue: &i}
+	case uint, uint64, uintptr:
+		u := reflect.ValueOf(val).Uint()
+		if u > math.MaxInt64 { // intValue is an int64
+			s := strconv.FormatUint(u, 10)
+			return otlpAnyValue{StringValue: &s}
+		}
+		i := strconv.FormatUint(u, 10)
+		return otlpAnyValue{IntValue: &i}
+	case float32:
+		return floatOTLPValue(float64(val))
+	case float64:
+		return floatOTLPValue(val)
+	case []any:
+		values := make([]otlpAnyValue, len(val))
+		for i, item := range val {
+			values[i] = toOTLPValue(item)
+		}
+		return otlpAnyValue{ArrayValue: &otlpArrayValue{Values: values}}
+	case map[string]any:
+		kvs := make([]otlpKeyValue, 0, len(val))
+		for k, item := range val {
+			kvs = append(kvs, otlpKeyValue{Key: k, Value: toOTLPValue(item)})
+		}
+		return otlpAnyValue{KvlistValue: &otlpKvList{Values: kvs}}
+	case time.Time:
+		s := val.Format(time.RFC3339Nano)
+		return otlpAnyValue{StringValue: &s}
+	case fmt.Stringer:
+		s := val.String()
+		return otlpAnyValue{StringValue: &s}
+	case error:
+		s := val.Error()
+		return otlpAnyValue{StringValue: &s}
+	default:
+		rv := reflect.ValueOf(val)
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Array:
+			values := make([]otlpAnyValue, rv.Len())
+			for i := range values {
+				values[i] = toOTLPValue(rv.Index(i).Interface())
+			}
+			return otlpAnyValue{ArrayValue: &otlpArrayValue{Values: values}}
+		case reflect.Map:
+			kvs := make([]otlpKeyValue, 0, rv.Len())
+			iter := rv.MapRange()
+			for iter.Next() {
+				kvs = append(kvs, otlpKeyValue{Key: fmt.Sprint(iter.Key().Interface()), Value: toOTLPValue(iter.Value().Interface())})
+			}
+			return otlpAnyValue{KvlistValue: &otlpKvList{Values: kvs}}
+		}
+		s := fmt.Sprint(val)
+		return otlpAnyValue{StringValue: &s}
+	}
+}
+
+// floatOTLPValue encodes a float, falling back to a string for NaN and infinities
+// which JSON cannot represent.
+func floatOTLPValue(
+	f float64,
+) otlpAnyValue {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		s := strconv.FormatFloat(f, 'g', -1, 64)
+		return otlpAnyValue{StringValue: &s}
+	}
+	return otlpAnyValue{DoubleValue: &f}
+}
+
+// reflectInt widens any signed (or small unsigned) integer to int64.
+func reflectInt(
+	v any,
+) int64 {
+	switch i := v.(type) {
+	case int:
+		return int64(i)
+	case int8:
+		return int64(i)
+	case int16:
+		return int64(i)
+	case int32:
+		return int64(i)
+	case int64:
+		return i
+	case uint8:
+		return int64(i)
+	case uint16:
+		return int64(i)
+	case uint32:
+		return int64(i)
+	default:
+		return 0
+	}
+}
+
+// The types below mirror the OTLP logs protobuf messages in their JSON encoding.
+
+type otlpRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 otlpAnyValue   `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string         `json:"stringValue,omitempty"`
+	BoolValue   *bool           `json:"boolValue,omitempty"`
+	IntValue    *string         `json:"intValue,omitempty"`
+	DoubleValue *float64        `json:"doubleValue,omitempty"`
+	ArrayValue  *otlpArrayValue `json:"arrayValue,omitempty"`
+	KvlistValue *otlpKvList     `json:"kvlistValue,omitempty"`
+}
+
+type otlpArrayValue struct {
+	Values []otlpAnyValue `json:"values"`
+}
+
+type otlpKvList struct {
+	Values []otlpKeyValue `json:"values"`
+}
@@ -0,0 +1,110 @@
+package golog
+
+import (
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LogPanicAndExit logs a recovered panic as a fatal entry carrying the panic
+// value and the stack, flushes the logger and terminates the process with the
+// given exit code. It never returns.
+//
+// It is meant for recovery code that has already called recover(); use
+// RecoverAndExit in a defer statement otherwise.
+//
+// Parameters:
+//   - logger: the logger receiving the entry; it is synced before exiting.
+//   - value: the value returned by recover().
+//   - code: the process exit code.
+func LogPanicAndExit(
+	logger *zap.Logger,
+	value any,
+	code int,
+) {
+	logger.
+		WithOptions(zap.WithFatalHook(exitHook{logger: logger, code: code})).
+		Fatal("panic recovered", panicFields(value)...)
+
+	// Unreachable unless the fatal level is disabled by the logger.
+	_ = logger.Sync()
+	os.Exit(code)
+}
+
+// RecoverAndExit recovers a panic of the calling goroutine and hands it to
+// LogPanicAndExit. It must be deferred directly; it does nothing when the
+// goroutine is not panicking.
+//
+// Parameters:
+//   - logger: the logger receiving the entry.
+//   - code: the process exit code.
+//
+// Example:
+//
+//	func main() {
+//		logger, handle := golog.SetupStdAndFileLogger(cfg)
+//		defer golog.RecoverAndExit(logger, 2)
+//		...
+//	}
+func RecoverAndExit(
+	logger *zap.Logger,
+	code int,
+) {
+	if value := recover(); value != nil {
+		LogPanicAndExit(logger, value, code)
+	}
+}
+
+// RecoverAndLog recovers a panic of the calling goroutine and logs it as an
+// error entry carrying the panic value and the stack, letting the goroutine
+// end normally. It must be deferred directly; it does nothing when the
+// goroutine is not panicking.
+//
+// Example:
+//
+//	go func() {
+//		defer golog.RecoverAndLog(logger)
+//		...
+//	}()
+func RecoverAndLog(
+	logger *zap.Logger,
+) {
+	if value := recover(); value != nil {
+		logger.Error("panic recovered", panicFields(value)...)
+	}
+}
+
+// panicFields returns the fields describing a recovered panic: the value (as
+// an error when it is one), its type and the stack of the current goroutine.
+func panicFields(
+	value any,
+) []zap.Field {
+	fields := make([]zap.Field, 0, 3)
+	if err, ok := value.(error); ok {
+		fields = append(fields, zap.NamedError("panic", err))
+	} else {
+		fields = append(fields, zap.Any("panic", value))
+	}
+	return append(fields,
+		zap.String("panic_type", fmt.Sprintf("%T", value)),
+		zap.StackSkip("panic_stack", 1),
+	)
+}
+
+// exitHook is a zapcore.CheckWriteHook syncing the logger before exiting with
+// a custom code, unlike zap's default fatal hook.
+type exitHook struct {
+	logger *zap.Logger
+	code   int
+}
+
+// OnWrite flushes the logger and terminates the process.
+func (h exitHook) OnWrite(
+	*zapcore.CheckedEntry,
+	[]zapcore.Field,
+) {
+	_ = h.logger.Sync()
+	os.Exit(h.code)
+}
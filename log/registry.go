@@ -0,0 +1,205 @@
+package golog
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Registry hands out named loggers (e.g., "grpc", "buffer") whose levels can be
+// adjusted independently of each other and of the shared level.
+//
+// A subsystem without a level follows the logger it was derived from. Once a
+// level is set, it replaces the shared level for that subsystem: a subsystem at
+// "debug" emits debug entries even if the rest of the process logs at "info".
+// Sinks with their own level (see WithSinkLevel) keep applying it, so a file
+// sink at "warn" still receives warn entries only.
+type Registry struct {
+	base    *zap.Logger
+	mu      sync.Mutex
+	entries map[string]*subsystemLevel
+}
+
+// subsystemLevel is the adjustable level of a named logger.
+type subsystemLevel struct {
+	level zap.AtomicLevel
+	set   atomic.Bool // false while the subsystem follows the base logger
+}
+
+// defaultRegistry backs the package-level Named function.
+var defaultRegistry atomic.Pointer[Registry]
+
+func init() {
+	defaultRegistry.Store(NewRegistry(zap.NewNop(), nil))
+}
+
+// NewRegistry creates a registry deriving its loggers from base.
+//
+// Parameters:
+//   - base: the logger every named logger derives from.
+//   - overrides: initial per-subsystem levels, usually from ParseLevelOverrides.
+//
+// Returns:
+//   - *Registry ready to hand out named loggers.
+func NewRegistry(
+	base *zap.Logger,
+	overrides map[string]zapcore.Level,
+) *Registry {
+	r := &Registry{base: base, entries: make(map[string]*subsystemLevel)}
+	for name, level := range overrides {
+		entry := r.entry(name)
+		entry.level.SetLevel(level)
+		entry.set.Store(true)
+	}
+	return r
+}
+
+// Named returns a logger for the given subsystem, named after it and filtered by
+// its level. Loggers returned for the same name share the same level.
+func (r *Registry) Named(
+	name string,
+) *zap.Logger {
+	return r.base.Named(name).With(subsystemField(r.entry(name)))
+}
+
+// SetLevel sets the level of a subsystem, detaching it from the shared level.
+//
+// Parameters:
+//   - name: subsystem name, as passed to Named.
+//   - level: textual level such as "debug", "info", "warn" or "error".
+//
+// Returns:
+//   - error if the level cannot be parsed; the current level is left unchanged.
+func (r *Registry) SetLevel(
+	name string,
+	level string,
+) error {
+	parsed, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("invalid log level: %w", err)
+	}
+	entry := r.entry(name)
+	entry.level.SetLevel(parsed)
+	entry.set.Store(true)
+	return nil
+}
+
+// ResetLevel makes a subsystem follow the shared level again.
+func (r *Registry) ResetLevel(
+	name string,
+) {
+	r.entry(name).set.Store(false)
+}
+
+// Level returns the level of a subsystem.
+//
+// Returns:
+//   - the subsystem level.
+//   - false if the subsystem follows the shared level.
+func (r *Registry) Level(
+	name string,
+) (zapcore.Level, bool) {
+	entry := r.entry(name)
+	return entry.level.Level(), entry.set.Load()
+}
+
+// withBase returns a registry deriving its loggers from base while sharing the
+// subsystem levels of r. It is used when sinks are added to a logger after its
+// registry was created, so that named loggers obtained afterwards include them.
+func (r *Registry) withBase(
+	base *zap.Logger,
+) *Registry {
+	if r == nil {
+		return NewRegistry(base, nil)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := make(map[string]*subsystemLevel, len(r.entries))
+	for name, entry := range r.entries {
+		entries[name] = entry
+	}
+	return &Registry{base: base, entries: entries}
+}
+
+// entry returns the level of a subsystem, creating it on first use.
+func (r *Registry) entry(
+	name string,
+) *subsystemLevel {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.entries[name]
+	if !ok {
+		entry = &subsystemLevel{level: zap.NewAtomicLevel()}
+		r.entries[name] = entry
+	}
+	return entry
+}
+
+// Named returns a logger for the given subsystem from the default registry.
+// The default registry discards everything until ReplaceRegistry is called.
+//
+// Example:
+//
+//	logger, handle := golog.SetupStdAndFileLogger(cfg)
+//	golog.ReplaceRegistry(handle.Registry())
+//	grpcLogger := golog.Named("grpc")
+func Named(
+	name string,
+) *zap.Logger {
+	return defaultRegistry.Load().Named(name)
+}
+
+// ReplaceRegistry installs r as the registry used by Named. Loggers obtained
+// before the call keep writing through the previous registry.
+func ReplaceRegistry(
+	r *Registry,
+) {
+	defaultRegistry.Store(r)
+}
+
+// ParseLevelOverrides parses a comma-separated list of subsystem=level pairs
+// (e.g., "grpc=debug,buffer=warn").
+//
+// Parameters:
+//   - s: the override list; an empty string yields an empty map.
+//
+// Returns:
+//   - map[string]zapcore.Level of subsystem levels.
+//   - error if a pair or a level is malformed.
+func ParseLevelOverrides(
+	s string,
+) (map[string]zapcore.Level, error) {
+	overrides := make(map[string]zapcore.Level)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid level override %q (expected name=level)", pair)
+		}
+		level, err := zapcore.ParseLevel(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid level override %q: %w", pair, err)
+		}
+		overrides[name] = level
+	}
+	return overrides, nil
+}
+
+// subsystemField returns the field binding the sinks of a named logger to the
+// subsystem level (see statsCore). It is skipped by the encoders.
+func subsystemField(
+	level *subsystemLevel,
+) zapcore.Field {
+	return zapcore.Field{Type: zapcore.SkipType, Interface: level}
+}
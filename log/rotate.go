@@ -0,0 +1,195 @@
+package golog
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// watchRotateSignal installs a SIGHUP handler that rotates the log files every
+// time the signal is received. This allows external logrotate workflows
+// (copy/move + "kill -HUP") to make the process reopen its log files.
+//
+// Parameters:
+//   - rotate: rotates the files, usually Handle.rotateAndReport.
+//
+// Returns:
+//   - a function that uninstalls the signal handler and stops the watcher goroutine.
+func watchRotateSignal(
+	rotate func(),
+) func() {
+	sigCh := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigCh, syscall.SIGHUP)
+
+	go func() {
+		for {
+			select {
+			case <-sigCh:
+				rotate()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigCh)
+		close(done)
+	}
+}
+
+// scheduleRotation rotates the log files at every multiple of interval, in
+// addition to lumberjack's size-based rotation. Boundaries are aligned on UTC:
+// 24h rotates at midnight UTC and 1h at the top of every hour. Rotated files
+// are subject to the usual MaxBackups and MaxAge cleanup.
+//
+// Parameters:
+//   - interval: time between rotations; must be positive.
+//   - rotate: rotates the files, usually Handle.rotateAndReport.
+//
+// Returns:
+//   - a function that stops the scheduler goroutine.
+func scheduleRotation(
+	interval time.Duration,
+	rotate func(),
+) func() {
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			now := time.Now()
+			timer := time.NewTimer(now.Truncate(interval).Add(interval).Sub(now))
+
+			select {
+			case <-timer.C:
+				rotate()
+			case <-done:
+				timer.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+	}
+}
+
+// backupTimeLength is the length of the timestamp lumberjack inserts in the
+// names of rotated files (format 2006-01-02T15-04-05.000).
+const backupTimeLength = len("2006-01-02T15-04-05.000")
+
+// rotationTracker forwards writes to a lumberjack logger and reports every
+// rotation, whether triggered by size or by Handle.Rotate. It mirrors
+// lumberjack's byte count to know when a write makes it rotate the file.
+type rotationTracker struct {
+	rotator *lumberjack.Logger
+	rotated func(oldName, newName string) // called after each rotation, outside the lock
+	mu      sync.Mutex
+	size    int64 // bytes in the current file
+}
+
+// newRotationTracker wraps a rotator whose file has already been created.
+func newRotationTracker(
+	rotator *lumberjack.Logger,
+	rotated func(oldName, newName string),
+) *rotationTracker {
+	t := &rotationTracker{rotator: rotator, rotated: rotated}
+	if info, err := os.Stat(rotator.Filename); err == nil {
+		t.size = info.Size()
+	}
+	return t
+}
+
+// Write writes p to the rotator.
+func (t *rotationTracker) Write(
+	p []byte,
+) (int, error) {
+	maxSize := int64(t.rotator.MaxSize) * 1024 * 1024
+	if maxSize <= 0 {
+		maxSize = 100 * 1024 * 1024 // lumberjack's default
+	}
+
+	t.mu.Lock()
+	rotating := int64(len(p)) <= maxSize && t.size+int64(len(p)) > maxSize
+	n, err := t.rotator.Write(p)
+	if rotating && err == nil {
+		t.size = int64(n)
+	} else {
+		t.size += int64(n)
+	}
+	t.mu.Unlock()
+
+	if rotating && err == nil {
+		t.report()
+	}
+	return n, err
+}
+
+// rotate rotates the file immediately.
+func (t *rotationTracker) rotate() error {
+	t.mu.Lock()
+	err := t.rotator.Rotate()
+	if err == nil {
+		t.size = 0
+	}
+	t.mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("failed to rotate log file %s: %w", t.rotator.Filename, err)
+	}
+	t.report()
+	return nil
+}
+
+// report calls rotated with the newest rotated file, if it can be found.
+func (t *rotationTracker) report() {
+	if backup := lastBackup(t.rotator.Filename); backup != "" {
+		t.rotated(backup, t.rotator.Filename)
+	}
+}
+
+// lastBackup returns the path of the most recent file rotated out of filename
+// (e.g., app-2024-05-01T00-00-00.000.log for app.log), compressed or not, or
+// an empty string if there is none.
+func lastBackup(
+	filename string,
+) string {
+	dir := filepath.Dir(filename)
+	ext := filepath.Ext(filename)
+	prefix := strings.TrimSuffix(filepath.Base(filename), ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+
+	var newest, newestTime string
+	for _, entry := range entries {
+		name := entry.Name()
+		stamp, ok := strings.CutPrefix(strings.TrimSuffix(name, ".gz"), prefix)
+		if !ok {
+			continue
+		}
+		stamp, ok = strings.CutSuffix(stamp, ext)
+		if !ok || len(stamp) != backupTimeLength {
+			continue
+		}
+		// The timestamp format sorts lexically.
+		if stamp > newestTime {
+			newest, newestTime = name, stamp
+		}
+	}
+	if newest == "" {
+		return ""
+	}
+	return filepath.Join(dir, newest)
+}
@@ -0,0 +1,35 @@
+package golog
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// samplingTick is the interval over which sampling counters are reset.
+const samplingTick = time.Second
+
+// samplingOption returns a zap.Option that wraps the logger core in a sampler.
+//
+// Within each second, the first `initial` entries with a given level and message
+// are logged, then only every `thereafter`-th one. Sampling is disabled when
+// initial is not positive.
+//
+// Parameters:
+//   - initial: number of identical entries logged per second before sampling starts.
+//   - thereafter: after `initial`, log one entry out of every `thereafter`.
+//
+// Returns:
+//   - zap.Option to pass to zap.New or Logger.WithOptions.
+func samplingOption(
+	initial int,
+	thereafter int,
+) zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if initial <= 0 {
+			return core
+		}
+		return zapcore.NewSamplerWithOptions(core, samplingTick, initial, thereafter)
+	})
+}
@@ -0,0 +1,222 @@
+package golog
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// maxSanitizeDepth bounds how deep sanitizeConfig descends into nested values.
+const maxSanitizeDepth = 10
+
+// durationType is the reflect.Type of time.Duration.
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// LogValuer is implemented by configuration types that control how they appear
+// in the startup log (see LogStartupInfo), for instance to mask credentials or
+// to log a compact summary instead of every field.
+type LogValuer interface {
+	// LogValue returns the value to log in place of the receiver. It is logged
+	// as is, without further sanitization.
+	LogValue() any
+}
+
+// sanitizeConfig converts a configuration value into a representation suitable
+// for structured logging.
+//
+// Values implementing LogValuer are logged as their LogValue, and other values
+// implementing fmt.Stringer (e.g., net.IP, time.Location) as their String, so
+// that types control their own representation instead of being walked field by
+// field; gocli.SecretString values are thus logged masked as "***". net.IPNet values are logged in CIDR notation, url.URL values as their
+// string without the user info (which may hold a password), and byte slices as
+// their length only, since they often hold keys or certificates.
+//
+// Other structs become maps of exported field names to values, and nested structs,
+// pointers, slices, arrays, and maps are walked recursively. Fields of type
+// time.Duration are converted to their string representation for readability.
+// Recursion stops at maxSanitizeDepth, and values already being visited (cycles
+// through pointers or maps) are rendered as a placeholder instead of looping.
+//
+// Field names are taken from the `log:"..."` struct tag when present, then from
+// the `flag:"..."` and `json:"..."` tags, falling back to the Go field name. A tag value of "-"
+// omits the field.
+//
+// Parameters:
+//   - cfg: any value (usually a struct or pointer to struct) to sanitize.
+//
+// Returns:
+//   - any: a sanitized representation of the value.
+func sanitizeConfig(
+	cfg any,
+) any {
+	return sanitizeValue(reflect.ValueOf(cfg), 0, make(map[uintptr]bool))
+}
+
+// sanitizeValue is the recursive worker behind sanitizeConfig.
+//
+// Parameters:
+//   - val: the value to sanitize.
+//   - depth: current nesting depth.
+//   - visiting: addresses of pointers and maps on the current path, for cycle detection.
+//
+// Returns:
+//   - any: a sanitized representation of val.
+func sanitizeValue(
+	val reflect.Value,
+	depth int,
+	visiting map[uintptr]bool,
+) any {
+	if !val.IsValid() {
+		return nil
+	}
+	if depth > maxSanitizeDepth {
+		return "<max depth exceeded>"
+	}
+	if (val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface) && val.IsNil() {
+		return nil
+	}
+
+	if val.Type() == durationType {
+		return time.Duration(val.Int()).String()
+	}
+	if val.CanInterface() {
+		switch v := val.Interface().(type) {
+		case time.Time:
+			return v
+		case net.IPNet:
+			return v.String()
+		case *net.IPNet:
+			return v.String()
+		case url.URL:
+			return redactURL(v)
+		case *url.URL:
+			return redactURL(*v)
+		case LogValuer:
+			return v.LogValue()
+		case fmt.Stringer:
+			return v.String()
+		}
+	}
+
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if val.Kind() == reflect.Ptr {
+			addr := val.Pointer()
+			if visiting[addr] {
+				return "<cycle>"
+			}
+			visiting[addr] = true
+			defer delete(visiting, addr)
+		}
+		return sanitizeValue(val.Elem(), depth+1, visiting)
+
+	case reflect.Struct:
+		out := make(map[string]any, val.NumField())
+		typ := val.Type()
+		for i := 0; i < val.NumField(); i++ {
+			fieldType := typ.Field(i)
+			if !fieldType.IsExported() {
+				continue
+			}
+			name, ok := fieldName(fieldType)
+			if !ok {
+				continue
+			}
+			out[name] = sanitizeValue(val.Field(i), depth+1, visiting)
+		}
+		return out
+
+	case reflect.Slice, reflect.Array:
+		if val.Kind() == reflect.Slice && val.IsNil() {
+			return nil
+		}
+		if val.Kind() == reflect.Slice && val.Type().Elem().Kind() == reflect.Uint8 {
+			return fmt.Sprintf("<%d bytes>", val.Len())
+		}
+		out := make([]any, val.Len())
+		for i := 0; i < val.Len(); i++ {
+			out[i] = sanitizeValue(val.Index(i), depth+1, visiting)
+		}
+		return out
+
+	case reflect.Map:
+		if val.IsNil() {
+			return nil
+		}
+		addr := val.Pointer()
+		if visiting[addr] {
+			return "<cycle>"
+		}
+		visiting[addr] = true
+		defer delete(visiting, addr)
+
+		out := make(map[string]any, val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			out[fmt.Sprint(iter.Key().Interface())] = sanitizeValue(iter.Value(), depth+1, visiting)
+		}
+		return out
+
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return val.Type().String()
+
+	default:
+		return val.Interface()
+	}
+}
+
+// redactURL returns the string form of u without its user info.
+func redactURL(
+	u url.URL,
+) string {
+	u.User = nil
+	return u.String()
+}
+
+// fieldName returns the key under which a struct field is logged.
+//
+// Parameters:
+//   - field: the struct field.
+//
+// Returns:
+//   - string: the name from the `log` tag, else the `flag` tag, else the `json` tag, else the Go field name.
+//   - bool: false if the field is explicitly excluded with a "-" tag.
+func fieldName(
+	field reflect.StructField,
+) (string, bool) {
+	for _, key := range []string{"log", "flag", "json"} {
+		tag, ok := field.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			return "", false
+		}
+		if name != "" {
+			return name, true
+		}
+	}
+	return field.Name, true
+}
+
+// getTypeName returns the name of the type of the given value,
+// automatically dereferencing pointers.
+//
+// Parameters:
+//   - v: any value.
+//
+// Returns:
+//   - string: the underlying type name.
+func getTypeName(
+	v any,
+) string {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
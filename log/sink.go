@@ -0,0 +1,148 @@
+package golog
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/kubensage/common/cli"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SinkFactory builds the core of a custom sink.
+//
+// The level enabler passed in is the sink's own level (see WithSinkLevel), or
+// enables every level when the sink follows the shared level, which the logger
+// applies before the sink together with the subsystem levels (see Registry); the
+// factory should use it for its core so that runtime level changes apply to the
+// sink. If the returned core implements io.Closer, it is
+// closed by Handle.Close.
+type SinkFactory func(level zapcore.LevelEnabler) (zapcore.Core, error)
+
+var (
+	sinksMu sync.RWMutex
+	sinks   = make(map[string]SinkFactory)
+)
+
+// RegisterSink makes a custom sink available by name to SetupLogger and
+// NewLogger, so that applications can attach their own cores (e.g., a gRPC
+// log-forwarding core) without modifying this package. It is meant to be called
+// from an init function.
+//
+// Panics if factory is nil or a sink with the same name is already registered.
+//
+// Parameters:
+//   - name: the sink name passed to SetupLogger.
+//   - factory: builds the sink core.
+func RegisterSink(
+	name string,
+	factory SinkFactory,
+) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+
+	if factory == nil {
+		panic("golog: RegisterSink factory is nil")
+	}
+	if _, ok := sinks[name]; ok {
+		panic("golog: RegisterSink called twice for sink " + name)
+	}
+	sinks[name] = factory
+}
+
+// Sinks returns the names of the registered custom sinks, sorted.
+func Sinks() []string {
+	sinksMu.RLock()
+	defer sinksMu.RUnlock()
+
+	names := make([]string, 0, len(sinks))
+	for name := range sinks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetupLogger creates a logger from any golog configuration and tees it with the
+// given registered sinks.
+//
+// Parameters:
+//   - cfg: a *gocli.LogStdConfig, *gocli.LogStdAndFileConfig or *gocli.LogSyslogConfig.
+//   - sinkNames: names of sinks registered with RegisterSink.
+//
+// Returns:
+//   - *zap.Logger writing to the configured outputs and to the sinks.
+//   - *Handle to adjust the logger at runtime and close the sinks.
+//
+// Panics if the logger cannot be created. Use NewLogger to handle the error instead.
+func SetupLogger(
+	cfg any,
+	sinkNames ...string,
+) (*zap.Logger, *Handle) {
+	logger, handle, err := NewLogger(cfg, sinkNames...)
+	if err != nil {
+		log.Fatalf("Failed to create logger: %v", err)
+	}
+	return logger, handle
+}
+
+// NewLogger is like SetupLogger but returns an error instead of terminating the
+// application.
+func NewLogger(
+	cfg any,
+	sinkNames ...string,
+) (*zap.Logger, *Handle, error) {
+	factories := make([]SinkFactory, len(sinkNames))
+	sinksMu.RLock()
+	for i, name := range sinkNames {
+		factories[i] = sinks[name]
+	}
+	sinksMu.RUnlock()
+	for i, factory := range factories {
+		if factory == nil {
+			return nil, nil, fmt.Errorf("unknown log sink %q", sinkNames[i])
+		}
+	}
+
+	var logger *zap.Logger
+	var handle *Handle
+	var err error
+
+	switch c := cfg.(type) {
+	case *gocli.LogStdConfig:
+		logger, handle, err = NewStdLogger(c)
+	case *gocli.LogStdAndFileConfig:
+		logger, handle, err = NewStdAndFileLogger(c)
+	case *gocli.LogSyslogConfig:
+		logger, handle, err = NewStdAndSyslogLogger(c)
+	default:
+		return nil, nil, fmt.Errorf("unsupported log configuration %T", cfg)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(factories) == 0 {
+		return logger, handle, nil
+	}
+
+	cores := make([]zapcore.Core, 0, len(factories))
+	for i, factory := range factories {
+		core, err := factory(handle.sinkEnabler(sinkNames[i]))
+		if err != nil {
+			handle.Close()
+			return nil, nil, fmt.Errorf("log sink %q: %w", sinkNames[i], err)
+		}
+		if closer, ok := core.(io.Closer); ok {
+			handle.onClose(func() { _ = closer.Close() })
+		}
+		cores = append(cores, handle.instrument(sinkNames[i], core))
+	}
+
+	logger = logger.WithOptions(teeOption(zapcore.NewTee(cores...)))
+	handle.registry = handle.registry.withBase(logger)
+	return logger, handle, nil
+}
@@ -0,0 +1,310 @@
+//go:build !windows && !plan9
+
+package golog
+
+import (
+	"fmt"
+	"log"
+	"log/syslog"
+	"strings"
+
+	"github.com/kubensage/common/cli"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// syslogFacilities maps facility names accepted in configuration to syslog priorities.
+var syslogFacilities = map[string]syslog.Priority{
+	"kern":     syslog.LOG_KERN,
+	"user":     syslog.LOG_USER,
+	"mail":     syslog.LOG_MAIL,
+	"daemon":   syslog.LOG_DAEMON,
+	"auth":     syslog.LOG_AUTH,
+	"syslog":   syslog.LOG_SYSLOG,
+	"lpr":      syslog.LOG_LPR,
+	"news":     syslog.LOG_NEWS,
+	"uucp":     syslog.LOG_UUCP,
+	"cron":     syslog.LOG_CRON,
+	"authpriv": syslog.LOG_AUTHPRIV,
+	"ftp":      syslog.LOG_FTP,
+	"local0":   syslog.LOG_LOCAL0,
+	"local1":   syslog.LOG_LOCAL1,
+	"local2":   syslog.LOG_LOCAL2,
+	"local3":   syslog.LOG_LOCAL3,
+	"local4":   syslog.LOG_LOCAL4,
+	"local5":   syslog.LOG_LOCAL5,
+	"local6":   syslog.LOG_LOCAL6,
+	"local7":   syslog.LOG_LOCAL7,
+}
+
+// SetupSyslogLogger creates and returns a zap.Logger that writes JSON-encoded
+// entries to the local or a remote syslog daemon. zap levels are mapped to the
+// corresponding syslog severities.
+//
+// With cfg.SyslogNetwork set to "tls", entries are sent as RFC 5424 messages over
+// TLS (RFC 5425), verifying the server certificate, so that logs can be shipped
+// to a central syslog without a local forwarder. Lost connections are reopened
+// with an exponential backoff; messages that cannot be sent meanwhile are lost.
+//
+// Parameters:
+//   - cfg: the syslog configuration (network, address, facility, tag, level).
+//
+// Returns:
+//   - *zap.Logger configured for syslog.
+//   - *Handle to adjust the logger at runtime (e.g., its level).
+//
+// Panics if the logger cannot be created. Use NewSyslogLogger to handle the error instead.
+func SetupSyslogLogger(
+	cfg *gocli.LogSyslogConfig,
+) (*zap.Logger, *Handle) {
+	logger, handle, err := NewSyslogLogger(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create logger: %v", err)
+	}
+	return logger, handle
+}
+
+// NewSyslogLogger is like SetupSyslogLogger but returns an error instead of
+// terminating the application.
+func NewSyslogLogger(
+	cfg *gocli.LogSyslogConfig,
+) (*zap.Logger, *Handle, error) {
+	level, err := parseLevel(cfg.LogLevel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	callerOpts, err := callerOptions(cfg.LogCaller, cfg.LogCallerSkip, cfg.LogStacktraceLevel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metricsOpts, err := metricsOptions(cfg.LogMetrics)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	overrides, err := ParseLevelOverrides(cfg.LogLevelOverrides)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	handle := newHandle(level)
+	core, err := newSyslogCore(cfg, handle, handle.sinkEnabler(SinkSyslog))
+	if err != nil {
+		return nil, nil, err
+	}
+	logger := zap.New(core, callerOpts...)
+	logger = logger.WithOptions(metricsOpts...)
+	logger = logger.WithOptions(
+		metadataOption(cfg.LogMetadata, cfg.AppName, cfg.AppVersion),
+		kubernetesMetadataOption(cfg.LogK8sMetadata),
+	)
+
+	if cfg.LogDedupInterval > 0 {
+		dedup := newDeduper(cfg.LogDedupInterval)
+		handle.onClose(dedup.stop)
+		logger = logger.WithOptions(dedup.option())
+	}
+
+	handle.registry = NewRegistry(logger, overrides)
+	return logger, handle, nil
+}
+
+// SetupStdAndSyslogLogger creates and returns a zap.Logger that writes logs to both
+// standard output and syslog. Standard output honors cfg.LogEncoder, while syslog
+// always receives JSON.
+//
+// Parameters:
+//   - cfg: the syslog configuration (network, address, facility, tag, level).
+//
+// Returns:
+//   - *zap.Logger configured for dual output.
+//   - *Handle to adjust the logger at runtime (e.g., its level).
+//
+// Panics if the logger cannot be created. Use NewStdAndSyslogLogger to handle the error instead.
+func SetupStdAndSyslogLogger(
+	cfg *gocli.LogSyslogConfig,
+) (*zap.Logger, *Handle) {
+	logger, handle, err := NewStdAndSyslogLogger(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create logger: %v", err)
+	}
+	return logger, handle
+}
+
+// NewStdAndSyslogLogger is like SetupStdAndSyslogLogger but returns an error instead
+// of terminating the application.
+func NewStdAndSyslogLogger(
+	cfg *gocli.LogSyslogConfig,
+) (*zap.Logger, *Handle, error) {
+	level, err := parseLevel(cfg.LogLevel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	callerOpts, err := callerOptions(cfg.LogCaller, cfg.LogCallerSkip, cfg.LogStacktraceLevel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metricsOpts, err := metricsOptions(cfg.LogMetrics)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	overrides, err := ParseLevelOverrides(cfg.LogLevelOverrides)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stdoutEncoder, err := newEncoder(cfg.LogEncoder, true, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	handle := newHandle(level)
+	stdoutCore := handle.instrument(SinkStdout, newStdCore(stdoutEncoder, handle.sinkEnabler(SinkStdout), false))
+
+	syslogCore, err := newSyslogCore(cfg, handle, handle.sinkEnabler(SinkSyslog))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logger := zap.New(zapcore.NewTee(syslogCore, stdoutCore), callerOpts...)
+	logger = logger.WithOptions(metricsOpts...)
+	logger = logger.WithOptions(
+		metadataOption(cfg.LogMetadata, cfg.AppName, cfg.AppVersion),
+		kubernetesMetadataOption(cfg.LogK8sMetadata),
+	)
+
+	if cfg.LogDedupInterval > 0 {
+		dedup := newDeduper(cfg.LogDedupInterval)
+		handle.onClose(dedup.stop)
+		logger = logger.WithOptions(dedup.option())
+	}
+
+	handle.registry = NewRegistry(logger, overrides)
+	return logger, handle, nil
+}
+
+// newSyslogCore dials the syslog daemon described by cfg and wraps the connection
+// in a zapcore.Core. With the "tls" network, entries are instead queued and sent
+// in the background over TLS, and the sender's shutdown and drop counter are
+// registered on the handle. The core is instrumented for Handle.Health.
+//
+// Parameters:
+//   - cfg: the syslog configuration.
+//   - handle: the handle of the logger being built.
+//   - level: level enabler for the core.
+//
+// Returns:
+//   - zapcore.Core writing to syslog.
+//   - error if the facility is unknown or the daemon cannot be reached.
+func newSyslogCore(
+	cfg *gocli.LogSyslogConfig,
+	handle *Handle,
+	level zapcore.LevelEnabler,
+) (zapcore.Core, error) {
+	facility, ok := syslogFacilities[strings.ToLower(cfg.SyslogFacility)]
+	if !ok {
+		return nil, fmt.Errorf("invalid syslog facility %q", cfg.SyslogFacility)
+	}
+
+	if cfg.SyslogNetwork == syslogTLSNetwork {
+		if cfg.SyslogAddress == "" {
+			return nil, fmt.Errorf("syslog address is required with the %q network", syslogTLSNetwork)
+		}
+		tlsConfig, err := newSyslogTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		sender := newSyslogTLSSender(cfg.SyslogAddress, tlsConfig)
+		handle.onClose(sender.stop)
+		handle.trackDropped(SinkSyslog, sender.Dropped)
+		handle.trackErrors(SinkSyslog, sender.sendStats())
+		return handle.instrument(SinkSyslog, newSyslogTLSCore(sender, facility, cfg.SyslogTag, level)), nil
+	}
+
+	writer, err := syslog.Dial(cfg.SyslogNetwork, cfg.SyslogAddress, facility|syslog.LOG_INFO, cfg.SyslogTag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+
+	encoderCfg := zap.NewProductionEncoderConfig()
+	encoderCfg.TimeKey = "" // syslog stamps every message itself
+
+	return handle.instrument(SinkSyslog, &syslogCore{
+		LevelEnabler: level,
+		encoder:      zapcore.NewJSONEncoder(encoderCfg),
+		writer:       writer,
+	}), nil
+}
+
+// syslogCore is a zapcore.Core that sends each encoded entry to syslog using the
+// severity matching the entry level.
+type syslogCore struct {
+	zapcore.LevelEnabler
+	encoder zapcore.Encoder
+	writer  *syslog.Writer
+}
+
+// With returns a copy of the core with the given fields added to every entry.
+func (c *syslogCore) With(
+	fields []zapcore.Field,
+) zapcore.Core {
+	clone := &syslogCore{
+		LevelEnabler: c.LevelEnabler,
+		encoder:      c.encoder.Clone(),
+		writer:       c.writer,
+	}
+	for i := range fields {
+		fields[i].AddTo(clone.encoder)
+	}
+	return clone
+}
+
+// Check adds the core to the checked entry if the entry level is enabled.
+func (c *syslogCore) Check(
+	ent zapcore.Entry,
+	ce *zapcore.CheckedEntry,
+) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write encodes the entry and forwards it to syslog with the mapped severity.
+func (c *syslogCore) Write(
+	ent zapcore.Entry,
+	fields []zapcore.Field,
+) error {
+	buf, err := c.encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	msg := strings.TrimSuffix(buf.String(), "\n")
+	buf.Free()
+
+	switch ent.Level {
+	case zapcore.DebugLevel:
+		return c.writer.Debug(msg)
+	case zapcore.InfoLevel:
+		return c.writer.Info(msg)
+	case zapcore.WarnLevel:
+		return c.writer.Warning(msg)
+	case zapcore.ErrorLevel:
+		return c.writer.Err(msg)
+	case zapcore.DPanicLevel, zapcore.PanicLevel:
+		return c.writer.Crit(msg)
+	case zapcore.FatalLevel:
+		return c.writer.Emerg(msg)
+	default:
+		return c.writer.Info(msg)
+	}
+}
+
+// Sync is a no-op: the syslog writer does not buffer messages.
+func (c *syslogCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,310 @@
+//go:build !windows && !plan9
+
+package golog
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/syslog"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kubensage/common/cli"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// syslogTLSNetwork is the SyslogNetwork value selecting syslog over TLS.
+	syslogTLSNetwork = "tls"
+
+	// syslogTLSFlushInterval is the maximum time an entry waits before being sent.
+	syslogTLSFlushInterval = time.Second
+
+	// syslogTLSTimeout bounds the connection handshake and every write.
+	syslogTLSTimeout = 10 * time.Second
+
+	// syslogTLSBackoffMin is the delay before reconnecting after a first failure.
+	syslogTLSBackoffMin = time.Second
+
+	// syslogTLSBackoffMax caps the delay between reconnection attempts.
+	syslogTLSBackoffMax = time.Minute
+)
+
+// newSyslogTLSConfig builds the TLS settings of a syslog connection.
+//
+// Parameters:
+//   - cfg: the syslog configuration holding the certificate files and options.
+//
+// Returns:
+//   - *tls.Config verifying the server against the configured or system CAs.
+//   - error if a certificate file cannot be loaded.
+func newSyslogTLSConfig(
+	cfg *gocli.LogSyslogConfig,
+) (*tls.Config, error) {
+	tlsCfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.SyslogTLSServerName,
+		InsecureSkipVerify: cfg.SyslogTLSInsecure,
+	}
+
+	if cfg.SyslogTLSCAFile != "" {
+		pem, err := os.ReadFile(cfg.SyslogTLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read syslog CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in syslog CA file %s", cfg.SyslogTLSCAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if cfg.SyslogTLSCertFile != "" || cfg.SyslogTLSKeyFile != "" {
+		if cfg.SyslogTLSCertFile == "" || cfg.SyslogTLSKeyFile == "" {
+			return nil, errors.New("syslog client certificate and key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.SyslogTLSCertFile, cfg.SyslogTLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load syslog client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	return tlsCfg, nil
+}
+
+// syslogTLSSender batches RFC 5424 messages and writes them to a remote syslog
+// server over TLS, framed by octet counting as required by RFC 5425.
+//
+// The connection is opened on the first batch and reopened after a failure,
+// waiting between attempts with an exponential backoff. Batches that cannot be
+// sent are reported on stderr by the batcher and lost.
+type syslogTLSSender struct {
+	*batcher[[]byte]
+	address   string
+	tlsConfig *tls.Config
+
+	mu        sync.Mutex // guards the fields below, used by the batcher goroutine and stop
+	conn      *tls.Conn
+	backoff   time.Duration // delay before the next attempt after a failure
+	nextDial  time.Time     // earliest time of the next connection attempt
+	lastError error         // failure of the last connection attempt
+}
+
+// newSyslogTLSSender creates a sender for the given server and starts its
+// batcher.
+func newSyslogTLSSender(
+	address string,
+	tlsConfig *tls.Config,
+) *syslogTLSSender {
+	s := &syslogTLSSender{address: address, tlsConfig: tlsConfig}
+	s.batcher = newBatcher(SinkSyslog, 0, syslogTLSFlushInterval, s.send)
+	return s
+}
+
+// send writes one batch, reconnecting once if the current connection fails.
+func (s *syslogTLSSender) send(
+	batch [][]byte,
+) error {
+	var payload bytes.Buffer
+	for _, msg := range batch {
+		payload.WriteString(strconv.Itoa(len(msg)))
+		payload.WriteByte(' ')
+		payload.Write(msg)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for attempt := 0; ; attempt++ {
+		if err := s.connect(); err != nil {
+			return err
+		}
+
+		_ = s.conn.SetWriteDeadline(time.Now().Add(syslogTLSTimeout))
+		_, err := s.conn.Write(payload.Bytes())
+		if err == nil {
+			return nil
+		}
+
+		// The server may have closed an idle connection: retry once on a new one.
+		_ = s.conn.Close()
+		s.conn = nil
+		if attempt > 0 {
+			return fmt.Errorf("failed to write to syslog server %s: %w", s.address, err)
+		}
+	}
+}
+
+// connect opens a connection if there is none and the backoff allows it. The
+// caller must hold s.mu.
+func (s *syslogTLSSender) connect() error {
+	if s.conn != nil {
+		return nil
+	}
+	if time.Now().Before(s.nextDial) {
+		return fmt.Errorf("syslog server %s unavailable, retrying in %s: %w",
+			s.address, time.Until(s.nextDial).Round(time.Second), s.lastError)
+	}
+
+	dialer := &net.Dialer{Timeout: syslogTLSTimeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", s.address, s.tlsConfig)
+	if err != nil {
+		if s.backoff == 0 {
+			s.backoff = syslogTLSBackoffMin
+		} else {
+			s.backoff = min(s.backoff*2, syslogTLSBackoffMax)
+		}
+		s.nextDial = time.Now().Add(s.backoff)
+		s.lastError = err
+		return fmt.Errorf("failed to connect to syslog server %s: %w", s.address, err)
+	}
+
+	s.conn, s.backoff, s.lastError = conn, 0, nil
+	return nil
+}
+
+// stop sends the pending messages and closes the connection.
+func (s *syslogTLSSender) stop() {
+	s.batcher.stop()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+}
+
+// syslogTLSCore is a zapcore.Core formatting entries as RFC 5424 messages for
+// a syslogTLSSender.
+type syslogTLSCore struct {
+	zapcore.LevelEnabler
+	encoder  zapcore.Encoder
+	sender   *syslogTLSSender
+	facility syslog.Priority
+	header   string // HOSTNAME APP-NAME PROCID MSGID, shared by every message
+}
+
+// newSyslogTLSCore returns a core sending to the given sender.
+//
+// Parameters:
+//   - sender: the sender writing to the server.
+//   - facility: syslog facility of the messages.
+//   - tag: APP-NAME of the messages; "-" when empty.
+//   - level: level enabler for the core.
+func newSyslogTLSCore(
+	sender *syslogTLSSender,
+	facility syslog.Priority,
+	tag string,
+	level zapcore.LevelEnabler,
+) zapcore.Core {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	if tag == "" {
+		tag = "-"
+	}
+
+	encoderCfg := zap.NewProductionEncoderConfig()
+	encoderCfg.TimeKey = "" // the message header carries the timestamp
+
+	return &syslogTLSCore{
+		LevelEnabler: level,
+		encoder:      zapcore.NewJSONEncoder(encoderCfg),
+		sender:       sender,
+		facility:     facility,
+		header:       hostname + " " + tag + " " + strconv.Itoa(os.Getpid()) + " - -",
+	}
+}
+
+// With returns a copy of the core with the given fields added to every entry.
+func (c *syslogTLSCore) With(
+	fields []zapcore.Field,
+) zapcore.Core {
+	clone := *c
+	clone.encoder = c.encoder.Clone()
+	for i := range fields {
+		fields[i].AddTo(clone.encoder)
+	}
+	return &clone
+}
+
+// Check adds the core to the checked entry if the entry level is enabled.
+func (c *syslogTLSCore) Check(
+	ent zapcore.Entry,
+	ce *zapcore.CheckedEntry,
+) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write formats the entry as "<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID
+// SD MSG", with the JSON-encoded entry as MSG, and queues it. Entries above
+// error level (panic, fatal) are flushed synchronously since the process may
+// exit.
+func (c *syslogTLSCore) Write(
+	ent zapcore.Entry,
+	fields []zapcore.Field,
+) error {
+	buf, err := c.encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	body := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+
+	msg := make([]byte, 0, len(c.header)+len(body)+48)
+	msg = append(msg, '<')
+	msg = strconv.AppendInt(msg, int64(c.facility|syslogSeverity(ent.Level)), 10)
+	msg = append(msg, ">1 "...)
+	msg = ent.Time.UTC().AppendFormat(msg, "2006-01-02T15:04:05.000000Z07:00")
+	msg = append(msg, ' ')
+	msg = append(msg, c.header...)
+	msg = append(msg, ' ')
+	msg = append(msg, body...)
+	buf.Free()
+
+	err = c.sender.enqueue(msg)
+
+	if ent.Level > zapcore.ErrorLevel {
+		c.sender.flush()
+	}
+	return err
+}
+
+// Sync blocks until all queued messages have been sent.
+func (c *syslogTLSCore) Sync() error {
+	c.sender.flush()
+	return nil
+}
+
+// syslogSeverity maps a zap level to a syslog severity.
+func syslogSeverity(
+	level zapcore.Level,
+) syslog.Priority {
+	switch level {
+	case zapcore.DebugLevel:
+		return syslog.LOG_DEBUG
+	case zapcore.InfoLevel:
+		return syslog.LOG_INFO
+	case zapcore.WarnLevel:
+		return syslog.LOG_WARNING
+	case zapcore.ErrorLevel:
+		return syslog.LOG_ERR
+	case zapcore.DPanicLevel, zapcore.PanicLevel:
+		return syslog.LOG_CRIT
+	case zapcore.FatalLevel:
+		return syslog.LOG_EMERG
+	default:
+		return syslog.LOG_INFO
+	}
+}
@@ -0,0 +1,46 @@
+//go:build windows || plan9
+
+package golog
+
+import (
+	"errors"
+	"log"
+
+	"github.com/kubensage/common/cli"
+	"go.uber.org/zap"
+)
+
+// errSyslogUnsupported is returned by the syslog constructors on platforms without syslog.
+var errSyslogUnsupported = errors.New("syslog is not supported on this platform")
+
+// SetupSyslogLogger is not supported on this platform and always terminates
+// the application.
+func SetupSyslogLogger(
+	cfg *gocli.LogSyslogConfig,
+) (*zap.Logger, *Handle) {
+	log.Fatalf("Failed to create logger: %v", errSyslogUnsupported)
+	return nil, nil
+}
+
+// NewSyslogLogger is not supported on this platform and always returns an error.
+func NewSyslogLogger(
+	cfg *gocli.LogSyslogConfig,
+) (*zap.Logger, *Handle, error) {
+	return nil, nil, errSyslogUnsupported
+}
+
+// SetupStdAndSyslogLogger is not supported on this platform and always terminates
+// the application.
+func SetupStdAndSyslogLogger(
+	cfg *gocli.LogSyslogConfig,
+) (*zap.Logger, *Handle) {
+	log.Fatalf("Failed to create logger: %v", errSyslogUnsupported)
+	return nil, nil
+}
+
+// NewStdAndSyslogLogger is not supported on this platform and always returns an error.
+func NewStdAndSyslogLogger(
+	cfg *gocli.LogSyslogConfig,
+) (*zap.Logger, *Handle, error) {
+	return nil, nil, errSyslogUnsupported
+}
@@ -0,0 +1,287 @@
+package golog
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// throttleSummaryInterval is the minimum time between two throttling summaries.
+const throttleSummaryInterval = time.Second
+
+// throttleOption returns a zap.Option limiting the throughput of debug and info
+// entries, to protect nodes during event storms.
+//
+// Each of the two levels has its own token bucket: up to rate entries per second
+// are written, with bursts of up to burst entries, and the excess is dropped.
+// Full verbosity comes back as soon as the rate falls below the limit. Warn and
+// above are never throttled. While entries are being dropped, a summary is
+// written at most once per second (and on Sync): a warn entry for each of the
+// most dropped messages, with the number of drops and the time of the first and
+// last ones, then a warn entry with the drop totals per level.
+//
+// Parameters:
+//   - rate: debug or info entries per second allowed; throttling is disabled when not positive.
+//   - burst: bucket size; values below 1 default to rate.
+//
+// Returns:
+//   - zap.Option to pass to zap.New or Logger.WithOptions.
+func throttleOption(
+	rate int,
+	burst int,
+) zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if rate <= 0 {
+			return core
+		}
+		if burst < 1 {
+			burst = rate
+		}
+		return &throttleCore{Core: core, throttle: newThrottle(float64(rate), float64(burst))}
+	})
+}
+
+const (
+	// throttleMaxMessages bounds the messages whose drops are tracked one by one
+	// between two summaries; drops of further messages are only counted per level.
+	throttleMaxMessages = 100
+
+	// throttleSummaryMessages is the number of messages itemized in a summary,
+	// the most dropped first.
+	throttleSummaryMessages = 10
+)
+
+// throttledMessage identifies the entries of a message dropped by the throttle.
+type throttledMessage struct {
+	level   zapcore.Level
+	logger  string
+	message string
+}
+
+// throttledStats counts the drops of a message between two summaries.
+type throttledStats struct {
+	count uint64
+	first time.Time // time of the first dropped entry
+	last  time.Time // time of the last dropped entry
+}
+
+// throttleSummary holds the drops reported by a summary.
+type throttleSummary struct {
+	dropped  map[zapcore.Level]uint64
+	messages map[throttledMessage]*throttledStats
+}
+
+// throttle holds the token buckets of the throttled levels and the drops since
+// the last summary, shared by a throttleCore and its children.
+type throttle struct {
+	mu          sync.Mutex
+	rate        float64
+	burst       float64
+	tokens      map[zapcore.Level]float64
+	refilled    map[zapcore.Level]time.Time
+	pending     throttleSummary // drops since the last summary
+	lastSummary time.Time
+}
+
+// newThrottle creates full buckets for the debug and info levels.
+func newThrottle(
+	rate float64,
+	burst float64,
+) *throttle {
+	t := &throttle{
+		rate:     rate,
+		burst:    burst,
+		tokens:   make(map[zapcore.Level]float64),
+		refilled: make(map[zapcore.Level]time.Time),
+	}
+	t.resetPending()
+	now := time.Now()
+	for _, lvl := range []zapcore.Level{zapcore.DebugLevel, zapcore.InfoLevel} {
+		t.tokens[lvl] = burst
+		t.refilled[lvl] = now
+	}
+	return t
+}
+
+// allow takes a token for an entry of the given level, or records its drop.
+//
+// Returns:
+//   - whether the entry may be written.
+//   - the drops since the last summary when a summary is due; nil otherwise.
+func (t *throttle) allow(
+	ent zapcore.Entry,
+) (bool, *throttleSummary) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	tokens := t.tokens[ent.Level] + now.Sub(t.refilled[ent.Level]).Seconds()*t.rate
+	if tokens > t.burst {
+		tokens = t.burst
+	}
+	t.refilled[ent.Level] = now
+
+	if tokens < 1 {
+		t.tokens[ent.Level] = tokens
+		t.drop(ent)
+		return false, nil
+	}
+	t.tokens[ent.Level] = tokens - 1
+	return true, t.takeSummary(now)
+}
+
+// due returns the drops since the last summary when a summary is due, nil
+// otherwise.
+func (t *throttle) due() *throttleSummary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.takeSummary(time.Now())
+}
+
+// drop records a dropped entry. The caller must hold t.mu.
+func (t *throttle) drop(
+	ent zapcore.Entry,
+) {
+	t.pending.dropped[ent.Level]++
+
+	key := throttledMessage{level: ent.Level, logger: ent.LoggerName, message: ent.Message}
+	stats, ok := t.pending.messages[key]
+	if !ok {
+		if len(t.pending.messages) >= throttleMaxMessages {
+			return
+		}
+		stats = &throttledStats{first: ent.Time}
+		t.pending.messages[key] = stats
+	}
+	stats.count++
+	stats.last = ent.Time
+}
+
+// takeSummary returns and resets the drops when there are some and the last
+// summary is older than throttleSummaryInterval. The caller must hold t.mu.
+func (t *throttle) takeSummary(
+	now time.Time,
+) *throttleSummary {
+	if len(t.pending.dropped) == 0 || now.Sub(t.lastSummary) < throttleSummaryInterval {
+		return nil
+	}
+	summary := t.pending
+	t.resetPending()
+	t.lastSummary = now
+	return &summary
+}
+
+// resetPending clears the drops since the last summary. The caller must hold
+// t.mu.
+func (t *throttle) resetPending() {
+	t.pending = throttleSummary{
+		dropped:  make(map[zapcore.Level]uint64),
+		messages: make(map[throttledMessage]*throttledStats),
+	}
+}
+
+// throttleCore drops debug and info entries exceeding the throttle rate.
+type throttleCore struct {
+	zapcore.Core
+	throttle *throttle
+}
+
+// With returns a copy of the core with the given fields added to every entry.
+func (c *throttleCore) With(
+	fields []zapcore.Field,
+) zapcore.Core {
+	return &throttleCore{Core: c.Core.With(fields), throttle: c.throttle}
+}
+
+// Check applies the throttle to debug and info entries the wrapped core accepts,
+// and writes the pending summary when one is due.
+func (c *throttleCore) Check(
+	ent zapcore.Entry,
+	ce *zapcore.CheckedEntry,
+) *zapcore.CheckedEntry {
+	if ent.Level > zapcore.InfoLevel || !c.Core.Enabled(ent.Level) {
+		if summary := c.throttle.due(); summary != nil {
+			c.writeSummary(ent, summary)
+		}
+		return c.Core.Check(ent, ce)
+	}
+
+	allowed, summary := c.throttle.allow(ent)
+	if !allowed {
+		return ce
+	}
+	if summary != nil {
+		c.writeSummary(ent, summary)
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// Sync writes the pending summary, even if one was written less than
+// throttleSummaryInterval ago, and flushes the wrapped core.
+func (c *throttleCore) Sync() error {
+	c.throttle.mu.Lock()
+	c.throttle.lastSummary = time.Time{}
+	summary := c.throttle.takeSummary(time.Now())
+	c.throttle.mu.Unlock()
+
+	if summary != nil {
+		c.writeSummary(zapcore.Entry{Time: time.Now()}, summary)
+	}
+	return c.Core.Sync()
+}
+
+// writeSummary writes a warn entry for each of the most dropped messages, with
+// the number of drops and the time of the first and last ones, followed by the
+// drop totals per level.
+func (c *throttleCore) writeSummary(
+	ent zapcore.Entry,
+	summary *throttleSummary,
+) {
+	messages := make([]throttledMessage, 0, len(summary.messages))
+	for key := range summary.messages {
+		messages = append(messages, key)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return summary.messages[messages[i]].count > summary.messages[messages[j]].count
+	})
+	if len(messages) > throttleSummaryMessages {
+		messages = messages[:throttleSummaryMessages]
+	}
+
+	for _, key := range messages {
+		stats := summary.messages[key]
+		entry := zapcore.Entry{
+			Level:      zapcore.WarnLevel,
+			Time:       ent.Time,
+			LoggerName: key.logger,
+			Message:    fmt.Sprintf("message dropped %d times by throttling", stats.count),
+		}
+		if ce := c.Core.Check(entry, nil); ce != nil {
+			ce.Write(
+				zap.String("throttled_message", key.message),
+				zap.Stringer("throttled_level", key.level),
+				zap.Uint64("dropped", stats.count),
+				zap.Time("first_dropped", stats.first),
+				zap.Time("last_dropped", stats.last),
+			)
+		}
+	}
+
+	total := zapcore.Entry{
+		Level:      zapcore.WarnLevel,
+		Time:       ent.Time,
+		LoggerName: ent.LoggerName,
+		Message:    "log throughput throttled, entries dropped",
+	}
+	if ce := c.Core.Check(total, nil); ce != nil {
+		ce.Write(
+			zap.Uint64("dropped_debug", summary.dropped[zapcore.DebugLevel]),
+			zap.Uint64("dropped_info", summary.dropped[zapcore.InfoLevel]),
+		)
+	}
+}
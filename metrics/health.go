@@ -0,0 +1,302 @@
+package gometrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kubensage/common/cli"
+)
+
+// healthCheckTimeout bounds the time given to the checkers of a probe.
+const healthCheckTimeout = 5 * time.Second
+
+// Checker reports the health of a component of the process, e.g., a gRPC
+// connection (see gogrpc.ConnectionChecker) or a buffer (see BufferChecker).
+//
+// It returns nil if the component is healthy, else an error describing the
+// problem. It must return once ctx is done.
+type Checker func(ctx context.Context) error
+
+// HealthServer serves the liveness and readiness endpoints of the process,
+// probed by Kubernetes: <path>/live answers whether the process should be
+// restarted, <path>/ready whether it should receive traffic. Each endpoint
+// runs its checkers and answers 200 if all pass, else 503, with the result
+// of every checker as JSON (e.g., {"status":"fail","checks":{"relay":"state
+// TRANSIENT_FAILURE"}}).
+//
+// The readiness endpoint fails until SetReady(true) is called, and again once
+// Shutdown is called, so that traffic is drained before the process stops.
+//
+// A nil *HealthServer, returned by NewHealthServer when the endpoints are
+// disabled, is valid: its methods do nothing, so that callers need no special
+// case.
+type HealthServer struct {
+	server *Server
+	ready  atomic.Bool
+
+	mu        sync.RWMutex
+	liveness  map[string]Checker
+	readiness map[string]Checker
+}
+
+// healthResponse is the body of the answers of the health endpoints.
+type healthResponse struct {
+	Status string            `json:"status"`           // "ok" or "fail"
+	Checks map[string]string `json:"checks,omitempty"` // "ok" or the error of each checker, by name
+}
+
+// NewHealthServer creates the liveness and readiness endpoints configured by
+// cfg, typically obtained from gocli.RegisterHealthFlags, and binds its listen
+// address so that address errors are reported before serving.
+//
+// Parameters:
+//   - cfg: the health configuration.
+//
+// Returns:
+//   - The server, listening but not yet serving nor ready, or nil if
+//     cfg.HealthListen is empty.
+//   - An error if the address cannot be bound.
+//
+// Example:
+//
+//	health, err := gometrics.NewHealthServer(healthConfig)
+//	if err != nil {
+//		logger.Fatal("failed to create health server", zap.Error(err))
+//	}
+//	health.AddReadinessCheck("relay", gogrpc.ConnectionChecker(conn))
+//	health.AddReadinessCheck("buffer", gometrics.BufferChecker(buffer, bufferConfig.BufferCapacity, 0.9))
+//	go health.Serve()
+//	err = goservice.Run(ctx, serviceConfig, logger, func(ctx context.Context, ready func()) error {
+//		go server.Serve()
+//		health.SetReady(true)
+//		ready()
+//		<-ctx.Done()
+//		_ = health.Shutdown(context.Background()) // fail readiness first
+//		server.Shutdown()
+//		return nil
+//	})
+func NewHealthServer(
+	cfg *gocli.HealthConfig,
+) (*HealthServer, error) {
+	if cfg.HealthListen == "" {
+		return nil, nil
+	}
+
+	path := "/" + strings.Trim(cfg.HealthPath, "/")
+	if path == "/" {
+		path = ""
+	}
+	h := &HealthServer{
+		liveness:  make(map[string]Checker),
+		readiness: make(map[string]Checker),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(path+"/live", func(w http.ResponseWriter, r *http.Request) {
+		h.serveProbe(w, r, h.checkers(false), true)
+	})
+	mux.HandleFunc(path+"/ready", func(w http.ResponseWriter, r *http.Request) {
+		h.serveProbe(w, r, h.checkers(true), h.ready.Load())
+	})
+
+	server, err := newServer(cfg.HealthListen, mux)
+	if err != nil {
+		return nil, err
+	}
+	h.server = server
+	return h, nil
+}
+
+// AddLivenessCheck adds a checker run by the liveness endpoint. A failing
+// liveness check gets the process restarted, so it should only detect
+// unrecoverable states (e.g., a deadlock).
+//
+// Parameters:
+//   - name: the name of the checker in the answers; it replaces a checker of
+//     the same name.
+//   - check: the checker.
+func (h *HealthServer) AddLivenessCheck(
+	name string,
+	check Checker,
+) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.liveness[name] = check
+}
+
+// AddReadinessCheck adds a checker run by the readiness endpoint, e.g., the
+// state of a dependency without which the process cannot serve.
+//
+// Parameters:
+//   - name: the name of the checker in the answers; it replaces a checker of
+//     the same name.
+//   - check: the checker.
+func (h *HealthServer) AddReadinessCheck(
+	name string,
+	check Checker,
+) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.readiness[name] = check
+}
+
+// SetReady sets whether the process is ready to receive traffic, e.g., once
+// its servers are started (see goservice.Run); the readiness checkers only
+// run while it is.
+//
+// Parameters:
+//   - ready: whether the process is ready.
+func (h *HealthServer) SetReady(
+	ready bool,
+) {
+	if h == nil {
+		return
+	}
+	h.ready.Store(ready)
+}
+
+// Addr returns the address the server listens on, e.g., to find the port
+// chosen for ":0", or nil if the server is disabled.
+func (h *HealthServer) Addr() net.Addr {
+	if h == nil {
+		return nil
+	}
+	return h.server.Addr()
+}
+
+// Serve answers probes until Shutdown is called.
+//
+// Returns:
+//   - nil after Shutdown or if the server is disabled, else the error that
+//     stopped the server.
+func (h *HealthServer) Serve() error {
+	if h == nil {
+		return nil
+	}
+	return h.server.Serve()
+}
+
+// Shutdown fails the readiness endpoint, then stops the server, waiting for
+// the running probes to end until ctx is done. It is meant to be called first
+// on the shutdown path, so that no traffic is routed to the process while its
+// other servers stop.
+//
+// Parameters:
+//   - ctx: bounds the wait for running probes.
+//
+// Returns:
+//   - An error if ctx ended before the running probes.
+func (h *HealthServer) Shutdown(
+	ctx context.Context,
+) error {
+	if h == nil {
+		return nil
+	}
+	h.ready.Store(false)
+	return h.server.Shutdown(ctx)
+}
+
+// BufferChecker returns a checker failing while a buffer is filled above a
+// ratio of its capacity, e.g., to stop receiving traffic while a relay cannot
+// forward its data.
+//
+// Parameters:
+//   - buffer: the buffer, e.g., a *datastructure.RingBuffer.
+//   - capacity: the capacity of the buffer; a checker of a buffer without
+//     capacity never fails.
+//   - threshold: the ratio of the capacity above which the checker fails
+//     (e.g., 0.9).
+//
+// Returns:
+//   - The checker.
+func BufferChecker(
+	buffer interface{ Len() int },
+	capacity int,
+	threshold float64,
+) Checker {
+	return func(context.Context) error {
+		if capacity <= 0 {
+			return nil
+		}
+		n := buffer.Len()
+		if ratio := float64(n) / float64(capacity); ratio > threshold {
+			return fmt.Errorf("buffer %.0f%% full (%d/%d)", ratio*100, n, capacity)
+		}
+		return nil
+	}
+}
+
+// checkers returns a copy of the readiness or liveness checkers.
+func (h *HealthServer) checkers(
+	readiness bool,
+) map[string]Checker {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if readiness {
+		return maps.Clone(h.readiness)
+	}
+	return maps.Clone(h.liveness)
+}
+
+// serveProbe answers a probe with the results of the checkers, run
+// concurrently, failing without running them unless ok is set.
+func (h *HealthServer) serveProbe(
+	w http.ResponseWriter,
+	r *http.Request,
+	checkers map[string]Checker,
+	ok bool,
+) {
+	resp := healthResponse{Status: "ok"}
+	if !ok {
+		resp.Status = "fail"
+	} else if len(checkers) > 0 {
+		ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+		defer cancel()
+
+		names := make([]string, 0, len(checkers))
+		for name := range checkers {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		results := make([]error, len(names))
+		var wg sync.WaitGroup
+		for i, name := range names {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i] = checkers[name](ctx)
+			}()
+		}
+		wg.Wait()
+
+		resp.Checks = make(map[string]string, len(names))
+		for i, name := range names {
+			resp.Checks[name] = "ok"
+			if results[i] != nil {
+				resp.Checks[name] = results[i].Error()
+				resp.Status = "fail"
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if resp.Status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
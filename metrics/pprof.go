@@ -0,0 +1,45 @@
+package gometrics
+
+import (
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/kubensage/common/cli"
+)
+
+// NewPprofServer creates the pprof profiling endpoint configured by cfg,
+// typically obtained from gocli.RegisterProfilingFlags, and binds its listen
+// address. The profiles are served under /debug/pprof/ (e.g., go tool pprof
+// http://127.0.0.1:6060/debug/pprof/heap).
+//
+// Parameters:
+//   - cfg: the profiling configuration.
+//
+// Returns:
+//   - The server, listening but not yet serving, or nil if cfg.PprofListen
+//     is empty.
+//   - An error if the address cannot be bound.
+//
+// Example:
+//
+//	server, err := gometrics.NewPprofServer(pprofConfig)
+//	if err != nil {
+//		logger.Fatal("failed to create pprof server", zap.Error(err))
+//	}
+//	go server.Serve()
+//	defer server.Shutdown(context.Background())
+func NewPprofServer(
+	cfg *gocli.PprofConfig,
+) (*Server, error) {
+	if cfg.PprofListen == "" {
+		return nil, nil
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return newServer(cfg.PprofListen, mux)
+}
@@ -0,0 +1,135 @@
+package gometrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kubensage/common/cli"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// readHeaderTimeout bounds the time a client may take to send its request
+// headers, so that idle connections cannot pile up.
+const readHeaderTimeout = 10 * time.Second
+
+// Server serves an HTTP debugging endpoint of the process: the Prometheus
+// metrics (see NewServer) or the pprof profiles (see NewPprofServer).
+//
+// A nil *Server, returned by NewServer when the endpoint is disabled, is valid:
+// its methods do nothing, so that callers need no special case.
+type Server struct {
+	server   *http.Server
+	listener net.Listener
+}
+
+// NewServer creates the scrape endpoint configured by cfg, typically obtained
+// from gocli.RegisterMetricsFlags, and binds its listen address so that address
+// errors are reported before serving.
+//
+// Parameters:
+//   - cfg: the metrics configuration.
+//   - gatherer: the source of the metrics; nil uses prometheus.DefaultGatherer,
+//     which also holds the Go runtime and process metrics and the log counters
+//     of golog (see golog.NewLogMetrics).
+//
+// Returns:
+//   - The server, listening but not yet serving, or nil if cfg.MetricsEnabled
+//     is false.
+//   - An error if the address cannot be bound.
+//
+// Example:
+//
+//	server, err := gometrics.NewServer(metricsConfig, nil)
+//	if err != nil {
+//		logger.Fatal("failed to create metrics server", zap.Error(err))
+//	}
+//	go func() {
+//		if err := server.Serve(); err != nil {
+//			logger.Error("metrics server failed", zap.Error(err))
+//		}
+//	}()
+//	defer server.Shutdown(context.Background())
+func NewServer(
+	cfg *gocli.MetricsConfig,
+	gatherer prometheus.Gatherer,
+) (*Server, error) {
+	if !cfg.MetricsEnabled {
+		return nil, nil
+	}
+	if gatherer == nil {
+		gatherer = prometheus.DefaultGatherer
+	}
+
+	path := cfg.MetricsPath
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	mux := http.NewServeMux()
+	mux.Handle(path, promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+	return newServer(cfg.MetricsListen, mux)
+}
+
+// newServer binds address and returns a server for handler.
+func newServer(
+	address string,
+	handler http.Handler,
+) (*Server, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+
+	return &Server{
+		server:   &http.Server{Handler: handler, ReadHeaderTimeout: readHeaderTimeout},
+		listener: listener,
+	}, nil
+}
+
+// Addr returns the address the server listens on, e.g., to find the port
+// chosen for ":0", or nil if the server is disabled.
+func (s *Server) Addr() net.Addr {
+	if s == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Serve answers requests until Shutdown is called.
+//
+// Returns:
+//   - nil after Shutdown or if the server is disabled, else the error that
+//     stopped the server.
+func (s *Server) Serve() error {
+	if s == nil {
+		return nil
+	}
+	if err := s.server.Serve(s.listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops the server, waiting for the running requests to end until ctx
+// is done.
+//
+// Parameters:
+//   - ctx: bounds the wait for running requests.
+//
+// Returns:
+//   - An error if ctx ended before the running requests.
+func (s *Server) Shutdown(
+	ctx context.Context,
+) error {
+	if s == nil {
+		return nil
+	}
+	err := s.server.Shutdown(ctx)
+	_ = s.listener.Close() // not closed by Shutdown if Serve was never called
+	return err
+}
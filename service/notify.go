@@ -0,0 +1,62 @@
+package goservice
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Notify sends a state change to systemd through the socket named by
+// NOTIFY_SOCKET (sd_notify), e.g., "READY=1", "RELOADING=1" or "STATUS=...",
+// several states being separated by newlines.
+//
+// Parameters:
+//   - state: the state to send.
+//
+// Returns:
+//   - Whether the state was sent: false if NOTIFY_SOCKET is not set, i.e.,
+//     the process is not run by a Type=notify systemd unit.
+//   - An error if the socket cannot be written.
+func Notify(
+	state string,
+) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:] // abstract namespace
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to systemd: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, fmt.Errorf("failed to notify systemd: %w", err)
+	}
+	return true, nil
+}
+
+// WatchdogInterval returns the watchdog timeout of the systemd unit
+// (WatchdogSec), read from WATCHDOG_USEC; pings must be sent more often, e.g.,
+// at half of it.
+//
+// Returns:
+//   - The timeout.
+//   - Whether the watchdog is enabled for this process: false if WATCHDOG_USEC
+//     is not set or invalid, or WATCHDOG_PID names another process.
+func WatchdogInterval() (time.Duration, bool) {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, false
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, false
+	}
+	return time.Duration(usec) * time.Microsecond, true
+}
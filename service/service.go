@@ -0,0 +1,146 @@
+package goservice
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kubensage/common/cli"
+	"go.uber.org/zap"
+)
+
+// Run runs fn under the service manager selected by cfg, typically obtained
+// from gocli.RegisterServiceFlags, for agents deployed outside Kubernetes:
+//
+//   - systemd: fn calling ready sends READY=1 to systemd (Type=notify units),
+//     the watchdog is pinged at half of WatchdogSec while fn runs if
+//     cfg.ServiceWatchdog is set, and STOPPING=1 is sent once fn returns;
+//   - windows: the process reports itself running to the Windows service
+//     control manager once fn calls ready, and a stop or shutdown request
+//     cancels the context of fn;
+//   - none: fn runs as is, ready doing nothing.
+//
+// The auto mode selects windows when the process is started by the Windows
+// service control manager, systemd when NOTIFY_SOCKET is set, and none
+// otherwise.
+//
+// Parameters:
+//   - ctx: context of fn; on Windows, fn also stops on service stop requests.
+//   - cfg: the service configuration.
+//   - logger: logger reporting the selected mode and watchdog failures, or nil.
+//   - fn: the body of the agent, calling ready once it serves requests and
+//     returning once its context is done.
+//
+// Returns:
+//   - The error of fn, or an error if the mode is not supported on this
+//     platform.
+//
+// Example:
+//
+//	err := goservice.Run(ctx, serviceConfig, logger, func(ctx context.Context, ready func()) error {
+//		go server.Serve()
+//		ready()
+//		<-ctx.Done()
+//		server.Shutdown()
+//		return nil
+//	})
+func Run(
+	ctx context.Context,
+	cfg *gocli.ServiceConfig,
+	logger *zap.Logger,
+	fn func(ctx context.Context, ready func()) error,
+) error {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	mode, err := resolveMode(cfg.ServiceMode)
+	if err != nil {
+		return err
+	}
+	logger.Info("service mode selected", zap.String("mode", mode))
+
+	switch mode {
+	case gocli.ServiceModeWindows:
+		return runWindows(ctx, fn)
+	case gocli.ServiceModeSystemd:
+		return runSystemd(ctx, cfg, logger, fn)
+	default:
+		return fn(ctx, func() {})
+	}
+}
+
+// resolveMode returns the service mode to run in, resolving the auto mode.
+func resolveMode(
+	mode string,
+) (string, error) {
+	switch mode {
+	case gocli.ServiceModeAuto, "":
+		isService, err := isWindowsService()
+		if err != nil {
+			return "", fmt.Errorf("failed to detect the Windows service control manager: %w", err)
+		}
+		if isService {
+			return gocli.ServiceModeWindows, nil
+		}
+		if os.Getenv("NOTIFY_SOCKET") != "" {
+			return gocli.ServiceModeSystemd, nil
+		}
+		return gocli.ServiceModeNone, nil
+	case gocli.ServiceModeNone, gocli.ServiceModeSystemd, gocli.ServiceModeWindows:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown service mode %q", mode)
+	}
+}
+
+// runSystemd runs fn, notifying systemd of its readiness and pinging the
+// watchdog meanwhile.
+func runSystemd(
+	ctx context.Context,
+	cfg *gocli.ServiceConfig,
+	logger *zap.Logger,
+	fn func(ctx context.Context, ready func()) error,
+) error {
+	if os.Getenv("NOTIFY_SOCKET") == "" {
+		logger.Warn("systemd service mode without NOTIFY_SOCKET, notifications disabled")
+	}
+
+	if interval, ok := WatchdogInterval(); ok && cfg.ServiceWatchdog {
+		watchdogCtx, stop := context.WithCancel(ctx)
+		defer stop()
+		go pingWatchdog(watchdogCtx, interval/2, logger)
+	}
+
+	err := fn(ctx, func() {
+		if _, err := Notify("READY=1"); err != nil {
+			logger.Warn("failed to notify systemd of readiness", zap.Error(err))
+		}
+	})
+
+	if _, notifyErr := Notify("STOPPING=1"); notifyErr != nil {
+		logger.Warn("failed to notify systemd of stopping", zap.Error(notifyErr))
+	}
+	return err
+}
+
+// pingWatchdog sends WATCHDOG=1 to systemd every period until ctx is done.
+func pingWatchdog(
+	ctx context.Context,
+	period time.Duration,
+	logger *zap.Logger,
+) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := Notify("WATCHDOG=1"); err != nil {
+				logger.Warn("failed to ping the systemd watchdog", zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+//go:build windows
+
+package goservice
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+// isWindowsService reports whether the process was started by the Windows
+// service control manager.
+func isWindowsService() (bool, error) {
+	return svc.IsWindowsService()
+}
+
+// runWindows runs fn as a Windows service, until it returns.
+func runWindows(
+	ctx context.Context,
+	fn func(ctx context.Context, ready func()) error,
+) error {
+	h := &serviceHandler{ctx: ctx, fn: fn}
+	if err := svc.Run("", h); err != nil { // the name is ignored for services running in their own process
+		return err
+	}
+	return h.err
+}
+
+// serviceHandler handles the requests of the Windows service control manager
+// while running fn.
+type serviceHandler struct {
+	ctx context.Context
+	fn  func(ctx context.Context, ready func()) error
+	err error // error of fn, once returned
+}
+
+// Execute runs fn, reporting the service running once fn is ready and
+// cancelling its context on stop or shutdown requests.
+func (h *serviceHandler) Execute(
+	_ []string,
+	requests <-chan svc.ChangeRequest,
+	status chan<- svc.Status,
+) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+
+	ctx, cancel := context.WithCancel(h.ctx)
+	defer cancel()
+
+	readyCh := make(chan struct{})
+	var once sync.Once
+	done := make(chan error, 1)
+	go func() {
+		done <- h.fn(ctx, func() { once.Do(func() { close(readyCh) }) })
+	}()
+
+	for {
+		select {
+		case <-readyCh:
+			readyCh = nil
+			status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				status <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				cancel()
+			}
+		case h.err = <-done:
+			status <- svc.Status{State: svc.StopPending}
+			if h.err != nil {
+				return false, 1
+			}
+			return false, 0
+		}
+	}
+}
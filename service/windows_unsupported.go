@@ -0,0 +1,22 @@
+//go:build !windows
+
+package goservice
+
+import (
+	"context"
+	"errors"
+)
+
+// isWindowsService always reports false: the Windows service control manager
+// only exists on Windows.
+func isWindowsService() (bool, error) {
+	return false, nil
+}
+
+// runWindows always fails: Windows services are only supported on Windows.
+func runWindows(
+	ctx context.Context,
+	fn func(ctx context.Context, ready func()) error,
+) error {
+	return errors.New("windows service mode is not supported on this platform")
+}